@@ -21,13 +21,16 @@ func main() {
 func newRootCommand() *cobra.Command {
 	var mode string
 	globalMode := taskdef.ModeTask
+	globalLoad := taskdef.LoadOptions{}
 
 	rootCmd := &cobra.Command{
 		Use:   "ecs-tag-shift",
 		Short: "A CLI tool to update ECS task definition and container definition image tags",
 		Long: `ecs-tag-shift is a CLI tool for updating container image tags in
 ECS task definitions and container definitions. It supports JSONC input
-and can output in JSON, YAML, or text formats.`,
+and can output in JSON, YAML, or text formats. Task definitions embedded
+in CloudFormation templates and Terraform configurations, as well as
+docker-compose services and Kubernetes workloads, are updated in place.`,
 		Version: version,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Validate and set global mode
@@ -36,8 +39,16 @@ and can output in JSON, YAML, or text formats.`,
 				globalMode = taskdef.ModeTask
 			case "container":
 				globalMode = taskdef.ModeContainer
+			case "cfn":
+				globalMode = taskdef.ModeCFN
+			case "terraform":
+				globalMode = taskdef.ModeTerraform
+			case "compose":
+				globalMode = taskdef.ModeCompose
+			case "k8s":
+				globalMode = taskdef.ModeK8s
 			default:
-				return fmt.Errorf("invalid mode: %s (must be 'task' or 'container')", mode)
+				return fmt.Errorf("invalid mode: %s (must be 'task', 'container', 'cfn', 'terraform', 'compose' or 'k8s')", mode)
 			}
 			return nil
 		},
@@ -46,11 +57,21 @@ and can output in JSON, YAML, or text formats.`,
 	}
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&mode, "mode", "m", "task", "Input mode (task or container)")
+	rootCmd.PersistentFlags().StringVarP(&mode, "mode", "m", "task", "Input mode (task, container, cfn, terraform, compose or k8s)")
+	rootCmd.PersistentFlags().BoolVar(&globalLoad.Strict, "strict", false, "Reject duplicate keys and keys not in the ECS task definition schema (task and container modes)")
 
 	// Add subcommands
-	rootCmd.AddCommand(command.NewShowCommand(&globalMode))
-	rootCmd.AddCommand(command.NewShiftCommand(&globalMode))
+	rootCmd.AddCommand(command.NewShowCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewShiftCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewRenderCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewValidateCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewLintCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewVerifyCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewRegisterCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewDeployCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewRollbackCommand(&globalMode, &globalLoad))
+	rootCmd.AddCommand(command.NewPruneCommand())
+	rootCmd.AddCommand(command.NewHistoryCommand())
 
 	return rootCmd
 }
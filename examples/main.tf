@@ -0,0 +1,29 @@
+resource "aws_ecs_task_definition" "app" {
+  family                   = "my-app"
+  network_mode             = "awsvpc"
+  requires_compatibilities = ["FARGATE"]
+  cpu                      = "256"
+  memory                   = "512"
+
+  container_definitions = jsonencode([
+    {
+      name      = "web"
+      image     = "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.2" # アプリケーションイメージ
+      cpu       = 256
+      memory    = 512
+      essential = true
+      portMappings = [
+        {
+          containerPort = 80
+          protocol      = "tcp"
+        }
+      ]
+    },
+    {
+      name   = "nginx"
+      image  = "nginx:latest"
+      cpu    = 128
+      memory = 256
+    }
+  ])
+}
@@ -70,6 +70,16 @@ else
     ((failed++))
 fi
 
+# Test show with CloudFormation mode
+echo -n "Test: Show CloudFormation template ... "
+if $BINARY -m cfn show $EXAMPLES_DIR/cloudformation.yaml -o text 2>&1 | grep -q "WorkerTaskDefinition:"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
 echo ""
 echo "=== Running shift command tests ==="
 
@@ -133,6 +143,157 @@ else
     ((failed++))
 fi
 
+# Test CloudFormation mode shift
+echo -n "Test: Shift CloudFormation template ... "
+if $BINARY -m cfn shift $EXAMPLES_DIR/cloudformation.yaml -r AppTaskDefinition -c web --tag v2.0.0 2>&1 | grep -q "amazonaws.com/my-app:v2.0.0"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test Terraform mode shift
+echo -n "Test: Shift Terraform configuration ... "
+if $BINARY -m terraform shift $EXAMPLES_DIR/main.tf -c nginx --tag stable 2>&1 | grep -q '"nginx:stable"'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test compose mode shift
+echo -n "Test: Shift compose services ... "
+if $BINARY -m compose shift $EXAMPLES_DIR/docker-compose.yml -c web --tag v2.0.0 2>&1 | grep -q "my-app:v2.0.0"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test Kubernetes mode shift
+echo -n "Test: Shift Kubernetes manifests ... "
+if [ "$($BINARY -m k8s shift $EXAMPLES_DIR/k8s-deployment.yaml --image my-app --tag v2.0.0 2>&1 | grep -c "my-app:v2.0.0")" = "2" ]; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+echo ""
+echo "=== Running render command tests ==="
+
+# Test render placeholders
+echo -n "Test: Render CodeDeploy placeholders ... "
+if $BINARY render $EXAMPLES_DIR/taskdef-codedeploy.json --set IMAGE1_NAME=my-app:v2.0.0 2>&1 | grep -q '"image": "my-app:v2.0.0"'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test reverse render
+echo -n "Test: Reverse render to placeholders ... "
+if $BINARY render $EXAMPLES_DIR/task-definition.json --reverse --set IMAGE1_NAME=web 2>&1 | grep -q '"image": "<IMAGE1_NAME>"'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test render of an AppSpec file
+echo -n "Test: Render the AppSpec task definition placeholder ... "
+if $BINARY render $EXAMPLES_DIR/appspec.yaml --set TASK_DEFINITION=arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:3 2>&1 | grep -q 'TaskDefinition: arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:3'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+echo ""
+echo "=== Running validate command tests ==="
+
+# Test validate on a valid task definition
+echo -n "Test: Validate valid task definition ... "
+if $BINARY validate $EXAMPLES_DIR/task-definition.json 2>&1 | grep -q "No validation errors found"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test validate on an invalid task definition
+echo '{"family": "x", "requiresCompatibilities": ["FARGATE"], "networkMode": "bridge", "cpu": "256", "memory": "512", "containerDefinitions": [{"name": "web"}]}' > /tmp/invalid_taskdef.json
+echo -n "Test: Validate invalid task definition (should fail) ... "
+if ! $BINARY validate /tmp/invalid_taskdef.json > /tmp/validate_out.txt 2>&1 && grep -q '\$.networkMode' /tmp/validate_out.txt && grep -q '\$.containerDefinitions\[0\].image' /tmp/validate_out.txt; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test shift refuses to write an invalid definition
+echo -n "Test: Shift rejects invalid result (should fail) ... "
+if $BINARY shift /tmp/invalid_taskdef.json -t v1 2>&1 | grep -q "skip-validation"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -f /tmp/invalid_taskdef.json /tmp/validate_out.txt
+
+echo ""
+echo "=== Running lint command tests ==="
+
+# Test lint reports warnings without failing
+echo -n "Test: Lint reports latest tag ... "
+if $BINARY lint $EXAMPLES_DIR/task-definition.json 2>&1 | grep -q "\[image-tag\]"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test lint fails on plaintext secrets and honours disable comments
+cat > /tmp/lint_taskdef.jsonc << 'LINTEOF'
+{
+  // ecs-tag-shift-disable image-tag
+  "family": "my-app",
+  "containerDefinitions": [
+    {"name": "app", "image": "my-app:latest", "environment": [{"name": "DB_PASSWORD", "value": "secret"}]}
+  ]
+}
+LINTEOF
+echo -n "Test: Lint fails on plaintext secret (should fail) ... "
+if ! $BINARY lint /tmp/lint_taskdef.jsonc > /tmp/lint_out.txt 2>&1 && grep -q "plaintext-secret" /tmp/lint_out.txt && ! grep -q "image-tag" /tmp/lint_out.txt; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test SARIF output
+echo -n "Test: Lint SARIF output ... "
+if $BINARY lint $EXAMPLES_DIR/task-definition.json -o sarif 2>&1 | grep -q '"startLine"'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -f /tmp/lint_taskdef.jsonc /tmp/lint_out.txt
+
 echo ""
 echo "=== Running overwrite option tests ==="
 
@@ -238,6 +399,187 @@ else
 fi
 rm /tmp/single_object.json
 
+# Test parse errors are located in the original JSONC source
+printf '{\n  // comment\n  "family": },\n  "containerDefinitions": []\n}\n' > /tmp/broken.json
+echo -n "Test: Parse error with line and column (should fail) ... "
+if $BINARY show /tmp/broken.json 2>&1 | grep -q "/tmp/broken.json:3:13:"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm /tmp/broken.json
+
+# Test strict mode lists duplicate and unknown keys
+printf '{\n  "family": "x",\n  "family": "y",\n  "containerDefinitions": [{"name": "web", "imgae": "nginx", "image": "nginx"}]\n}\n' > /tmp/strict.json
+echo -n "Test: Strict mode rejects duplicate and unknown keys (should fail) ... "
+if $BINARY --strict show /tmp/strict.json > /tmp/strict_out.txt 2>&1; then
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+elif grep -q '\$.family: duplicate key' /tmp/strict_out.txt && grep -q 'imgae: unknown key' /tmp/strict_out.txt; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test strict mode accepts the examples
+echo -n "Test: Strict mode accepts valid task definition ... "
+if $BINARY --strict show $EXAMPLES_DIR/task-definition.jsonc > /dev/null 2>&1; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -f /tmp/strict.json /tmp/strict_out.txt
+
+# Test unknown fields survive a shift
+echo '{"family": "x", "containerDefinitions": [{"name": "web", "image": "nginx:1.0", "secrets": [{"name": "DB", "valueFrom": "arn:db"}], "futureField": {"enabled": true}}]}' > /tmp/roundtrip.json
+echo -n "Test: Shift keeps secrets and unknown fields ... "
+if $BINARY shift /tmp/roundtrip.json -t 2.0 --skip-validation 2>&1 | tr -d ' \n' | grep -q '"valueFrom":"arn:db"}\],"futureField":{"enabled":true}'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -f /tmp/roundtrip.json
+
+# Test show --all text output includes task-level fields
+echo '{"family": "x", "networkMode": "awsvpc", "runtimePlatform": {"cpuArchitecture": "ARM64", "operatingSystemFamily": "LINUX"}, "volumes": [{"name": "data", "efsVolumeConfiguration": {"fileSystemId": "fs-1234"}}], "containerDefinitions": [{"name": "web", "image": "nginx:1.0", "mountPoints": [{"sourceVolume": "data", "containerPath": "/data"}]}]}' > /tmp/tasklevel.json
+echo -n "Test: Show --all text output includes task-level fields ... "
+output=$($BINARY show /tmp/tasklevel.json -o text --all 2>&1)
+if echo "$output" | grep -q "Runtime Platform: LINUX/ARM64" && echo "$output" | grep -q "data: efs fs-1234"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -f /tmp/tasklevel.json
+
+# Test --resolve-digest rejects images whose repository is templated
+echo -n "Test: Resolve digest of a templated image in cfn mode (should fail) ... "
+if $BINARY --mode cfn shift $EXAMPLES_DIR/cloudformation.yaml --tag v1.0 --image my-app --resolve-digest 2>&1 | grep -q "repository is not a literal"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test --verify skips templated images of embedded documents
+TEMPLATED_DIR=$(mktemp -d)
+printf 'services:\n  web:\n    image: ${REGISTRY}/my-app:v1.0\n' > "$TEMPLATED_DIR/docker-compose.yml"
+echo -n "Test: Verify skips templated images in compose mode ... "
+if $BINARY --mode compose shift "$TEMPLATED_DIR/docker-compose.yml" --tag v1.1 --verify 2>&1 | grep -q 'image: ${REGISTRY}/my-app:v1.1'; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test shift without a tag
+echo -n "Test: Shift without --tag or --latest-matching (should fail) ... "
+if $BINARY shift $EXAMPLES_DIR/task-definition.json 2>&1 | grep -q "tag is required"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test verify in an embedded mode
+echo -n "Test: Verify command in compose mode ... "
+if $BINARY -m compose verify "$TEMPLATED_DIR/docker-compose.yml" 2>&1 | grep -q "All images exist"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -rf "$TEMPLATED_DIR"
+
+# Test register in container mode
+echo -n "Test: Register in container mode (should fail) ... "
+if $BINARY -m container register $EXAMPLES_DIR/container-definitions.json 2>&1 | grep -q "only supported in task mode"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test ECS source in container mode
+echo -n "Test: Show ecs:// source in container mode (should fail) ... "
+if $BINARY -m container show ecs://my-app 2>&1 | grep -q "only supported in task mode"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test deploy without a service
+echo -n "Test: Deploy without --service (should fail) ... "
+if $BINARY deploy $EXAMPLES_DIR/task-definition.json 2>&1 | grep -q "required flag(s) \"service\" not set"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test rollback of a file without history
+echo -n "Test: Rollback a file without an audit log (should fail) ... "
+if ECS_TAG_SHIFT_AUDIT_LOG= $BINARY rollback $EXAMPLES_DIR/task-definition.json 2>&1 | grep -q "audit-log (or ECS_TAG_SHIFT_AUDIT_LOG) is required"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test rollback with an empty audit log
+AUDIT_DIR=$(mktemp -d)
+echo -n "Test: Rollback with an empty audit log (should fail) ... "
+if $BINARY rollback $EXAMPLES_DIR/task-definition.json --audit-log "$AUDIT_DIR/empty.jsonl" 2>&1 | grep -q "no previous image found"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
+# Test that the audit log of shift is queried by history and used by rollback
+cp "$EXAMPLES_DIR/task-definition.json" "$AUDIT_DIR/task-definition.json"
+echo -n "Test: Shift audit log, history and rollback ... "
+$BINARY shift "$AUDIT_DIR/task-definition.json" -c web --tag v9.0.0 -w --audit-log "$AUDIT_DIR/audit.jsonl" > /dev/null 2>&1
+if $BINARY history --audit-log "$AUDIT_DIR/audit.jsonl" --file "$AUDIT_DIR/task-definition.json" -c web 2>&1 | grep -q ":v9.0.0$" && \
+   ECS_TAG_SHIFT_AUDIT_LOG="$AUDIT_DIR/audit.jsonl" $BINARY rollback "$AUDIT_DIR/task-definition.json" -w > /dev/null 2>&1 && \
+   ! grep -q "v9.0.0" "$AUDIT_DIR/task-definition.json"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+rm -rf "$AUDIT_DIR"
+
+# Test prune with a revision
+echo -n "Test: Prune a revision instead of a family (should fail) ... "
+if $BINARY prune my-app:3 2>&1 | grep -q "invalid family"; then
+    echo -e "${GREEN}PASS${NC}"
+    ((passed++))
+else
+    echo -e "${RED}FAIL${NC}"
+    ((failed++))
+fi
+
 # Test invalid output format
 echo -n "Test: Invalid output format (should fail) ... "
 if $BINARY show $EXAMPLES_DIR/task-definition.json -o invalid 2>&1 | grep -q "invalid"; then
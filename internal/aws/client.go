@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Service describes an AWS service using the JSON 1.1 protocol
+type Service struct {
+	// SigningName is the service name used in signatures, e.g. "ecr"
+	SigningName string
+	// EndpointPrefix is the host prefix of the regional endpoint, e.g. "api.ecr"
+	EndpointPrefix string
+	// TargetPrefix is prepended to operation names in the X-Amz-Target header
+	TargetPrefix string
+}
+
+// ECR is the Amazon Elastic Container Registry API
+var ECR = Service{SigningName: "ecr", EndpointPrefix: "api.ecr", TargetPrefix: "AmazonEC2ContainerRegistry_V20150921"}
+
+// ECS is the Amazon Elastic Container Service API
+var ECS = Service{SigningName: "ecs", EndpointPrefix: "ecs", TargetPrefix: "AmazonEC2ContainerServiceV20141113"}
+
+// APIError is an error returned by an AWS API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s (HTTP %d)", e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Client calls AWS JSON APIs in a region
+type Client struct {
+	Region      string
+	Credentials Credentials
+	// Endpoint overrides the regional endpoint of every service (e.g. "http://localhost:4566")
+	Endpoint   string
+	HTTPClient *http.Client
+	// Now returns the signing time; time.Now is used if nil
+	Now func() time.Time
+}
+
+// endpoint returns the base URL of a service
+func (c *Client) endpoint(svc Service) string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+	suffix := "amazonaws.com"
+	if strings.HasPrefix(c.Region, "cn-") {
+		suffix = "amazonaws.com.cn"
+	}
+	return fmt.Sprintf("https://%s.%s.%s", svc.EndpointPrefix, c.Region, suffix)
+}
+
+// Call invokes an operation with input encoded as JSON and decodes the response into output
+func (c *Client) Call(ctx context.Context, svc Service, operation string, input, output interface{}) error {
+	if c.Region == "" {
+		return fmt.Errorf("AWS region is not set")
+	}
+	if c.Credentials.IsZero() {
+		return fmt.Errorf("AWS credentials are not set")
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", operation, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(svc)+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", operation, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", svc.TargetPrefix+"."+operation)
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	Sign(req, body, c.Credentials, c.Region, svc.SigningName, now())
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", operation, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", operation, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s failed: %w", operation, parseAPIError(resp.StatusCode, data))
+	}
+	if output == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, output); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// parseAPIError parses an error response such as {"__type": "...#ClientException", "message": "..."}
+func parseAPIError(status int, data []byte) *APIError {
+	var body struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	_ = json.Unmarshal(data, &body)
+	apiErr := &APIError{StatusCode: status, Code: body.Type, Message: body.Message}
+	if i := strings.LastIndex(apiErr.Code, "#"); i != -1 {
+		apiErr.Code = apiErr.Code[i+1:]
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = http.StatusText(status)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = body.MessageUpper
+	}
+	return apiErr
+}
@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClientCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-west-2/ecr/aws4_request") {
+			t.Errorf("unexpected Authorization header: %q", r.Header.Get("Authorization"))
+		}
+		var input map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken":
+			_, _ = w.Write([]byte(`{"authorizationData": [{"authorizationToken": "QVdTOnNlY3JldA=="}]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "com.amazonaws.ecr#InvalidParameterException", "message": "bad target"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Region: "us-west-2", Credentials: Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, Endpoint: server.URL}
+
+	var output struct {
+		AuthorizationData []struct {
+			AuthorizationToken string `json:"authorizationToken"`
+		} `json:"authorizationData"`
+	}
+	if err := client.Call(context.Background(), ECR, "GetAuthorizationToken", struct{}{}, &output); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if len(output.AuthorizationData) != 1 || output.AuthorizationData[0].AuthorizationToken != "QVdTOnNlY3JldA==" {
+		t.Errorf("Call() output = %+v", output)
+	}
+
+	err := client.Call(context.Background(), ECR, "Unknown", struct{}{}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "InvalidParameterException" || apiErr.Message != "bad target" {
+		t.Errorf("Call() error = %v, expected an APIError", err)
+	}
+}
+
+func TestClientEndpoint(t *testing.T) {
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{region: "us-east-1", expected: "https://api.ecr.us-east-1.amazonaws.com"},
+		{region: "cn-north-1", expected: "https://api.ecr.cn-north-1.amazonaws.com.cn"},
+	}
+	for _, tt := range tests {
+		c := &Client{Region: tt.region}
+		if got := c.endpoint(ECR); got != tt.expected {
+			t.Errorf("endpoint() = %q, expected %q", got, tt.expected)
+		}
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := `[default]
+aws_access_key_id = DEFAULTKEY
+aws_secret_access_key = defaultsecret
+
+# comment
+[ci]
+aws_access_key_id=CIKEY
+aws_secret_access_key=cisecret
+aws_session_token=citoken
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+
+	creds, err := LoadCredentials("")
+	if err != nil || creds.AccessKeyID != "DEFAULTKEY" || creds.SecretAccessKey != "defaultsecret" {
+		t.Errorf("LoadCredentials() = %+v, %v", creds, err)
+	}
+	creds, err = LoadCredentials("ci")
+	if err != nil || creds != (Credentials{AccessKeyID: "CIKEY", SecretAccessKey: "cisecret", SessionToken: "citoken"}) {
+		t.Errorf("LoadCredentials(ci) = %+v, %v", creds, err)
+	}
+
+	// The environment is used unless a profile is given
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "envsecret")
+	creds, err = LoadCredentials("")
+	if err != nil || creds.AccessKeyID != "ENVKEY" {
+		t.Errorf("LoadCredentials() with environment = %+v, %v", creds, err)
+	}
+	creds, err = LoadCredentials("ci")
+	if err != nil || creds.AccessKeyID != "CIKEY" {
+		t.Errorf("LoadCredentials(ci) with environment = %+v, %v", creds, err)
+	}
+}
+
+func TestLoadCredentialsUnsupportedSource(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	content := `[profile assume]
+role_arn = arn:aws:iam::123456789012:role/deploy
+source_profile = default
+
+[profile sso]
+sso_session = my-sso
+sso_account_id = 123456789012
+
+[profile process]
+credential_process = /usr/local/bin/get-credentials
+`
+	if err := os.WriteFile(config, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "ENVKEY")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	tests := map[string]string{"assume": "role_arn", "sso": "sso_session", "process": "credential_process"}
+	for profile, source := range tests {
+		_, err := LoadCredentials(profile)
+		if err == nil || !strings.Contains(err.Error(), "profile "+profile+" uses "+source) {
+			t.Errorf("LoadCredentials(%s) error = %v, expected %s to be named", profile, err, source)
+		}
+	}
+}
+
+func TestLoadRegion(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	content := `[default]
+region = us-east-1
+
+[profile ci]
+region = ap-northeast-1
+aws_access_key_id = CONFIGKEY
+aws_secret_access_key = configsecret
+`
+	if err := os.WriteFile(config, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	if region, err := LoadRegion(""); err != nil || region != "us-east-1" {
+		t.Errorf("LoadRegion() = %q, %v", region, err)
+	}
+	if region, err := LoadRegion("ci"); err != nil || region != "ap-northeast-1" {
+		t.Errorf("LoadRegion(ci) = %q, %v", region, err)
+	}
+	if creds, err := LoadCredentials("ci"); err != nil || creds.AccessKeyID != "CONFIGKEY" {
+		t.Errorf("LoadCredentials(ci) from the config file = %+v, %v", creds, err)
+	}
+
+	t.Setenv("AWS_REGION", "eu-west-1")
+	client, err := NewClient("ci", "", "http://localhost:4566")
+	if err != nil || client.Region != "eu-west-1" || client.Endpoint != "http://localhost:4566" {
+		t.Errorf("NewClient() = %+v, %v", client, err)
+	}
+
+	t.Setenv("AWS_REGION", "")
+	if _, err := NewClient("missing", "", ""); err == nil || !strings.Contains(err.Error(), "region is not set") {
+		t.Errorf("NewClient() error = %v, expected a missing region error", err)
+	}
+}
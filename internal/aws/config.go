@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadRegion returns the region from the AWS_REGION/AWS_DEFAULT_REGION environment variables, or
+// the region of profile in the shared config file (AWS_CONFIG_FILE or ~/.aws/config). An empty
+// profile means AWS_PROFILE, or "default". An empty region is returned if none is configured.
+func LoadRegion(profile string) (string, error) {
+	for _, env := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(env); region != "" {
+			return region, nil
+		}
+	}
+	values, err := configProfile(profile)
+	if err != nil {
+		return "", err
+	}
+	return values["region"], nil
+}
+
+// configProfile returns the settings of a profile in the shared config file. Profiles other than
+// "default" are written as [profile name] there.
+func configProfile(profile string) (map[string]string, error) {
+	path := os.Getenv("AWS_CONFIG_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", "config")
+	}
+	sections, err := loadINI(path)
+	if err != nil {
+		return nil, err
+	}
+	name := resolveProfile(profile)
+	if values, ok := sections["profile "+name]; ok {
+		return values, nil
+	}
+	return sections[name], nil
+}
+
+// NewClient creates a client using the credentials of profile. An empty region is loaded with
+// LoadRegion, and an empty endpoint means the regional endpoint of each service.
+func NewClient(profile, region, endpoint string) (*Client, error) {
+	creds, err := LoadCredentials(profile)
+	if err != nil {
+		return nil, err
+	}
+	if region == "" {
+		if region, err = LoadRegion(profile); err != nil {
+			return nil, err
+		}
+	}
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is not set (use --region, AWS_REGION or the region of the profile in ~/.aws/config)")
+	}
+	return &Client{Region: region, Credentials: creds, Endpoint: endpoint}, nil
+}
@@ -0,0 +1,123 @@
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Credentials holds AWS access keys
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// IsZero reports whether the credentials are empty
+func (c Credentials) IsZero() bool {
+	return c.AccessKeyID == "" && c.SecretAccessKey == ""
+}
+
+// unsupportedSources lists the profile keys of credential sources that LoadCredentials cannot use
+var unsupportedSources = []string{"role_arn", "credential_process", "sso_session", "sso_start_url", "sso_account_id", "sso_role_name", "web_identity_token_file"}
+
+// LoadCredentials loads credentials from profile in the shared credentials file
+// (AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials) or the shared config file. If profile is
+// empty, the AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY environment variables are used if set, or
+// else AWS_PROFILE or "default". Only access keys are supported; a profile using another
+// credential source such as role_arn or SSO is an error. Empty credentials are returned if none
+// are configured.
+func LoadCredentials(profile string) (Credentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" && profile == "" {
+		return Credentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	sections, err := loadINI(path)
+	if err != nil {
+		return Credentials{}, err
+	}
+	values := sections[resolveProfile(profile)]
+	config, err := configProfile(profile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	for _, key := range unsupportedSources {
+		if values[key] != "" || config[key] != "" {
+			return Credentials{}, fmt.Errorf("profile %s uses %s, which is not supported; use access keys, e.g. from aws configure export-credentials", resolveProfile(profile), key)
+		}
+	}
+	if values["aws_access_key_id"] == "" {
+		// Keys may also be set in the shared config file
+		values = config
+	}
+	return Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	}, nil
+}
+
+// resolveProfile returns profile, AWS_PROFILE or "default"
+func resolveProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("AWS_PROFILE"); env != "" {
+		return env
+	}
+	return "default"
+}
+
+// loadINI reads an AWS shared config or credentials file into sections of lower-cased keys.
+// A missing file yields no sections.
+func loadINI(path string) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sections, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var current map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if current = sections[name]; current == nil {
+				current = make(map[string]string)
+				sections[name] = current
+			}
+		case current != nil:
+			if key, value, ok := strings.Cut(line, "="); ok {
+				current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return sections, nil
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"os"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// addAuditLogFlag adds the --audit-log flag to a command
+func addAuditLogFlag(cmd *cobra.Command, path *string, usage string) {
+	cmd.Flags().StringVar(path, "audit-log", "", usage+" (default: $"+history.EnvAuditLog+")")
+}
+
+// auditLogPath returns path, or the audit log set in the environment if it is empty
+func auditLogPath(path string) string {
+	if path != "" {
+		return path
+	}
+	return os.Getenv(history.EnvAuditLog)
+}
+
+// auditRecord describes the image changes a command made to a document
+type auditRecord struct {
+	command string
+	// source is the absolute path or ecs:// name of the input, or "" for stdin
+	source string
+	// file is the absolute path of the file written, the ARN of the task definition registered, or
+	// "" for stdout
+	file    string
+	tag     string
+	input   []byte
+	output  []byte
+	changes []history.Change
+}
+
+// recordAudit appends a record to the audit log at path (or the environment). Nothing is recorded
+// if no audit log is set or nothing changed.
+func recordAudit(path string, r auditRecord) error {
+	path = auditLogPath(path)
+	if path == "" || len(r.changes) == 0 {
+		return nil
+	}
+	return history.Append(path, history.Entry{
+		Time:       time.Now().UTC(),
+		User:       history.CurrentUser(),
+		Command:    r.command,
+		Source:     r.source,
+		File:       r.file,
+		Tag:        r.tag,
+		InputHash:  history.Digest(r.input),
+		OutputHash: history.Digest(r.output),
+		Changes:    r.changes,
+	})
+}
+
+// auditSource returns the source of a document for the audit log
+func auditSource(doc taskdef.Document, args []string, fromECS string) string {
+	if name, err := ecsSourceName(args, fromECS); err == nil && name != "" {
+		return ecsScheme + name
+	}
+	return history.AbsPath(doc.Source())
+}
+
+// writtenFile returns the absolute path of the file a document is written back to, or "" if it
+// is written to stdout
+func writtenFile(doc taskdef.Document, overwrite bool) string {
+	if !overwrite {
+		return ""
+	}
+	return history.AbsPath(doc.Source())
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+	"github.com/spf13/cobra"
+)
+
+// AWSOptions represents the options of commands that call AWS APIs
+type AWSOptions struct {
+	Profile     string
+	Region      string
+	EndpointURL string
+	// ECS is the client to use; if nil, one is created from the options
+	ECS *ecs.Client
+}
+
+// addAWSFlags adds the --profile, --region and --endpoint-url flags to a command
+func addAWSFlags(cmd *cobra.Command, opts *AWSOptions) {
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "AWS profile (default: AWS_PROFILE or default)")
+	cmd.Flags().StringVar(&opts.Region, "region", "", "AWS region (default: AWS_REGION or the region of the profile)")
+	cmd.Flags().StringVar(&opts.EndpointURL, "endpoint-url", "", "Custom ECS endpoint URL, e.g. a local mock server")
+}
+
+// ecsClient returns the ECS client of the options, or a client created from them
+func (o *AWSOptions) ecsClient() (*ecs.Client, error) {
+	if o.ECS != nil {
+		return o.ECS, nil
+	}
+	return ecs.NewClient(o.Profile, o.Region, o.EndpointURL)
+}
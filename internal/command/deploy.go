@@ -0,0 +1,136 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// DeployOptions represents options for the deploy command
+type DeployOptions struct {
+	Mode           taskdef.LoadMode
+	Load           taskdef.LoadOptions
+	FromECS        string
+	AWS            AWSOptions
+	Cluster        string
+	Service        string
+	Timeout        time.Duration
+	Interval       time.Duration
+	NoWait         bool
+	SkipValidation bool
+}
+
+// NewDeployCommand creates a new deploy command
+func NewDeployCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &DeployOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "deploy [file | ecs://family[:revision]] --service <service>",
+		Short: "Register a task definition and deploy it to an ECS service",
+		Long: `Register a task definition, update an ECS service to the new revision and wait
+until the deployment completes.
+
+The deployment fails if ECS marks it as FAILED or the deployment circuit breaker
+rolls it back. Progress and service events are written to stderr, and the ARN of
+the deployed revision to stdout.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runDeploy(args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Cluster, "cluster", "default", "ECS cluster of the service")
+	cmd.Flags().StringVar(&opts.Service, "service", "", "ECS service to update (required)")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "Maximum time to wait for the deployment")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 15*time.Second, "Time between deployment status checks")
+	cmd.Flags().BoolVar(&opts.NoWait, "no-wait", false, "Return after updating the service without waiting for the deployment")
+	cmd.Flags().BoolVar(&opts.SkipValidation, "skip-validation", false, "Deploy the task definition even if it fails validation")
+	cmd.Flags().StringVar(&opts.FromECS, "from-ecs", "", "Read the task definition from ECS (family, family:revision or ARN)")
+	addAWSFlags(cmd, &opts.AWS)
+
+	if err := cmd.MarkFlagRequired("service"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+func runDeploy(args []string, opts *DeployOptions) error {
+	if opts.Service == "" {
+		return fmt.Errorf("service is required")
+	}
+	if opts.Mode != taskdef.ModeTask {
+		return fmt.Errorf("deploy is only supported in task mode")
+	}
+	if !opts.NoWait && opts.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if !opts.NoWait && opts.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	doc, err := loadSource(args, opts.FromECS, &opts.AWS, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+	if errs := validateDocument(doc); len(errs) > 0 && !opts.SkipValidation {
+		return validationFailure("task definition is invalid (use --skip-validation to deploy it anyway)", errs)
+	}
+
+	client, err := opts.AWS.ecsClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	registered, err := client.RegisterTaskDefinition(ctx, doc.(*taskdef.TaskDocument).TaskDefinition)
+	if err != nil {
+		return fmt.Errorf("failed to register task definition: %w", err)
+	}
+	arn := ecs.TaskDefinitionARN(registered)
+	progress := output.NewDeploymentProgress(os.Stderr, nil)
+	if err := progress.Registered(arn); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write progress: %v\n", err)
+	}
+
+	svc, err := client.UpdateService(ctx, opts.Cluster, opts.Service, arn)
+	if err != nil {
+		return fmt.Errorf("failed to update service %s: %w", opts.Service, err)
+	}
+	deployment := svc.Primary()
+	if deployment == nil {
+		return fmt.Errorf("service %s has no primary deployment after the update", opts.Service)
+	}
+	if err := progress.ServiceUpdated(opts.Cluster, svc, deployment); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write progress: %v\n", err)
+	}
+
+	if !opts.NoWait {
+		ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+
+		_, err := client.WaitForDeployment(ctx, opts.Cluster, opts.Service, deployment.ID, ecs.WaitOptions{
+			Interval:       opts.Interval,
+			TaskDefinition: arn,
+			Progress: func(svc *ecs.Service, d *ecs.Deployment) {
+				if err := progress.Update(svc, d); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: failed to write progress: %v\n", err)
+				}
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, arn)
+	return err
+}
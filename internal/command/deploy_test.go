@@ -0,0 +1,139 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// newDeployECS starts a fake ECS endpoint for deploying my-app:8 to prod/web. describe returns
+// the deployments of the service for each DescribeServices call, starting at 0.
+func newDeployECS(t *testing.T, describe func(call int) string) *fakeECS {
+	t.Helper()
+	describeCalls := 0
+	return newFakeECS(t, map[string]ecsHandler{
+		"RegisterTaskDefinition": func(map[string]interface{}) string {
+			return `{"taskDefinition": {"family": "my-app", "revision": 8, "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8", "containerDefinitions": []}}`
+		},
+		"UpdateService": func(body map[string]interface{}) string {
+			if body["cluster"] != "prod" || body["service"] != "web" || body["taskDefinition"] != "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8" {
+				t.Errorf("unexpected UpdateService request %v", body)
+			}
+			return `{"service": {"serviceName": "web", "deployments": [{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8", "rolloutState": "IN_PROGRESS"}]}}`
+		},
+		"DescribeServices": func(map[string]interface{}) string {
+			deployments := describe(describeCalls)
+			describeCalls++
+			return `{"services": [{"serviceName": "web", "deployments": [` + deployments + `]}]}`
+		},
+	})
+}
+
+func TestDeploy(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	tmpFile := filepath.Join(t.TempDir(), "task-definition.json")
+	if err := os.WriteFile(tmpFile, []byte(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "my-app:v2", "memory": 512}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		describe       func(call int) string
+		wantErr        string
+		wantOperations string
+	}{
+		{
+			name: "Completed",
+			describe: func(call int) string {
+				if call == 0 {
+					return `{"id": "ecs-svc/2", "status": "PRIMARY", "rolloutState": "IN_PROGRESS", "desiredCount": 1}`
+				}
+				return `{"id": "ecs-svc/2", "status": "PRIMARY", "rolloutState": "COMPLETED", "desiredCount": 1, "runningCount": 1}`
+			},
+			wantOperations: "RegisterTaskDefinition,UpdateService,DescribeServices,DescribeServices",
+		},
+		{
+			name: "Rolled back",
+			describe: func(int) string {
+				return `{"id": "ecs-svc/3", "status": "PRIMARY", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7", "rolloutState": "IN_PROGRESS"},
+				        {"id": "ecs-svc/2", "status": "ACTIVE", "rolloutState": "FAILED", "rolloutStateReason": "circuit breaker"}`
+			},
+			wantErr:        "deployment was rolled back to arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7",
+			wantOperations: "RegisterTaskDefinition,UpdateService,DescribeServices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newDeployECS(t, tt.describe)
+			opts := &DeployOptions{
+				Mode:     taskdef.ModeTask,
+				AWS:      AWSOptions{EndpointURL: fake.URL},
+				Cluster:  "prod",
+				Service:  "web",
+				Timeout:  time.Minute,
+				Interval: time.Millisecond,
+			}
+
+			out, err := captureStdout(t, func() error { return runDeploy([]string{tmpFile}, opts) })
+			if got := fake.calledOperations(); got != tt.wantOperations {
+				t.Errorf("operations = %s, expected %s", got, tt.wantOperations)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("runDeploy() error = %v, expected %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runDeploy() error = %v", err)
+			}
+			if out != "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8\n" {
+				t.Errorf("runDeploy() output = %q", out)
+			}
+		})
+	}
+}
+
+func TestDeployNoWait(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	tmpFile := filepath.Join(t.TempDir(), "task-definition.json")
+	if err := os.WriteFile(tmpFile, []byte(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "my-app:v2", "memory": 512}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := newDeployECS(t, func(int) string { return "" })
+	opts := &DeployOptions{Mode: taskdef.ModeTask, AWS: AWSOptions{EndpointURL: fake.URL}, Cluster: "prod", Service: "web", NoWait: true}
+	if _, err := captureStdout(t, func() error { return runDeploy([]string{tmpFile}, opts) }); err != nil {
+		t.Fatalf("runDeploy() error = %v", err)
+	}
+	if got := fake.calledOperations(); got != "RegisterTaskDefinition,UpdateService" {
+		t.Errorf("operations = %s", got)
+	}
+}
+
+func TestDeployInvalidWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		interval time.Duration
+		wantErr  string
+	}{
+		{name: "Zero timeout", timeout: 0, interval: time.Second, wantErr: "--timeout must be positive"},
+		{name: "Negative interval", timeout: time.Minute, interval: -time.Second, wantErr: "--interval must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &DeployOptions{Mode: taskdef.ModeTask, Service: "web", Timeout: tt.timeout, Interval: tt.interval}
+			if err := runDeploy([]string{"task-definition.json"}, opts); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("runDeploy() error = %v, expected %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ecsHandler returns the response of a fake ECS operation to a request body
+type ecsHandler func(body map[string]interface{}) string
+
+// fakeECS is a fake ECS endpoint that answers the operations it has handlers for and fails the
+// test on any other
+type fakeECS struct {
+	*httptest.Server
+	// operations are the operations called, in order
+	operations []string
+}
+
+// newFakeECS starts a fake ECS endpoint, which is closed when the test ends
+func newFakeECS(t *testing.T, handlers map[string]ecsHandler) *fakeECS {
+	t.Helper()
+	fake := &fakeECS{}
+	fake.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonEC2ContainerServiceV20141113.")
+		fake.operations = append(fake.operations, operation)
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		handler, ok := handlers[operation]
+		if !ok {
+			t.Errorf("unexpected operation %s", operation)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(handler(body)))
+	}))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+// calledOperations returns the operations called, separated by commas
+func (f *fakeECS) calledOperations() string {
+	return strings.Join(f.operations, ",")
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// HistoryOptions represents options for the history command
+type HistoryOptions struct {
+	AuditLog      string
+	File          string
+	ContainerName string
+	Image         string
+	Since         string
+	Until         string
+	OutputFormat  string
+	Format        output.OutputFormat
+}
+
+// NewHistoryCommand creates a new history command
+func NewHistoryCommand() *cobra.Command {
+	opts := &HistoryOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Query the audit log of image changes",
+		Long: `Show the image changes recorded in the audit log by shift and rollback, oldest
+first, optionally filtered by file, container, image or date.
+
+--since and --until take a date (YYYY-MM-DD, local time) or an RFC 3339 time;
+both are inclusive.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHistory(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.AuditLog, "audit-log", "", "Audit log to read (default: $"+history.EnvAuditLog+")")
+	cmd.Flags().StringVarP(&opts.File, "file", "f", "", "Only show changes of this file or ecs://family[:revision]")
+	cmd.Flags().StringVarP(&opts.ContainerName, "container", "c", "", "Only show changes of this container")
+	cmd.Flags().StringVarP(&opts.Image, "image", "i", "", "Only show changes from or to an image containing this text")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show changes at or after this date or time")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Only show changes at or before this date or time")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func runHistory(opts *HistoryOptions) error {
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	if opts.Format != output.FormatText && opts.Format != output.FormatJSON {
+		return fmt.Errorf("invalid output format: %s (must be text or json)", opts.OutputFormat)
+	}
+	path := auditLogPath(opts.AuditLog)
+	if path == "" {
+		return fmt.Errorf("--audit-log (or %s) is required", history.EnvAuditLog)
+	}
+
+	filter := history.Filter{Container: opts.ContainerName, Image: opts.Image}
+	if opts.File != "" {
+		filter.File = opts.File
+		if !strings.HasPrefix(opts.File, ecsScheme) {
+			filter.File = history.AbsPath(opts.File)
+		}
+	}
+	var err error
+	if filter.Since, err = parseHistoryTime(opts.Since, false); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseHistoryTime(opts.Until, true); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	entries, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	return output.FormatHistory(os.Stdout, history.Query(entries, filter), opts.Format)
+}
+
+// parseHistoryTime parses an RFC 3339 time or a date in local time. With endOfDay, a date means
+// the last moment of that day.
+func parseHistoryTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not a date (YYYY-MM-DD) or RFC 3339 time", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
@@ -0,0 +1,160 @@
+package command
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+	"github.com/dev-shimada/ecs-tag-shift/internal/registry"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestShiftAuditLog(t *testing.T) {
+	t.Setenv(history.EnvAuditLog, "")
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "task-definition.json")
+	auditLog := filepath.Join(dir, "audit.jsonl")
+	input := []byte(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "my-app:v1.4.1", "memory": 512}, {"name": "log", "image": "fluent-bit:2"}]}`)
+	if err := os.WriteFile(tmpFile, input, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &ShiftOptions{Mode: taskdef.ModeTask, Tag: "v1.4.2", ContainerName: "web", OutputFormat: "json", Overwrite: true, AuditLog: auditLog}
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+	written, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := history.Load(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("audit log has %d entries, expected 1", len(entries))
+	}
+	e := entries[0]
+	abs, _ := filepath.Abs(tmpFile)
+	if e.Command != history.CommandShift || e.Source != abs || e.File != abs || e.Tag != "v1.4.2" || e.User != history.CurrentUser() {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.InputHash != history.Digest(input) || e.OutputHash != history.Digest(written) {
+		t.Errorf("hashes = %s, %s", e.InputHash, e.OutputHash)
+	}
+	if len(e.Changes) != 1 || e.Changes[0].OldImage != "my-app:v1.4.1" || e.Changes[0].NewImage != "my-app:v1.4.2" {
+		t.Errorf("changes = %+v", e.Changes)
+	}
+
+	// The audit log can also be set in the environment
+	t.Setenv(history.EnvAuditLog, auditLog)
+	opts = &ShiftOptions{Mode: taskdef.ModeTask, Tag: "v1.5.0", ContainerName: "web", OutputFormat: "json"}
+	if _, err := captureStdout(t, func() error { return runShift([]string{tmpFile}, opts) }); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+
+	out, err := captureStdout(t, func() error {
+		return runHistory(&HistoryOptions{File: tmpFile, Image: "v1.5.0", OutputFormat: "text"})
+	})
+	if err != nil {
+		t.Fatalf("runHistory() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "my-app:v1.4.2 -> my-app:v1.5.0") || !strings.Contains(lines[1], abs) {
+		t.Errorf("runHistory() output =\n%s", out)
+	}
+}
+
+func TestShiftLatestMatchingAuditLog(t *testing.T) {
+	t.Setenv(history.EnvAuditLog, "")
+	tags := map[string]string{
+		"/v2/my-app/tags/list":  `{"tags": ["v1.0.0", "v1.10.0", "v1.9.0"]}`,
+		"/v2/sidecar/tags/list": `{"tags": ["v0.1.0", "v0.2.0"]}`,
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := tags[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "containers.json")
+	auditLog := filepath.Join(dir, "audit.jsonl")
+	content := `[
+  {"name": "web", "image": "` + host + `/my-app:v1.0.0"},
+  {"name": "worker", "image": "` + host + `/my-app:v1.0.0"},
+  {"name": "sidecar", "image": "` + host + `/sidecar:v0.1.0"}
+]`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	shift := func(image string) history.Entry {
+		t.Helper()
+		opts := &ShiftOptions{Mode: taskdef.ModeContainer, LatestMatching: `^v\d+\.\d+\.\d+$`, ImageName: image, OutputFormat: "json", Overwrite: true, AuditLog: auditLog, Registry: client}
+		if err := runShift([]string{tmpFile}, opts); err != nil {
+			t.Fatalf("runShift() error = %v", err)
+		}
+		entries, err := history.Load(auditLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entries[len(entries)-1]
+	}
+
+	// The entry has the tag chosen for the only repository shifted
+	e := shift("my-app")
+	if e.Tag != "v1.10.0" || len(e.Changes) != 2 || e.Changes[0].Tag != "v1.10.0" || e.Changes[1].Tag != "v1.10.0" {
+		t.Errorf("entry = %+v", e)
+	}
+
+	// Tags chosen per repository are recorded per change
+	tags["/v2/my-app/tags/list"] = `{"tags": ["v1.10.0", "v1.11.0"]}`
+	e = shift("")
+	got := make(map[string]string)
+	for _, c := range e.Changes {
+		got[c.Container] = c.Tag
+	}
+	if e.Tag != "" || len(got) != 3 || got["web"] != "v1.11.0" || got["worker"] != "v1.11.0" || got["sidecar"] != "v0.2.0" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		endOfDay bool
+		expected time.Time
+		wantErr  bool
+	}{
+		{input: "", expected: time.Time{}},
+		{input: "2024-01-15T10:00:00Z", expected: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{input: "2024-01-15", expected: time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)},
+		{input: "2024-01-15", endOfDay: true, expected: time.Date(2024, 1, 15, 23, 59, 59, 999999999, time.Local)},
+		{input: "last week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseHistoryTime(tt.input, tt.endOfDay)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHistoryTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("parseHistoryTime() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
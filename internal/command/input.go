@@ -0,0 +1,132 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// readInput reads the file given as the first argument, or stdin if there is none.
+// name is the file name, or empty for stdin.
+func readInput(args []string) (data []byte, name string, err error) {
+	if len(args) > 0 {
+		data, err = os.ReadFile(args[0])
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read file: %w", err)
+		}
+		return data, args[0], nil
+	}
+
+	data, err = io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return data, "", nil
+}
+
+// loadDocument loads the file given as the first argument, or stdin if there is none
+func loadDocument(args []string, mode taskdef.LoadMode, opts taskdef.LoadOptions) (taskdef.Document, error) {
+	if len(args) > 0 {
+		return taskdef.LoadDocumentFromFile(args[0], mode, opts)
+	}
+	return taskdef.LoadDocument(os.Stdin, "", mode, opts)
+}
+
+// ecsScheme prefixes arguments naming a task definition in ECS, e.g. ecs://my-app:12
+const ecsScheme = "ecs://"
+
+// loadSource loads the task definition named by fromECS or an ecs://family[:revision] argument
+// from the ECS DescribeTaskDefinition API, or else the file given as the first argument or stdin.
+// Documents read from ECS have no source file.
+func loadSource(args []string, fromECS string, awsOpts *AWSOptions, mode taskdef.LoadMode, opts taskdef.LoadOptions) (taskdef.Document, error) {
+	doc, _, err := loadSourceData(args, fromECS, awsOpts, mode, opts)
+	return doc, err
+}
+
+// loadSourceData is loadSource that also returns the bytes the document was loaded from
+func loadSourceData(args []string, fromECS string, awsOpts *AWSOptions, mode taskdef.LoadMode, opts taskdef.LoadOptions) (taskdef.Document, []byte, error) {
+	name, err := ecsSourceName(args, fromECS)
+	if err != nil {
+		return nil, nil, err
+	}
+	if name == "" {
+		data, file, err := readInput(args)
+		if err != nil {
+			return nil, nil, err
+		}
+		doc, err := taskdef.LoadDocument(bytes.NewReader(data), file, mode, opts)
+		return doc, data, err
+	}
+	if mode != taskdef.ModeTask {
+		return nil, nil, fmt.Errorf("task definitions from ECS are only supported in task mode")
+	}
+
+	client, err := awsOpts.ecsClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := client.DescribeTaskDefinition(context.Background(), name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to describe task definition %s: %w", name, err)
+	}
+	doc, err := taskdef.LoadDocument(bytes.NewReader(data), "", mode, opts)
+	return doc, data, err
+}
+
+// ecsSourceName returns the task definition named by fromECS or an ecs:// argument, or "" if the
+// input is a file or stdin
+func ecsSourceName(args []string, fromECS string) (string, error) {
+	name := fromECS
+	if len(args) > 0 {
+		if name != "" {
+			return "", fmt.Errorf("--from-ecs cannot be used with a file argument")
+		}
+		if strings.HasPrefix(args[0], ecsScheme) {
+			name = strings.TrimPrefix(args[0], ecsScheme)
+			if name == "" {
+				return "", fmt.Errorf("%s requires a task definition family", ecsScheme)
+			}
+		}
+	}
+	return name, nil
+}
+
+// writeDocument writes a document back to its source file if overwrite is set and it was read
+// from a file, or to stdout otherwise
+func writeDocument(doc taskdef.Document, overwrite bool, format output.OutputFormat) error {
+	_, err := writeDocumentData(doc, overwrite, format)
+	return err
+}
+
+// writeDocumentData is writeDocument that also returns the bytes written. The document is encoded
+// before the file is opened, so an encoding error leaves the file untouched.
+func writeDocumentData(doc taskdef.Document, overwrite bool, format output.OutputFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := doc.Encode(&buf, taskdef.Encoding(format)); err != nil {
+		return nil, err
+	}
+	if !overwrite || doc.Source() == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return buf.Bytes(), err
+	}
+
+	file, err := os.Create(doc.Source())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for writing: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", err)
+		}
+	}()
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
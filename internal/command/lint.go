@@ -0,0 +1,115 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// LintOptions represents options for the lint command
+type LintOptions struct {
+	Mode         taskdef.LoadMode
+	Load         taskdef.LoadOptions
+	OutputFormat string
+	Format       output.OutputFormat
+	Disable      []string
+	FailOn       string
+}
+
+// NewLintCommand creates a new lint command
+func NewLintCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &LintOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "lint [file]",
+		Short: "Check a task definition or container definitions for operational best practices",
+		Long: `Check a task definition or container definitions file for operational best practices.
+
+Rules can be disabled for a whole file with a comment in the JSONC input:
+
+  // ecs-tag-shift-disable image-tag, readonly-root-filesystem`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runLint(args, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "text", "Output format (json, yaml, text, sarif)")
+	cmd.Flags().StringSliceVar(&opts.Disable, "disable", nil, "Rules to disable (can be repeated or comma-separated)")
+	cmd.Flags().StringVar(&opts.FailOn, "fail-on", "error", "Minimum severity that makes the command fail (error, warning, info)")
+
+	return cmd
+}
+
+func runLint(args []string, opts *LintOptions) error {
+	// Parse output format
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	switch opts.Format {
+	case output.FormatJSON, output.FormatYAML, output.FormatText, output.FormatSARIF:
+	default:
+		return fmt.Errorf("invalid output format: %s (must be json, yaml, text, or sarif)", opts.OutputFormat)
+	}
+	failOn, err := taskdef.ParseSeverity(opts.FailOn)
+	if err != nil {
+		return err
+	}
+	if opts.Mode != taskdef.ModeTask && opts.Mode != taskdef.ModeContainer {
+		return fmt.Errorf("lint is only supported in task and container modes")
+	}
+
+	rules := taskdef.DefaultLintRules()
+	known := make(map[string]bool)
+	for _, r := range rules {
+		known[r.ID] = true
+	}
+	for _, id := range opts.Disable {
+		if !known[id] {
+			return fmt.Errorf("unknown lint rule: %s", id)
+		}
+	}
+
+	// Read the raw input so that disable comments can be found before they are stripped
+	raw, name, err := readInput(args)
+	if err != nil {
+		return err
+	}
+
+	doc, positions, err := taskdef.LoadWithPositions(bytes.NewReader(raw), name, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+
+	disabled := taskdef.ParseLintDirectives(raw)
+	for _, id := range opts.Disable {
+		disabled[id] = true
+	}
+
+	var findings []taskdef.LintFinding
+	switch d := doc.(type) {
+	case *taskdef.TaskDocument:
+		findings = taskdef.Lint(d.TaskDefinition, rules, disabled)
+	case *taskdef.ContainerDocument:
+		findings = taskdef.LintContainerDefinitions(d.ContainerDefinitions, rules, disabled)
+	}
+
+	if err := output.FormatLintFindings(os.Stdout, findings, rules, output.Source{URI: name, Positions: positions}, opts.Format); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, f := range findings {
+		if f.Severity.AtLeast(failOn) {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("lint found %d problem(s) at or above %s severity", failed, failOn)
+	}
+	return nil
+}
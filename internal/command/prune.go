@@ -0,0 +1,181 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// PruneClient is the part of the ECS API used by prune. *ecs.Client implements it; tests
+// substitute a fake.
+type PruneClient interface {
+	ListTaskDefinitions(ctx context.Context, family, status string) ([]string, error)
+	ListClusters(ctx context.Context) ([]string, error)
+	ListServices(ctx context.Context, cluster string) ([]string, error)
+	DescribeServices(ctx context.Context, cluster string, services []string) ([]ecs.Service, error)
+	DeregisterTaskDefinition(ctx context.Context, taskDefinition string) error
+	DeleteTaskDefinitions(ctx context.Context, taskDefinitions []string) error
+}
+
+// PruneOptions represents options for the prune command
+type PruneOptions struct {
+	AWS          AWSOptions
+	Keep         int
+	Clusters     []string
+	Delete       bool
+	DryRun       bool
+	OutputFormat string
+	Format       output.OutputFormat
+	// Client is the ECS client to use; if nil, one is created from the AWS options
+	Client PruneClient
+}
+
+// NewPruneCommand creates a new prune command
+func NewPruneCommand() *cobra.Command {
+	opts := &PruneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "prune <family>",
+		Short: "Deregister old revisions of a task definition family",
+		Long: `Deregister the ACTIVE revisions of a task definition family except the newest
+ones (--keep) and those used by a service or one of its deployments.
+
+Services are looked up in the clusters given with --cluster, or in every cluster
+of the region. With --delete, the revisions are deleted permanently after being
+deregistered, as are the INACTIVE revisions no service uses.
+
+A table of the revisions and what is done with them is written first; use
+--dry-run to only write the table.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrune(args, opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Keep, "keep", 10, "Number of the newest ACTIVE revisions to keep")
+	cmd.Flags().StringSliceVar(&opts.Clusters, "cluster", nil, "Clusters whose services are checked (can be repeated or comma-separated; default: all clusters)")
+	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete the revisions permanently instead of only deregistering them")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only show what would be removed")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "text", "Output format (text, json)")
+	addAWSFlags(cmd, &opts.AWS)
+
+	return cmd
+}
+
+func runPrune(args []string, opts *PruneOptions) error {
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	if opts.Format != output.FormatText && opts.Format != output.FormatJSON {
+		return fmt.Errorf("invalid output format: %s (must be text or json)", opts.OutputFormat)
+	}
+	if opts.Keep < 0 {
+		return fmt.Errorf("--keep must not be negative")
+	}
+	family := strings.TrimPrefix(args[0], ecsScheme)
+	if family == "" || strings.ContainsAny(family, ":/") {
+		return fmt.Errorf("invalid family: %s (prune takes a family name, not a revision or ARN)", args[0])
+	}
+
+	client := opts.Client
+	if client == nil {
+		c, err := opts.AWS.ecsClient()
+		if err != nil {
+			return err
+		}
+		client = c
+	}
+	ctx := context.Background()
+
+	active, err := client.ListTaskDefinitions(ctx, family, "ACTIVE")
+	if err != nil {
+		return fmt.Errorf("failed to list revisions of %s: %w", family, err)
+	}
+	var inactive []string
+	if opts.Delete {
+		inactive, err = client.ListTaskDefinitions(ctx, family, "INACTIVE")
+		if err != nil {
+			return fmt.Errorf("failed to list revisions of %s: %w", family, err)
+		}
+	}
+	inUse, err := revisionsInUse(ctx, client, family, opts.Clusters)
+	if err != nil {
+		return err
+	}
+
+	plan := ecs.PlanPrune(active, inactive, ecs.PrunePlanOptions{Keep: opts.Keep, InUse: inUse, Delete: opts.Delete})
+	if err := output.FormatPrunePlan(os.Stdout, plan, opts.Format); err != nil {
+		return err
+	}
+	if opts.DryRun {
+		return nil
+	}
+
+	var deleted []string
+	for _, r := range plan {
+		if r.Action == ecs.PruneKeep {
+			continue
+		}
+		if r.Status == "ACTIVE" {
+			if err := client.DeregisterTaskDefinition(ctx, r.ARN); err != nil {
+				return fmt.Errorf("failed to deregister %s: %w", r.ARN, err)
+			}
+			fmt.Fprintf(os.Stderr, "Deregistered %s\n", r.ARN)
+		}
+		if r.Action == ecs.PruneDelete {
+			deleted = append(deleted, r.ARN)
+		}
+	}
+	if len(deleted) > 0 {
+		if err := client.DeleteTaskDefinitions(ctx, deleted); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Deleted %d revision(s)\n", len(deleted))
+	}
+	return nil
+}
+
+// revisionsInUse returns the revisions of a family used by the services of clusters (all
+// clusters if none are given), mapped to the services as cluster/service
+func revisionsInUse(ctx context.Context, client PruneClient, family string, clusters []string) (map[int][]string, error) {
+	if len(clusters) == 0 {
+		all, err := client.ListClusters(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clusters: %w", err)
+		}
+		clusters = all
+	}
+
+	inUse := make(map[int][]string)
+	for _, cluster := range clusters {
+		arns, err := client.ListServices(ctx, cluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services of cluster %s: %w", resourceName(cluster), err)
+		}
+		services, err := client.DescribeServices(ctx, cluster, arns)
+		if err != nil {
+			return nil, err
+		}
+		for _, svc := range services {
+			user := resourceName(cluster) + "/" + svc.ServiceName
+			seen := make(map[int]bool)
+			for _, td := range svc.TaskDefinitions() {
+				f, revision, ok := ecs.ParseTaskDefinitionARN(td)
+				if !ok || f != family || seen[revision] {
+					continue
+				}
+				seen[revision] = true
+				inUse[revision] = append(inUse[revision], user)
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// resourceName returns the name at the end of an ARN such as arn:aws:ecs:...:cluster/prod
+func resourceName(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
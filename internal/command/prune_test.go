@@ -0,0 +1,159 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+)
+
+// fakePruneClient is an in-memory PruneClient
+type fakePruneClient struct {
+	active       []string
+	inactive     []string
+	services     map[string][]ecs.Service
+	deregistered []string
+	deleted      []string
+}
+
+func (f *fakePruneClient) ListTaskDefinitions(_ context.Context, _ string, status string) ([]string, error) {
+	if status == "INACTIVE" {
+		return f.inactive, nil
+	}
+	return f.active, nil
+}
+
+func (f *fakePruneClient) ListClusters(context.Context) ([]string, error) {
+	var clusters []string
+	for cluster := range f.services {
+		clusters = append(clusters, "arn:aws:ecs:us-east-1:123456789012:cluster/"+cluster)
+	}
+	return clusters, nil
+}
+
+func (f *fakePruneClient) ListServices(_ context.Context, cluster string) ([]string, error) {
+	var arns []string
+	for _, svc := range f.services[resourceName(cluster)] {
+		arns = append(arns, svc.ServiceName)
+	}
+	return arns, nil
+}
+
+func (f *fakePruneClient) DescribeServices(_ context.Context, cluster string, _ []string) ([]ecs.Service, error) {
+	return f.services[resourceName(cluster)], nil
+}
+
+func (f *fakePruneClient) DeregisterTaskDefinition(_ context.Context, taskDefinition string) error {
+	f.deregistered = append(f.deregistered, taskDefinition)
+	return nil
+}
+
+func (f *fakePruneClient) DeleteTaskDefinitions(_ context.Context, taskDefinitions []string) error {
+	f.deleted = append(f.deleted, taskDefinitions...)
+	return nil
+}
+
+func TestPrune(t *testing.T) {
+	arn := func(revision int) string {
+		return fmt.Sprintf("arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:%d", revision)
+	}
+
+	tests := []struct {
+		name             string
+		opts             PruneOptions
+		wantDeregistered string
+		wantDeleted      string
+	}{
+		{
+			name:             "Deregister",
+			opts:             PruneOptions{Keep: 2},
+			wantDeregistered: "my-app:4,my-app:2",
+		},
+		{
+			name: "Dry run",
+			opts: PruneOptions{Keep: 2, DryRun: true},
+		},
+		{
+			name:             "Only the services of a cluster",
+			opts:             PruneOptions{Keep: 2, Clusters: []string{"stg"}},
+			wantDeregistered: "my-app:4,my-app:3,my-app:2",
+		},
+		{
+			name:             "Delete",
+			opts:             PruneOptions{Keep: 2, Delete: true},
+			wantDeregistered: "my-app:4,my-app:2",
+			wantDeleted:      "my-app:4,my-app:2,my-app:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePruneClient{
+				active:   []string{arn(6), arn(5), arn(4), arn(3), arn(2)},
+				inactive: []string{arn(1)},
+				services: map[string][]ecs.Service{
+					"prod": {{ServiceName: "web", TaskDefinition: arn(3), Deployments: []ecs.Deployment{{TaskDefinition: arn(3)}}}},
+					"stg":  {{ServiceName: "web", TaskDefinition: "arn:aws:ecs:us-east-1:123456789012:task-definition/other:2"}},
+				},
+			}
+			opts := tt.opts
+			opts.OutputFormat = "text"
+			opts.Client = client
+
+			out, err := captureStdout(t, func() error { return runPrune([]string{"my-app"}, &opts) })
+			if err != nil {
+				t.Fatalf("runPrune() error = %v", err)
+			}
+			if !strings.Contains(out, "REVISION") {
+				t.Errorf("runPrune() output = %s", out)
+			}
+			if got := shortNames(client.deregistered); got != tt.wantDeregistered {
+				t.Errorf("deregistered %s, expected %s", got, tt.wantDeregistered)
+			}
+			if got := shortNames(client.deleted); got != tt.wantDeleted {
+				t.Errorf("deleted %s, expected %s", got, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestPruneKeepsTaskSets(t *testing.T) {
+	arn := func(revision int) string {
+		return fmt.Sprintf("arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:%d", revision)
+	}
+	// A blue/green service of the CODE_DEPLOY deployment controller runs its revisions in task sets
+	client := &fakePruneClient{
+		active: []string{arn(5), arn(4), arn(3), arn(2)},
+		services: map[string][]ecs.Service{
+			"prod": {{ServiceName: "web", TaskDefinition: arn(5), TaskSets: []ecs.TaskSet{
+				{ID: "ecs-svc/2", Status: "PRIMARY", TaskDefinition: arn(2)},
+				{ID: "ecs-svc/3", Status: "ACTIVE", TaskDefinition: arn(3)},
+			}}},
+		},
+	}
+	opts := &PruneOptions{Keep: 1, OutputFormat: "text", Client: client}
+	if _, err := captureStdout(t, func() error { return runPrune([]string{"my-app"}, opts) }); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if got := shortNames(client.deregistered); got != "my-app:4" {
+		t.Errorf("deregistered %s, expected my-app:4", got)
+	}
+}
+
+func TestPruneInvalidFamily(t *testing.T) {
+	opts := &PruneOptions{Keep: 1, OutputFormat: "text", Client: &fakePruneClient{}}
+	if err := runPrune([]string{"my-app:3"}, opts); err == nil || !strings.Contains(err.Error(), "invalid family") {
+		t.Errorf("runPrune() error = %v", err)
+	}
+}
+
+// shortNames joins the family:revision of task definition ARNs
+func shortNames(arns []string) string {
+	names := make([]string, len(arns))
+	for i, arn := range arns {
+		names[i] = resourceName(arn)
+	}
+	return strings.Join(names, ",")
+}
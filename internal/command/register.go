@@ -0,0 +1,69 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// RegisterOptions represents options for the register command
+type RegisterOptions struct {
+	Mode           taskdef.LoadMode
+	Load           taskdef.LoadOptions
+	AWS            AWSOptions
+	SkipValidation bool
+}
+
+// NewRegisterCommand creates a new register command
+func NewRegisterCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &RegisterOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "register [file]",
+		Short: "Register a task definition with ECS",
+		Long: `Register a task definition with the ECS RegisterTaskDefinition API and print the
+ARN of the new revision. Read-only fields of described task definitions (such as
+taskDefinitionArn and revision) are left out of the request.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runRegister(args, opts)
+		},
+	}
+
+	addAWSFlags(cmd, &opts.AWS)
+	cmd.Flags().BoolVar(&opts.SkipValidation, "skip-validation", false, "Register the task definition even if it fails validation")
+
+	return cmd
+}
+
+func runRegister(args []string, opts *RegisterOptions) error {
+	if opts.Mode != taskdef.ModeTask {
+		return fmt.Errorf("register is only supported in task mode")
+	}
+
+	doc, err := loadDocument(args, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+	if errs := validateDocument(doc); len(errs) > 0 && !opts.SkipValidation {
+		return validationFailure("task definition is invalid (use --skip-validation to register it anyway)", errs)
+	}
+
+	client, err := opts.AWS.ecsClient()
+	if err != nil {
+		return err
+	}
+	registered, err := client.RegisterTaskDefinition(context.Background(), doc.(*taskdef.TaskDocument).TaskDefinition)
+	if err != nil {
+		return fmt.Errorf("failed to register task definition: %w", err)
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, ecs.TaskDefinitionARN(registered))
+	return err
+}
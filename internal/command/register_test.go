@@ -0,0 +1,102 @@
+package command
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// captureStdout runs fn and returns what it wrote to standard output
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	err = fn()
+	_ = w.Close()
+	return <-done, err
+}
+
+// setTestAWSEnvironment isolates a test from the AWS configuration of the machine
+func setTestAWSEnvironment(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+}
+
+func TestRegister(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	var requests []map[string]interface{}
+	server := newFakeECS(t, map[string]ecsHandler{
+		"RegisterTaskDefinition": func(body map[string]interface{}) string {
+			requests = append(requests, body)
+			return `{"taskDefinition": {"family": "my-app", "revision": 4, "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:4", "containerDefinitions": []}}`
+		},
+	})
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "task-definition.json")
+	if err := os.WriteFile(valid, []byte(`{
+  // Registered as-is
+  "family": "my-app",
+  "revision": 3,
+  "containerDefinitions": [{"name": "web", "image": "my-app:v2", "memory": 512}]
+}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"family": "my-app", "containerDefinitions": [{"name": "web"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newOpts := func() *RegisterOptions {
+		return &RegisterOptions{Mode: taskdef.ModeTask, AWS: AWSOptions{EndpointURL: server.URL}}
+	}
+
+	out, err := captureStdout(t, func() error { return runRegister([]string{valid}, newOpts()) })
+	if err != nil {
+		t.Fatalf("runRegister() error = %v", err)
+	}
+	if out != "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:4\n" {
+		t.Errorf("runRegister() output = %q", out)
+	}
+	if len(requests) != 1 || requests[0]["family"] != "my-app" || requests[0]["revision"] != nil {
+		t.Errorf("unexpected requests %v", requests)
+	}
+
+	if err := runRegister([]string{invalid}, newOpts()); err == nil || !strings.Contains(err.Error(), "use --skip-validation to register it anyway") {
+		t.Errorf("runRegister() error = %v, expected a validation failure", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("an invalid task definition was registered")
+	}
+
+	opts := newOpts()
+	opts.Mode = taskdef.ModeContainer
+	if err := runRegister([]string{valid}, opts); err == nil || !strings.Contains(err.Error(), "only supported in task mode") {
+		t.Errorf("runRegister() error = %v, expected a mode error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// RenderOptions represents options for the render command
+type RenderOptions struct {
+	Mode         taskdef.LoadMode
+	Load         taskdef.LoadOptions
+	Values       map[string]string
+	Reverse      bool
+	OutputFormat string
+	Format       output.OutputFormat
+	Overwrite    bool
+}
+
+// NewRenderCommand creates a new render command
+func NewRenderCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &RenderOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "render [file]",
+		Short: "Substitute CodeDeploy image placeholders",
+		Long: `Substitute CodeDeploy image placeholders such as <IMAGE1_NAME> with concrete images
+(--set IMAGE1_NAME=my-app:v1.2.3), or with --reverse turn concrete images back into
+placeholders (--set IMAGE1_NAME=web assigns the placeholder to container "web"; without
+--set every container is numbered in order).
+
+A CodeDeploy AppSpec file is recognised automatically: its <TASK_DEFINITION>
+placeholder is substituted with a task definition ARN (--set
+TASK_DEFINITION=arn:...), or with --reverse the TaskDefinition of each service is
+turned back into a placeholder (--set TASK_DEFINITION=TargetService). The rest of
+the file is kept as it is.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runRender(args, opts)
+		},
+	}
+
+	cmd.Flags().StringToStringVarP(&opts.Values, "set", "s", nil, "Placeholder values (PLACEHOLDER=image, or PLACEHOLDER=container with --reverse)")
+	cmd.Flags().BoolVar(&opts.Reverse, "reverse", false, "Replace images with placeholders")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "json", "Output format (json, yaml)")
+	cmd.Flags().BoolVarP(&opts.Overwrite, "overwrite", "w", false, "Overwrite input file (only with file input)")
+
+	return cmd
+}
+
+func runRender(args []string, opts *RenderOptions) error {
+	// Parse output format
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	if opts.Format != output.FormatJSON && opts.Format != output.FormatYAML {
+		return fmt.Errorf("invalid output format: %s (must be json or yaml)", opts.OutputFormat)
+	}
+	if !opts.Reverse && len(opts.Values) == 0 {
+		return fmt.Errorf("at least one --set PLACEHOLDER=image is required")
+	}
+
+	// Load input
+	data, file, err := readInput(args)
+	if err != nil {
+		return err
+	}
+	if taskdef.IsAppSpec(data) {
+		return renderAppSpec(data, file, opts)
+	}
+	if opts.Mode != taskdef.ModeTask && opts.Mode != taskdef.ModeContainer {
+		return fmt.Errorf("render is only supported in task and container modes")
+	}
+	doc, err := taskdef.LoadDocument(bytes.NewReader(data), file, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+
+	if opts.Reverse {
+		err = taskdef.ReversePlaceholders(doc.Containers(), opts.Values)
+	} else {
+		err = taskdef.RenderPlaceholders(doc.Containers(), opts.Values)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeDocument(doc, opts.Overwrite, opts.Format)
+}
+
+// renderAppSpec substitutes or restores the task definition placeholders of an AppSpec file and
+// writes it in its original format
+func renderAppSpec(data []byte, file string, opts *RenderOptions) error {
+	var err error
+	if opts.Reverse {
+		data, err = taskdef.ReverseAppSpec(data, opts.Values)
+	} else {
+		data, err = taskdef.RenderAppSpec(data, opts.Values)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !opts.Overwrite || file == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// RollbackOptions represents options for the rollback command
+type RollbackOptions struct {
+	Mode          taskdef.LoadMode
+	Load          taskdef.LoadOptions
+	FromECS       string
+	AWS           AWSOptions
+	AuditLog      string
+	ContainerName string
+	MaxRevisions  int
+	OutputFormat  string
+	Format        output.OutputFormat
+	Overwrite     bool
+	Register      bool
+}
+
+// NewRollbackCommand creates a new rollback command
+func NewRollbackCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &RollbackOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rollback [file | ecs://family[:revision]]",
+		Short: "Restore the previous image of each container",
+		Long: `Restore the image each container had before its current image.
+
+For a file, the previous images are looked up in the audit log written by
+shift --audit-log. For a task definition in ECS (ecs://family[:revision] or
+--from-ecs), earlier ACTIVE revisions of the family are searched for the most
+recent different image of each container; the other settings of the current
+revision are kept.
+
+The result is written to stdout (or back to the file with --overwrite), or
+registered as a new revision with --register.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runRollback(args, opts)
+		},
+	}
+
+	addAuditLogFlag(cmd, &opts.AuditLog, "Audit log written by shift --audit-log (required for files); the rollback is recorded in it")
+	cmd.Flags().StringVarP(&opts.ContainerName, "container", "c", "", "Only roll back this container")
+	cmd.Flags().IntVar(&opts.MaxRevisions, "max-revisions", 50, "Maximum number of earlier revisions to search in ECS")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "json", "Output format (json, yaml)")
+	cmd.Flags().BoolVarP(&opts.Overwrite, "overwrite", "w", false, "Overwrite input file (only with file input)")
+	cmd.Flags().BoolVar(&opts.Register, "register", false, "Register the result as a new revision and print its ARN (task mode)")
+	cmd.Flags().StringVar(&opts.FromECS, "from-ecs", "", "Roll back a task definition in ECS (family, family:revision or ARN)")
+	addAWSFlags(cmd, &opts.AWS)
+
+	return cmd
+}
+
+// previousImage is the image a container is rolled back to
+type previousImage struct {
+	image string
+	// origin describes where the image was found, e.g. "revision 7"
+	origin string
+}
+
+func runRollback(args []string, opts *RollbackOptions) error {
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	if opts.Format != output.FormatJSON && opts.Format != output.FormatYAML {
+		return fmt.Errorf("invalid output format: %s (must be json or yaml)", opts.OutputFormat)
+	}
+	if opts.Mode != taskdef.ModeTask && opts.Mode != taskdef.ModeContainer {
+		return fmt.Errorf("rollback is only supported in task and container modes")
+	}
+	if opts.Register && opts.Mode != taskdef.ModeTask {
+		return fmt.Errorf("--register is only supported in task mode")
+	}
+	remote := opts.FromECS != "" || (len(args) > 0 && strings.HasPrefix(args[0], ecsScheme))
+	auditLog := auditLogPath(opts.AuditLog)
+	if !remote && auditLog == "" {
+		return fmt.Errorf("--audit-log (or %s) is required to roll back a file", history.EnvAuditLog)
+	}
+
+	doc, input, err := loadSourceData(args, opts.FromECS, &opts.AWS, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+	if !remote && doc.Source() == "" {
+		return fmt.Errorf("rollback needs a file or an ECS task definition; the history of stdin is unknown")
+	}
+
+	var previous map[string]previousImage
+	if remote {
+		previous, err = previousImagesFromECS(doc.(*taskdef.TaskDocument).TaskDefinition, opts)
+	} else {
+		previous, err = previousImagesFromHistory(doc, auditLog)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Restore the previous images
+	var changes []history.Change
+	containers := doc.Containers()
+	for i := range containers {
+		c := &containers[i]
+		if opts.ContainerName != "" && c.Name != opts.ContainerName {
+			continue
+		}
+		p, ok := previous[c.Name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s -> %s (%s)\n", c.Name, c.Image, p.image, p.origin)
+		changes = append(changes, history.Change{Container: c.Name, OldImage: c.Image, NewImage: p.image})
+		c.Image = p.image
+	}
+	if len(changes) == 0 {
+		if opts.ContainerName != "" {
+			return fmt.Errorf("no previous image found for container '%s'", opts.ContainerName)
+		}
+		return fmt.Errorf("no previous image found for any container")
+	}
+	if errs := validateDocument(doc); len(errs) > 0 {
+		return validationFailure("rolled back definition is invalid", errs)
+	}
+
+	if opts.Register {
+		client, err := opts.AWS.ecsClient()
+		if err != nil {
+			return err
+		}
+		registered, err := client.RegisterTaskDefinition(context.Background(), doc.(*taskdef.TaskDocument).TaskDefinition)
+		if err != nil {
+			return fmt.Errorf("failed to register task definition: %w", err)
+		}
+		arn := ecs.TaskDefinitionARN(registered)
+		if _, err := fmt.Fprintln(os.Stdout, arn); err != nil {
+			return err
+		}
+		return recordAudit(auditLog, auditRecord{
+			command: history.CommandRollback,
+			source:  auditSource(doc, args, opts.FromECS),
+			file:    arn,
+			input:   input,
+			output:  []byte(arn + "\n"),
+			changes: changes,
+		})
+	}
+
+	written, err := writeDocumentData(doc, opts.Overwrite, opts.Format)
+	if err != nil {
+		return err
+	}
+	return recordAudit(auditLog, auditRecord{
+		command: history.CommandRollback,
+		source:  auditSource(doc, args, opts.FromECS),
+		file:    writtenFile(doc, opts.Overwrite),
+		input:   input,
+		output:  written,
+		changes: changes,
+	})
+}
+
+// previousImagesFromHistory looks up in an audit log the image each container had before it was
+// shifted to its current image
+func previousImagesFromHistory(doc taskdef.Document, path string) (map[string]previousImage, error) {
+	entries, err := history.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	file := history.AbsPath(doc.Source())
+	previous := make(map[string]previousImage)
+	for _, c := range doc.Containers() {
+		if image, ok := history.PreviousImage(entries, file, c.Name, c.Image); ok {
+			previous[c.Name] = previousImage{image: image, origin: "audit log"}
+		}
+	}
+	return previous, nil
+}
+
+// previousImagesFromECS searches the earlier ACTIVE revisions of a task definition, newest first,
+// for the most recent image of each container that differs from its current image
+func previousImagesFromECS(current *taskdef.TaskDefinition, opts *RollbackOptions) (map[string]previousImage, error) {
+	client, err := opts.AWS.ecsClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	arns, err := client.ListTaskDefinitions(ctx, current.Family, "ACTIVE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions of %s: %w", current.Family, err)
+	}
+
+	previous := make(map[string]previousImage)
+	searched := 0
+	for _, arn := range arns {
+		if len(previous) == len(current.ContainerDefinitions) || searched >= opts.MaxRevisions {
+			break
+		}
+		if _, revision, ok := ecs.ParseTaskDefinitionARN(arn); !ok || revision >= current.Revision {
+			continue
+		}
+		searched++
+
+		data, err := client.DescribeTaskDefinition(ctx, arn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe task definition %s: %w", arn, err)
+		}
+		old, err := taskdef.LoadTaskDefinition(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load task definition %s: %w", arn, err)
+		}
+		for _, c := range current.ContainerDefinitions {
+			if _, found := previous[c.Name]; found {
+				continue
+			}
+			for _, o := range old.ContainerDefinitions {
+				if o.Name == c.Name && o.Image != c.Image {
+					previous[c.Name] = previousImage{image: o.Image, origin: fmt.Sprintf("revision %d", old.Revision)}
+				}
+			}
+		}
+	}
+	return previous, nil
+}
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestRollbackFromHistory(t *testing.T) {
+	dir := t.TempDir()
+	tmpFile := filepath.Join(dir, "task-definition.json")
+	auditLog := filepath.Join(dir, "audit.jsonl")
+	if err := os.WriteFile(tmpFile, []byte(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "my-app:v1", "memory": 512}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tag := range []string{"v2", "v3"} {
+		opts := &ShiftOptions{Mode: taskdef.ModeTask, Tag: tag, OutputFormat: "json", Overwrite: true, AuditLog: auditLog}
+		if err := runShift([]string{tmpFile}, opts); err != nil {
+			t.Fatalf("runShift() error = %v", err)
+		}
+	}
+
+	// Each rollback goes back one more shift
+	for _, expected := range []string{"my-app:v2", "my-app:v1"} {
+		opts := &RollbackOptions{Mode: taskdef.ModeTask, OutputFormat: "json", Overwrite: true, AuditLog: auditLog}
+		if err := runRollback([]string{tmpFile}, opts); err != nil {
+			t.Fatalf("runRollback() error = %v", err)
+		}
+		data, err := taskdef.LoadFromFile(tmpFile, taskdef.ModeTask)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if image := data.(*taskdef.TaskDefinition).ContainerDefinitions[0].Image; image != expected {
+			t.Errorf("image after rollback = %s, expected %s", image, expected)
+		}
+	}
+
+	opts := &RollbackOptions{Mode: taskdef.ModeTask, OutputFormat: "json", AuditLog: auditLog}
+	if err := runRollback([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), "no previous image found") {
+		t.Errorf("runRollback() at the oldest image error = %v", err)
+	}
+}
+
+func TestRollbackFromECS(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	revisions := map[string]string{
+		"my-app": `{"family": "my-app", "revision": 9, "containerDefinitions": [{"name": "web", "image": "my-app:v3", "memory": 512}, {"name": "log", "image": "fluent-bit:2"}]}`,
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8": `{"family": "my-app", "revision": 8, "containerDefinitions": [{"name": "web", "image": "my-app:v3", "memory": 256}, {"name": "log", "image": "fluent-bit:1"}]}`,
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7": `{"family": "my-app", "revision": 7, "containerDefinitions": [{"name": "web", "image": "my-app:v2", "memory": 256}, {"name": "log", "image": "fluent-bit:0"}]}`,
+	}
+	var described []string
+	server := newFakeECS(t, map[string]ecsHandler{
+		"ListTaskDefinitions": func(map[string]interface{}) string {
+			return `{"taskDefinitionArns": [
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:9",
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8",
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7"
+]}`
+		},
+		"DescribeTaskDefinition": func(body map[string]interface{}) string {
+			name, _ := body["taskDefinition"].(string)
+			described = append(described, name)
+			return `{"taskDefinition": ` + revisions[name] + `}`
+		},
+	})
+
+	opts := &RollbackOptions{Mode: taskdef.ModeTask, OutputFormat: "json", MaxRevisions: 50, AWS: AWSOptions{EndpointURL: server.URL}}
+	out, err := captureStdout(t, func() error { return runRollback([]string{"ecs://my-app"}, opts) })
+	if err != nil {
+		t.Fatalf("runRollback() error = %v", err)
+	}
+	td, err := taskdef.LoadTaskDefinition(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, out)
+	}
+	// The images come from the earlier revisions, the other settings from the current one
+	web, log := td.ContainerDefinitions[0], td.ContainerDefinitions[1]
+	if web.Image != "my-app:v2" || web.Memory != 512 || log.Image != "fluent-bit:1" {
+		t.Errorf("runRollback() output = %s", out)
+	}
+	if len(described) != 3 {
+		t.Errorf("described %v, expected the current and two earlier revisions", described)
+	}
+}
+
+func TestRollbackRegisterAudit(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	const arn = "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:10"
+	revisions := map[string]string{
+		"my-app": `{"family": "my-app", "revision": 9, "containerDefinitions": [{"name": "web", "image": "my-app:v3", "memory": 512}]}`,
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8": `{"family": "my-app", "revision": 8, "containerDefinitions": [{"name": "web", "image": "my-app:v2", "memory": 512}]}`,
+	}
+	server := newFakeECS(t, map[string]ecsHandler{
+		"ListTaskDefinitions": func(map[string]interface{}) string {
+			return `{"taskDefinitionArns": [
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:9",
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8"
+]}`
+		},
+		"DescribeTaskDefinition": func(body map[string]interface{}) string {
+			name, _ := body["taskDefinition"].(string)
+			return `{"taskDefinition": ` + revisions[name] + `}`
+		},
+		"RegisterTaskDefinition": func(map[string]interface{}) string {
+			return `{"taskDefinition": {"family": "my-app", "revision": 10, "taskDefinitionArn": "` + arn + `", "containerDefinitions": []}}`
+		},
+	})
+
+	auditLog := filepath.Join(t.TempDir(), "audit.jsonl")
+	opts := &RollbackOptions{Mode: taskdef.ModeTask, OutputFormat: "json", MaxRevisions: 50, Register: true, AuditLog: auditLog, AWS: AWSOptions{EndpointURL: server.URL}}
+	out, err := captureStdout(t, func() error { return runRollback([]string{"ecs://my-app"}, opts) })
+	if err != nil {
+		t.Fatalf("runRollback() error = %v", err)
+	}
+	if out != arn+"\n" {
+		t.Errorf("runRollback() output = %q, expected the registered ARN", out)
+	}
+
+	entries, err := history.Load(auditLog)
+	if err != nil {
+		t.Fatalf("history.Load() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("audit log has %d entries, expected 1", len(entries))
+	}
+	e := entries[0]
+	if e.Command != history.CommandRollback || e.Source != "ecs://my-app" || e.File != arn || e.OutputHash != history.Digest([]byte(out)) {
+		t.Errorf("unexpected audit entry %+v", e)
+	}
+	if len(e.Changes) != 1 || e.Changes[0].Container != "web" || e.Changes[0].OldImage != "my-app:v3" || e.Changes[0].NewImage != "my-app:v2" {
+		t.Errorf("unexpected audit changes %+v", e.Changes)
+	}
+}
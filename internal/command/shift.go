@@ -1,57 +1,94 @@
 package command
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
 	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/registry"
 	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
 	"github.com/spf13/cobra"
 )
 
 // ShiftOptions represents options for the shift command
 type ShiftOptions struct {
-	Mode          taskdef.LoadMode
-	Tag           string
-	ContainerName string
-	ImageName     string
-	OutputFormat  string
-	Format        output.OutputFormat
-	Overwrite     bool
+	Mode           taskdef.LoadMode
+	Load           taskdef.LoadOptions
+	FromECS        string
+	AWS            AWSOptions
+	Tag            string
+	LatestMatching string
+	ContainerName  string
+	ImageName      string
+	Resource       string
+	OutputFormat   string
+	Format         output.OutputFormat
+	Overwrite      bool
+	SkipValidation bool
+	ResolveDigest  bool
+	Verify         bool
+	// AuditLog is the path of the audit log to record the changes in; if empty,
+	// $ECS_TAG_SHIFT_AUDIT_LOG is used, and nothing is recorded if that is unset too
+	AuditLog string
+	// Registry is used by --latest-matching, --resolve-digest and --verify; if nil, a client using the default credentials is created
+	Registry *registry.Client
 }
 
 // NewShiftCommand creates a new shift command
-func NewShiftCommand(globalMode *taskdef.LoadMode) *cobra.Command {
+func NewShiftCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
 	opts := &ShiftOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "shift [file]",
+		Use:   "shift [file | ecs://family[:revision]]",
 		Short: "Update container image tags",
-		Long:  `Update the image tags for containers in a task definition or container definitions file.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Update the image tags for containers in a task definition or container definitions file.
+
+In task mode, the task definition can also be read from ECS with
+ecs://family[:revision] or --from-ecs; the result is written to stdout.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Mode = *globalMode
+			opts.Load = *globalLoad
 			return runShift(args, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.Tag, "tag", "t", "", "New image tag (required)")
-	cmd.Flags().StringVarP(&opts.ContainerName, "container", "c", "", "Filter by container name")
+	cmd.Flags().StringVarP(&opts.Tag, "tag", "t", "", "New image tag (required unless --latest-matching is set)")
+	cmd.Flags().StringVar(&opts.LatestMatching, "latest-matching", "", "Use the latest registry tag matching this regular expression instead of --tag (highest semantic version, else most recently created image)")
+	cmd.Flags().StringVarP(&opts.ContainerName, "container", "c", "", "Filter by container name (service name in compose mode)")
 	cmd.Flags().StringVarP(&opts.ImageName, "image", "i", "", "Filter by image repository name")
-	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "json", "Output format (json, yaml)")
+	cmd.Flags().StringVarP(&opts.Resource, "resource", "r", "", "Filter by resource (CloudFormation logical ID, Terraform resource address, or Kubernetes kind/name or namespace/kind/name)")
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "json", "Output format (json, yaml; only used in task and container modes)")
 	cmd.Flags().BoolVarP(&opts.Overwrite, "overwrite", "w", false, "Overwrite input file (only with file input)")
-
-	if err := cmd.MarkFlagRequired("tag"); err != nil {
-		panic(err)
-	}
+	cmd.Flags().BoolVar(&opts.SkipValidation, "skip-validation", false, "Write the result even if it fails validation")
+	cmd.Flags().BoolVar(&opts.ResolveDigest, "resolve-digest", false, "Pin updated images to the digest of the new tag")
+	cmd.Flags().BoolVar(&opts.Verify, "verify", false, "Check that every resulting image exists in its registry before writing (images with ${...} are skipped)")
+	addAuditLogFlag(cmd, &opts.AuditLog, "Append the image changes to this audit log (JSON Lines), for history and rollback")
+	cmd.Flags().StringVar(&opts.FromECS, "from-ecs", "", "Read the task definition from ECS (family, family:revision or ARN)")
+	addAWSFlags(cmd, &opts.AWS)
+	cmd.MarkFlagsMutuallyExclusive("tag", "latest-matching")
 
 	return cmd
 }
 
 func runShift(args []string, opts *ShiftOptions) error {
 	// Validate tag
-	if opts.Tag == "" {
-		return fmt.Errorf("tag is required")
+	if opts.Tag == "" && opts.LatestMatching == "" {
+		return fmt.Errorf("tag is required (set --tag or --latest-matching)")
+	}
+	if opts.Tag != "" && opts.LatestMatching != "" {
+		return fmt.Errorf("--tag and --latest-matching cannot be used together")
+	}
+	var pattern *regexp.Regexp
+	if opts.LatestMatching != "" {
+		var err error
+		if pattern, err = regexp.Compile(opts.LatestMatching); err != nil {
+			return fmt.Errorf("invalid --latest-matching pattern: %w", err)
+		}
 	}
 
 	// Parse output format
@@ -60,85 +97,150 @@ func runShift(args []string, opts *ShiftOptions) error {
 		return fmt.Errorf("invalid output format: %s (must be json or yaml)", opts.OutputFormat)
 	}
 
-	// Validate overwrite option
-	if opts.Overwrite && len(args) == 0 {
-		// Overwrite without file input - just ignore and output to stdout
-		opts.Overwrite = false
-	}
-
 	// Load input
-	var data interface{}
-	var err error
-	var inputFile string
-
-	if len(args) > 0 {
-		// Load from file
-		inputFile = args[0]
-		data, err = taskdef.LoadFromFile(inputFile, opts.Mode)
-	} else {
-		// Load from stdin
-		data, err = taskdef.Load(os.Stdin, opts.Mode)
-	}
-
+	doc, input, err := loadSourceData(args, opts.FromECS, &opts.AWS, opts.Mode, opts.Load)
 	if err != nil {
 		return err
 	}
 
-	// Create update options
+	// Update and validate
 	updateOpts := taskdef.UpdateOptions{
 		Tag:           opts.Tag,
 		ContainerName: opts.ContainerName,
 		ImageName:     opts.ImageName,
+		Resource:      opts.Resource,
 	}
-
-	// Update and output
-	switch opts.Mode {
-	case taskdef.ModeTask:
-		taskDef := data.(*taskdef.TaskDefinition)
-		if err := taskdef.UpdateTaskDefinition(taskDef, updateOpts); err != nil {
+	var client *registry.Client
+	if pattern != nil || opts.ResolveDigest || opts.Verify {
+		if client, err = registryClient(opts.Registry, opts.AWS.Profile); err != nil {
 			return err
 		}
+	}
+	if pattern != nil {
+		updateOpts.TagFunc = latestTagFunc(client, pattern)
+	}
+	if opts.ResolveDigest {
+		updateOpts.DigestFunc = digestFunc(client)
+	}
+	result, err := doc.Update(updateOpts)
+	if err != nil {
+		return err
+	}
+	if errs := validateDocument(doc); len(errs) > 0 && !opts.SkipValidation {
+		return validationFailure("updated definition is invalid (use --skip-validation to write it anyway)", errs)
+	}
 
-		// Determine output destination
-		if opts.Overwrite && inputFile != "" {
-			return writeToFile(inputFile, taskDef, opts.Format, false)
+	if opts.Verify {
+		if problems := checkImages(client, doc, nil); len(problems) > 0 {
+			return fmt.Errorf("image verification failed for %d image(s); nothing was written:\n  %s", len(problems), strings.Join(problems, "\n  "))
 		}
-		return output.FormatTaskDefinitionFull(os.Stdout, taskDef, opts.Format)
+	}
 
-	case taskdef.ModeContainer:
-		containers := data.([]taskdef.ContainerDefinition)
-		updatedContainers, err := taskdef.UpdateContainerDefinitions(containers, updateOpts)
-		if err != nil {
-			return err
-		}
+	// Embedded documents are written back in their original format
+	written, err := writeDocumentData(doc, opts.Overwrite, opts.Format)
+	if err != nil {
+		return err
+	}
+	changes := historyChanges(result)
+	return recordAudit(opts.AuditLog, auditRecord{
+		command: history.CommandShift,
+		source:  auditSource(doc, args, opts.FromECS),
+		file:    writtenFile(doc, opts.Overwrite),
+		tag:     commonTag(changes),
+		input:   input,
+		output:  written,
+		changes: changes,
+	})
+}
 
-		// Determine output destination
-		if opts.Overwrite && inputFile != "" {
-			return writeToFile(inputFile, updatedContainers, opts.Format, true)
-		}
-		return output.FormatContainerDefinitionsFull(os.Stdout, updatedContainers, opts.Format)
+// historyChanges returns the image changes of an update
+func historyChanges(result *taskdef.UpdateResult) []history.Change {
+	var changes []history.Change
+	for _, m := range result.Updated() {
+		changes = append(changes, history.Change{
+			Resource:  m.Resource,
+			Container: m.Name,
+			OldImage:  m.OldImage,
+			NewImage:  m.NewImage,
+			Tag:       taskdef.ParseImageReference(m.NewImage).Tag,
+		})
+	}
+	return changes
+}
 
-	default:
-		return fmt.Errorf("invalid mode: %s", opts.Mode)
+// commonTag returns the tag shared by every change, or "" if they differ
+func commonTag(changes []history.Change) string {
+	if len(changes) == 0 {
+		return ""
 	}
+	for _, c := range changes[1:] {
+		if c.Tag != changes[0].Tag {
+			return ""
+		}
+	}
+	return changes[0].Tag
 }
 
-// writeToFile writes the result to a file
-func writeToFile(filename string, data interface{}, format output.OutputFormat, isContainers bool) error {
-	file, err := os.Create(filename)
+// registryClient returns client if it is set, or a client using the default credentials, with ECR
+// tokens requested using the AWS profile
+func registryClient(client *registry.Client, profile string) (*registry.Client, error) {
+	if client != nil {
+		return client, nil
+	}
+	store, err := registry.DefaultCredentials(profile)
 	if err != nil {
-		return fmt.Errorf("failed to open file for writing: %w", err)
+		return nil, err
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to close file: %v\n", err)
+	return registry.NewClient(store), nil
+}
+
+// latestTagFunc returns a function picking the latest tag matching pattern (see registry.LatestTag)
+// from the repository of each container's image. Tags are looked up once per repository.
+func latestTagFunc(client *registry.Client, pattern *regexp.Regexp) func(taskdef.ContainerDefinition) (string, error) {
+	latest := make(map[string]string)
+	return func(c taskdef.ContainerDefinition) (string, error) {
+		ref := taskdef.ParseImageReference(c.Image)
+		if strings.Contains(ref.Repository, "${") {
+			return "", fmt.Errorf("cannot look up tags for container '%s': repository of image '%s' is not a literal", c.Name, c.Image)
+		}
+		if tag, ok := latest[ref.Repository]; ok {
+			return tag, nil
 		}
-	}()
+		tags, err := client.Tags(context.Background(), ref)
+		if err != nil {
+			return "", err
+		}
+		created := func(tag string) (time.Time, error) {
+			return client.Created(context.Background(), taskdef.ImageReference{Repository: ref.Repository, Tag: tag})
+		}
+		tag, ok, err := registry.LatestTag(tags, pattern, created)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("no tag of %s matches %s", ref.Repository, pattern)
+		}
+		latest[ref.Repository] = tag
+		return tag, nil
+	}
+}
 
-	if isContainers {
-		containers := data.([]taskdef.ContainerDefinition)
-		return output.FormatContainerDefinitionsFull(file, containers, format)
+// digestFunc returns a function resolving the digest of an image, which is looked up once per image
+func digestFunc(client *registry.Client) func(image string) (string, error) {
+	digests := make(map[string]string)
+	return func(image string) (string, error) {
+		ref := taskdef.ParseImageReference(image)
+		if strings.Contains(ref.Repository, "${") {
+			return "", fmt.Errorf("cannot resolve the digest of image '%s': repository is not a literal", image)
+		}
+		if digest, ok := digests[image]; ok {
+			return digest, nil
+		}
+		digest, err := client.Digest(context.Background(), ref)
+		if err != nil {
+			return "", err
+		}
+		digests[image] = digest
+		return digest, nil
 	}
-	taskDef := data.(*taskdef.TaskDefinition)
-	return output.FormatTaskDefinitionFull(file, taskDef, format)
 }
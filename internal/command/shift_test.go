@@ -2,11 +2,15 @@ package command
 
 import (
 	"bytes"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/registry"
 	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
 )
 
@@ -188,3 +192,430 @@ func TestOverwriteWithContainerMode(t *testing.T) {
 		t.Errorf("File should contain new tag v1.0.0")
 	}
 }
+
+func TestShiftKeepsUnknownNestedFields(t *testing.T) {
+	input := `{
+  "family": "my-app",
+  "containerDefinitions": [{
+    "name": "web",
+    "image": "my-app:v1",
+    "portMappings": [{"containerPort": 80, "futurePortField": "a"}],
+    "logConfiguration": {"logDriver": "awslogs", "futureLogField": {"mode": "b"}},
+    "healthCheck": {"command": ["CMD", "true"], "futureHealthField": 3},
+    "linuxParameters": {"capabilities": {"add": ["SYS_PTRACE"], "futureCapField": true}},
+    "repositoryCredentials": {"credentialsParameter": "arn:aws:secretsmanager:us-east-1:123456789012:secret:registry", "futureCredField": "c"}
+  }],
+  "volumes": [{"name": "data", "efsVolumeConfiguration": {"fileSystemId": "fs-1", "futureEFSField": "d"}}],
+  "runtimePlatform": {"cpuArchitecture": "ARM64", "futurePlatformField": "e"}
+}`
+	unknown := []string{"futurePortField", "futureLogField", "futureHealthField", "futureCapField", "futureCredField", "futureEFSField", "futurePlatformField"}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "task-definition.json")
+			if err := os.WriteFile(tmpFile, []byte(input), 0644); err != nil {
+				t.Fatal(err)
+			}
+			opts := &ShiftOptions{Mode: taskdef.ModeTask, Tag: "v2", OutputFormat: format, Overwrite: true}
+			if err := runShift([]string{tmpFile}, opts); err != nil {
+				t.Fatalf("runShift() error = %v", err)
+			}
+
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), "my-app:v2") {
+				t.Errorf("image was not shifted:\n%s", content)
+			}
+			for _, key := range unknown {
+				if !strings.Contains(string(content), key) {
+					t.Errorf("unknown field %s was dropped:\n%s", key, content)
+				}
+			}
+		})
+	}
+}
+
+func TestShiftResolveDigest(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodHead || r.URL.Path != "/v2/my-app/manifests/v2.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tmpFile := filepath.Join(t.TempDir(), "task-def.json")
+	content := `{
+  "family": "my-app",
+  "containerDefinitions": [
+    {"name": "web", "image": "` + host + `/my-app:v1.0.0@sha256:old"},
+    {"name": "worker", "image": "` + host + `/my-app:v1.0.0"},
+    {"name": "nginx", "image": "nginx:latest"}
+  ]
+}`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+	opts := &ShiftOptions{
+		Mode:          taskdef.ModeTask,
+		Tag:           "v2.0.0",
+		ImageName:     "my-app",
+		OutputFormat:  "json",
+		Overwrite:     true,
+		ResolveDigest: true,
+		Registry:      client,
+	}
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+
+	data, err := taskdef.LoadFromFile(tmpFile, taskdef.ModeTask)
+	if err != nil {
+		t.Fatalf("Failed to load file: %v", err)
+	}
+	containers := data.(*taskdef.TaskDefinition).ContainerDefinitions
+	expected := host + "/my-app:v2.0.0@" + digest
+	if containers[0].Image != expected || containers[1].Image != expected {
+		t.Errorf("images = %q, %q, expected %q", containers[0].Image, containers[1].Image, expected)
+	}
+	if containers[2].Image != "nginx:latest" {
+		t.Errorf("unfiltered image changed to %q", containers[2].Image)
+	}
+	if requests != 1 {
+		t.Errorf("registry received %d requests, expected 1", requests)
+	}
+
+	opts.Tag = "v3.0.0"
+	if err := runShift([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("runShift() error = %v, expected unknown tag error", err)
+	}
+}
+
+func TestShiftVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/my-app/manifests/v2.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tmpFile := filepath.Join(t.TempDir(), "containers.json")
+	content := `[
+  {"name": "web", "image": "` + host + `/my-app:v1.0.0"},
+  {"name": "sidecar", "image": "` + host + `/sidecar:v1.0.0"},
+  {"name": "proxy", "image": "` + host + `/proxy:v1.0.0"},
+  {"name": "app", "image": "<IMAGE1_NAME>"}
+]`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+	opts := &ShiftOptions{
+		Mode:         taskdef.ModeContainer,
+		Tag:          "v2.0.0",
+		OutputFormat: "json",
+		Overwrite:    true,
+		Verify:       true,
+		Registry:     client,
+	}
+
+	// Only my-app has the tag; every other missing image is listed and the file is left as-is
+	err := runShift([]string{tmpFile}, opts)
+	if err == nil {
+		t.Fatal("runShift() expected an error for missing images")
+	}
+	for _, want := range []string{"2 image(s)", host + "/sidecar:v2.0.0 (container 'sidecar'): not found", host + "/proxy:v2.0.0 (container 'proxy'): not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("runShift() error does not contain %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "my-app") || strings.Contains(err.Error(), "IMAGE1_NAME") {
+		t.Errorf("runShift() error lists an image that exists or is a placeholder:\n%v", err)
+	}
+	written, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(written) != content {
+		t.Errorf("file was written despite the verification failure")
+	}
+
+	opts.ContainerName = "web"
+	if err := runShift([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), host+"/sidecar:v1.0.0 (container 'sidecar'): not found") {
+		t.Errorf("runShift() error = %v, expected the unchanged sidecar and proxy images to be reported", err)
+	}
+}
+
+func TestShiftVerifyEmbedded(t *testing.T) {
+	var checked []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checked = append(checked, r.URL.Path)
+		if r.URL.Path != "/v2/my-app/manifests/v2.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tmpFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	content := `services:
+  web:
+    image: ` + host + `/my-app:v1.0.0
+  worker:
+    image: ${REGISTRY}/worker:v1.0.0
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+	opts := &ShiftOptions{Mode: taskdef.ModeCompose, Tag: "v2.0.0", OutputFormat: "json", Overwrite: true, Verify: true, Registry: client}
+
+	// The literal image is checked and the templated one is skipped
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+	if len(checked) != 1 || checked[0] != "/v2/my-app/manifests/v2.0.0" {
+		t.Errorf("checked %v, expected only my-app:v2.0.0", checked)
+	}
+	written, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.Contains(string(written), host+"/my-app:v2.0.0") || !strings.Contains(string(written), "${REGISTRY}/worker:v2.0.0") {
+		t.Errorf("unexpected result:\n%s", written)
+	}
+
+	opts.Tag = "v3.0.0"
+	if err := runShift([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), host+"/my-app:v3.0.0 (container 'web'): not found") {
+		t.Errorf("runShift() error = %v, expected the missing literal image to be reported", err)
+	}
+}
+
+func TestShiftResolveDigestEmbedded(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/my-app/manifests/v2.0.0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tmpFile := filepath.Join(t.TempDir(), "deployment.yaml")
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: ` + host + `/my-app:v1.0.0
+        - name: proxy
+          image: "envoy:v1"
+`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+	opts := &ShiftOptions{Mode: taskdef.ModeK8s, Tag: "v2.0.0", ContainerName: "web", OutputFormat: "json", Overwrite: true, ResolveDigest: true, Registry: client}
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+	written, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	expected := strings.Replace(content, "/my-app:v1.0.0", "/my-app:v2.0.0@"+digest, 1)
+	if string(written) != expected {
+		t.Errorf("unexpected result:\n%s\nexpected:\n%s", written, expected)
+	}
+
+	// The digest of a templated repository cannot be resolved
+	composeFile := filepath.Join(t.TempDir(), "docker-compose.yml")
+	if err := os.WriteFile(composeFile, []byte("services:\n  web:\n    image: ${REGISTRY}/my-app:v1.0.0\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	opts = &ShiftOptions{Mode: taskdef.ModeCompose, Tag: "v2.0.0", OutputFormat: "json", Overwrite: true, ResolveDigest: true, Registry: client}
+	if err := runShift([]string{composeFile}, opts); err == nil || !strings.Contains(err.Error(), "repository is not a literal") {
+		t.Errorf("runShift() error = %v, expected the templated repository to be reported", err)
+	}
+}
+
+func TestShiftLatestMatching(t *testing.T) {
+	tags := map[string]string{
+		"/v2/my-app/tags/list":  `{"tags": ["v1.0.0", "v1.10.0", "v1.9.0", "v2.0.0-rc.1", "latest"]}`,
+		"/v2/sidecar/tags/list": `{"tags": ["v0.1.0", "v0.2.0"]}`,
+		"/v2/proxy/tags/list":   `{"tags": ["stable", "main-9f2c1e", "main-0c77d2"]}`,
+		// main-0c77d2 was built after main-9f2c1e although it sorts first
+		"/v2/proxy/manifests/main-9f2c1e": `{"config": {"digest": "sha256:old"}}`,
+		"/v2/proxy/manifests/main-0c77d2": `{"config": {"digest": "sha256:new"}}`,
+		"/v2/proxy/blobs/sha256:old":      `{"created": "2024-01-09T10:00:00Z"}`,
+		"/v2/proxy/blobs/sha256:new":      `{"created": "2024-01-10T10:00:00Z"}`,
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := tags[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tmpFile := filepath.Join(t.TempDir(), "containers.json")
+	content := `[
+  {"name": "web", "image": "` + host + `/my-app:v1.0.0"},
+  {"name": "worker", "image": "` + host + `/my-app:v1.0.0"},
+  {"name": "sidecar", "image": "` + host + `/sidecar:v0.1.0"},
+  {"name": "proxy", "image": "` + host + `/proxy:stable"}
+]`
+	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+	newOpts := func() *ShiftOptions {
+		return &ShiftOptions{
+			Mode:           taskdef.ModeContainer,
+			LatestMatching: `^v\d+\.\d+\.\d+$`,
+			OutputFormat:   "json",
+			Overwrite:      true,
+			Registry:       client,
+		}
+	}
+
+	// proxy has no matching tag
+	if err := runShift([]string{tmpFile}, newOpts()); err == nil || !strings.Contains(err.Error(), "no tag of "+host+"/proxy matches") {
+		t.Errorf("runShift() error = %v, expected no matching tag for proxy", err)
+	}
+
+	opts := newOpts()
+	opts.ContainerName = "proxy"
+	opts.LatestMatching = "["
+	if err := runShift([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), "invalid --latest-matching pattern") {
+		t.Errorf("runShift() error = %v, expected an invalid pattern error", err)
+	}
+
+	opts = newOpts()
+	opts.Tag = "v1.0.0"
+	if err := runShift([]string{tmpFile}, opts); err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("runShift() error = %v, expected --tag and --latest-matching to conflict", err)
+	}
+
+	opts = newOpts()
+	opts.ImageName = "my-app"
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+	opts = newOpts()
+	opts.ContainerName = "sidecar"
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+	opts = newOpts()
+	opts.ContainerName = "proxy"
+	opts.LatestMatching = `^main-`
+	if err := runShift([]string{tmpFile}, opts); err != nil {
+		t.Fatalf("runShift() error = %v", err)
+	}
+
+	doc, err := taskdef.LoadDocumentFromFile(tmpFile, taskdef.ModeContainer, taskdef.LoadOptions{})
+	if err != nil {
+		t.Fatalf("Failed to load result: %v", err)
+	}
+	expected := map[string]string{
+		"web":     host + "/my-app:v1.10.0",
+		"worker":  host + "/my-app:v1.10.0",
+		"sidecar": host + "/sidecar:v0.2.0",
+		"proxy":   host + "/proxy:main-0c77d2",
+	}
+	for _, c := range doc.Containers() {
+		if c.Image != expected[c.Name] {
+			t.Errorf("container %s image = %s, expected %s", c.Name, c.Image, expected[c.Name])
+		}
+	}
+}
+
+func TestShiftFromECS(t *testing.T) {
+	setTestAWSEnvironment(t)
+
+	var described []string
+	server := newFakeECS(t, map[string]ecsHandler{
+		"DescribeTaskDefinition": func(body map[string]interface{}) string {
+			name, _ := body["taskDefinition"].(string)
+			described = append(described, name)
+			return `{
+  "taskDefinition": {
+    "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7",
+    "family": "my-app",
+    "revision": 7,
+    "containerDefinitions": [{"name": "web", "image": "my-app:v1", "memory": 512}]
+  },
+  "tags": [{"key": "team", "value": "web"}]
+}`
+		},
+	})
+
+	for _, tt := range []struct {
+		args    []string
+		fromECS string
+	}{
+		{args: []string{"ecs://my-app:7"}},
+		{fromECS: "my-app"},
+	} {
+		opts := &ShiftOptions{
+			Mode:         taskdef.ModeTask,
+			Tag:          "v2",
+			OutputFormat: "json",
+			Overwrite:    true,
+			FromECS:      tt.fromECS,
+			AWS:          AWSOptions{EndpointURL: server.URL},
+		}
+		out, err := captureStdout(t, func() error { return runShift(tt.args, opts) })
+		if err != nil {
+			t.Fatalf("runShift() error = %v", err)
+		}
+		td, err := taskdef.LoadTaskDefinition(strings.NewReader(out))
+		if err != nil {
+			t.Fatalf("output is not a task definition: %v\n%s", err, out)
+		}
+		if td.ContainerDefinitions[0].Image != "my-app:v2" || len(td.Tags) != 1 {
+			t.Errorf("runShift() output = %s", out)
+		}
+	}
+	if strings.Join(described, ",") != "my-app:7,my-app" {
+		t.Errorf("described %v", described)
+	}
+
+	opts := &ShiftOptions{Mode: taskdef.ModeTask, Tag: "v2", OutputFormat: "json", FromECS: "my-app"}
+	if err := runShift([]string{"task-definition.json"}, opts); err == nil || !strings.Contains(err.Error(), "cannot be used with a file argument") {
+		t.Errorf("runShift() error = %v, expected --from-ecs to conflict with a file", err)
+	}
+	opts = &ShiftOptions{Mode: taskdef.ModeContainer, Tag: "v2", OutputFormat: "json"}
+	if err := runShift([]string{"ecs://my-app"}, opts); err == nil || !strings.Contains(err.Error(), "only supported in task mode") {
+		t.Errorf("runShift() error = %v, expected a mode error", err)
+	}
+}
@@ -12,28 +12,37 @@ import (
 // ShowOptions represents options for the show command
 type ShowOptions struct {
 	Mode       taskdef.LoadMode
+	Load       taskdef.LoadOptions
+	FromECS    string
+	AWS        AWSOptions
 	OutputFile string
 	Format     output.OutputFormat
 	ShowAll    bool
 }
 
 // NewShowCommand creates a new show command
-func NewShowCommand(globalMode *taskdef.LoadMode) *cobra.Command {
+func NewShowCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
 	opts := &ShowOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "show [file]",
+		Use:   "show [file | ecs://family[:revision]]",
 		Short: "Display task definition or container definitions",
-		Long:  `Display the contents of a task definition or container definitions file.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Display the contents of a task definition or container definitions file.
+
+In task mode, the task definition can also be read from ECS with
+ecs://family[:revision] or --from-ecs.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.Mode = *globalMode
+			opts.Load = *globalLoad
 			return runShow(args, opts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&opts.OutputFile, "output", "o", "json", "Output format (json, yaml, text)")
 	cmd.Flags().BoolVar(&opts.ShowAll, "all", false, "Show all fields")
+	cmd.Flags().StringVar(&opts.FromECS, "from-ecs", "", "Read the task definition from ECS (family, family:revision or ARN)")
+	addAWSFlags(cmd, &opts.AWS)
 
 	return cmd
 }
@@ -46,30 +55,10 @@ func runShow(args []string, opts *ShowOptions) error {
 	}
 
 	// Load input
-	var data interface{}
-	var err error
-
-	if len(args) > 0 {
-		// Load from file
-		data, err = taskdef.LoadFromFile(args[0], opts.Mode)
-	} else {
-		// Load from stdin
-		data, err = taskdef.Load(os.Stdin, opts.Mode)
-	}
-
+	doc, err := loadSource(args, opts.FromECS, &opts.AWS, opts.Mode, opts.Load)
 	if err != nil {
 		return err
 	}
 
-	// Format and output
-	switch opts.Mode {
-	case taskdef.ModeTask:
-		taskDef := data.(*taskdef.TaskDefinition)
-		return output.FormatTaskDefinition(os.Stdout, taskDef, opts.Format, opts.ShowAll)
-	case taskdef.ModeContainer:
-		containers := data.([]taskdef.ContainerDefinition)
-		return output.FormatContainerDefinitions(os.Stdout, containers, opts.Format, opts.ShowAll)
-	default:
-		return fmt.Errorf("invalid mode: %s", opts.Mode)
-	}
+	return output.FormatDocument(os.Stdout, doc, opts.Format, opts.ShowAll)
 }
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// ValidateOptions represents options for the validate command
+type ValidateOptions struct {
+	Mode         taskdef.LoadMode
+	Load         taskdef.LoadOptions
+	OutputFormat string
+	Format       output.OutputFormat
+}
+
+// NewValidateCommand creates a new validate command
+func NewValidateCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &ValidateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Validate a task definition or container definitions",
+		Long: `Validate a task definition or container definitions file against the ECS
+RegisterTaskDefinition constraints (required fields, name charset and length,
+FARGATE CPU/memory combinations and network mode, port mappings).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runValidate(args, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.OutputFormat, "output", "o", "text", "Output format (json, yaml, text, sarif)")
+
+	return cmd
+}
+
+func runValidate(args []string, opts *ValidateOptions) error {
+	// Parse output format
+	opts.Format = output.OutputFormat(opts.OutputFormat)
+	switch opts.Format {
+	case output.FormatJSON, output.FormatYAML, output.FormatText, output.FormatSARIF:
+	default:
+		return fmt.Errorf("invalid output format: %s (must be json, yaml, text, or sarif)", opts.OutputFormat)
+	}
+	if opts.Mode != taskdef.ModeTask && opts.Mode != taskdef.ModeContainer {
+		return fmt.Errorf("validate is only supported in task and container modes")
+	}
+
+	// Load input
+	raw, name, err := readInput(args)
+	if err != nil {
+		return err
+	}
+
+	doc, positions, err := taskdef.LoadWithPositions(bytes.NewReader(raw), name, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+
+	errs := validateDocument(doc)
+	if err := output.FormatValidationErrors(os.Stdout, errs, output.Source{URI: name, Positions: positions}, opts.Format); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", len(errs))
+	}
+	return nil
+}
+
+// validateDocument validates task and container documents; other documents are not validated
+func validateDocument(doc taskdef.Document) []taskdef.ValidationError {
+	switch d := doc.(type) {
+	case *taskdef.TaskDocument:
+		return taskdef.ValidateTaskDefinition(d.TaskDefinition)
+	case *taskdef.ContainerDocument:
+		return taskdef.ValidateContainerDefinitions(d.ContainerDefinitions)
+	default:
+		return nil
+	}
+}
+
+// validationFailure builds an error listing every validation error after message
+func validationFailure(message string, errs []taskdef.ValidationError) error {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = "  " + e.Error()
+	}
+	return fmt.Errorf("%s:\n%s", message, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// describeOutputInput is DescribeTaskDefinition output whose task definition has an invalid
+// FARGATE memory size and a container without logging
+const describeOutputInput = `{
+  "taskDefinition": {
+    "family": "my-app",
+    "requiresCompatibilities": ["FARGATE"],
+    "networkMode": "awsvpc",
+    "cpu": "256",
+    "memory": "4096",
+    "containerDefinitions": [
+      {
+        "name": "web",
+        "image": "nginx:1.25",
+        "essential": true,
+        "readonlyRootFilesystem": true
+      }
+    ]
+  },
+  "tags": [
+    {"key": "team", "value": "web"}
+  ]
+}
+`
+
+func TestSARIFDescribeOutputPositions(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "describe.json")
+	if err := os.WriteFile(tmpFile, []byte(describeOutputInput), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		run      func() error
+		path     string
+		expected taskdef.Position
+	}{
+		{
+			name: "validate",
+			run: func() error {
+				return runValidate([]string{tmpFile}, &ValidateOptions{Mode: taskdef.ModeTask, OutputFormat: "sarif"})
+			},
+			path:     "$.memory",
+			expected: taskdef.Position{Line: 7, Column: 5},
+		},
+		{
+			name: "lint",
+			run: func() error {
+				return runLint([]string{tmpFile}, &LintOptions{Mode: taskdef.ModeTask, OutputFormat: "sarif", FailOn: "error"})
+			},
+			path:     "$.containerDefinitions[0]",
+			expected: taskdef.Position{Line: 9, Column: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := captureStdout(t, tt.run)
+			var log struct {
+				Runs []struct {
+					Results []struct {
+						Locations []struct {
+							PhysicalLocation struct {
+								Region struct {
+									StartLine   int `json:"startLine"`
+									StartColumn int `json:"startColumn"`
+								} `json:"region"`
+							} `json:"physicalLocation"`
+							LogicalLocations []struct {
+								FullyQualifiedName string `json:"fullyQualifiedName"`
+							} `json:"logicalLocations"`
+						} `json:"locations"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			if err := json.Unmarshal([]byte(out), &log); err != nil || len(log.Runs) != 1 {
+				t.Fatalf("failed to parse SARIF output: %v\n%s", err, out)
+			}
+
+			found := false
+			for _, result := range log.Runs[0].Results {
+				loc := result.Locations[0]
+				if loc.LogicalLocations[0].FullyQualifiedName != tt.path {
+					continue
+				}
+				found = true
+				region := loc.PhysicalLocation.Region
+				if region.StartLine != tt.expected.Line || region.StartColumn != tt.expected.Column {
+					t.Errorf("%s located at %d:%d, expected %d:%d", tt.path, region.StartLine, region.StartColumn, tt.expected.Line, tt.expected.Column)
+				}
+			}
+			if !found {
+				t.Errorf("no result for %s in SARIF output:\n%s", tt.path, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/registry"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"github.com/spf13/cobra"
+)
+
+// VerifyOptions represents options for the verify command
+type VerifyOptions struct {
+	Mode     taskdef.LoadMode
+	Load     taskdef.LoadOptions
+	Platform bool
+	// Profile is the AWS profile used to get ECR tokens
+	Profile string
+	// Registry is the client used to reach the registries; if nil, a client using the default credentials is created
+	Registry *registry.Client
+}
+
+// NewVerifyCommand creates a new verify command
+func NewVerifyCommand(globalMode *taskdef.LoadMode, globalLoad *taskdef.LoadOptions) *cobra.Command {
+	opts := &VerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify [file]",
+		Short: "Check that the container images exist in their registries",
+		Long: `Check that every container image of a document exists in its registry. In the
+cfn, terraform, compose and k8s modes, images that are not literals (e.g.
+${AWS::AccountId}.dkr.ecr...) are skipped.
+
+With --platform, the image index of each image is inspected to check that the
+image is available for the runtime platform of the task definition
+(runtimePlatform.cpuArchitecture and operatingSystemFamily, linux/amd64 if unset).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Mode = *globalMode
+			opts.Load = *globalLoad
+			return runVerify(args, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Platform, "platform", false, "Check that every image is available for the runtime platform of the task definition")
+	cmd.Flags().StringVar(&opts.Profile, "profile", "", "AWS profile used to get ECR tokens (default: AWS_PROFILE or default)")
+
+	return cmd
+}
+
+func runVerify(args []string, opts *VerifyOptions) error {
+	doc, err := loadDocument(args, opts.Mode, opts.Load)
+	if err != nil {
+		return err
+	}
+
+	var platform *registry.Platform
+	if opts.Platform {
+		var rp *taskdef.RuntimePlatform
+		if d, ok := doc.(*taskdef.TaskDocument); ok {
+			rp = d.TaskDefinition.RuntimePlatform
+		}
+		p := registry.RuntimePlatform(rp)
+		platform = &p
+	}
+
+	client, err := registryClient(opts.Registry, opts.Profile)
+	if err != nil {
+		return err
+	}
+	if problems := checkImages(client, doc, platform); len(problems) > 0 {
+		return fmt.Errorf("image verification failed for %d image(s):\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+
+	if platform != nil {
+		_, err = fmt.Fprintf(os.Stdout, "All images are available for %s\n", platform)
+	} else {
+		_, err = fmt.Fprintln(os.Stdout, "All images exist")
+	}
+	return err
+}
+
+// checkImages checks that every image of a document exists in its registry and, if platform is
+// set, that it is available for that platform. Images using placeholders and templated images
+// (${...}) are skipped. The result describes every image that failed a check or could not be
+// checked.
+func checkImages(client *registry.Client, doc taskdef.Document, platform *registry.Platform) []string {
+	var problems []string
+	checked := make(map[string]bool)
+	for _, c := range doc.Containers() {
+		if _, ok := taskdef.ImagePlaceholder(c.Image); ok || c.Image == "" || strings.Contains(c.Image, "${") || checked[c.Image] {
+			continue
+		}
+		checked[c.Image] = true
+
+		if problem := checkImage(client, c.Image, platform); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s (container '%s'): %s", c.Image, c.Name, problem))
+		}
+	}
+	return problems
+}
+
+// checkImage checks a single image and describes the problem found, or returns "" if there is none
+func checkImage(client *registry.Client, image string, platform *registry.Platform) string {
+	ref := taskdef.ParseImageReference(image)
+	if platform == nil {
+		exists, err := client.Exists(context.Background(), ref)
+		switch {
+		case err != nil:
+			return err.Error()
+		case !exists:
+			return "not found"
+		}
+		return ""
+	}
+
+	platforms, err := client.Platforms(context.Background(), ref)
+	if errors.Is(err, registry.ErrNotFound) {
+		return "not found"
+	}
+	if err != nil {
+		return err.Error()
+	}
+	available := make([]string, len(platforms))
+	for i, p := range platforms {
+		if p.Satisfies(*platform) {
+			return ""
+		}
+		available[i] = p.String()
+	}
+	return fmt.Sprintf("not available for %s (available: %s)", platform, strings.Join(available, ", "))
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/registry"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestVerifyPlatform(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/my-app/manifests/v1.0.0":
+			_, _ = w.Write([]byte(`{"manifests": [
+  {"platform": {"os": "linux", "architecture": "amd64"}},
+  {"platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}}
+]}`))
+		case "/v2/sidecar/manifests/v1.0.0":
+			_, _ = w.Write([]byte(`{"config": {"digest": "sha256:cfg"}}`))
+		case "/v2/sidecar/blobs/sha256:cfg":
+			_, _ = w.Write([]byte(`{"os": "linux", "architecture": "amd64"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	client := registry.NewClient(nil)
+	client.HTTPClient = server.Client()
+
+	tests := []struct {
+		name      string
+		platform  string
+		images    []string
+		wantError []string
+	}{
+		{
+			name:     "Default platform",
+			platform: "",
+			images:   []string{"my-app:v1.0.0", "sidecar:v1.0.0"},
+		},
+		{
+			name:     "ARM64 with a single-platform image",
+			platform: `"runtimePlatform": {"cpuArchitecture": "ARM64", "operatingSystemFamily": "LINUX"},`,
+			images:   []string{"my-app:v1.0.0", "sidecar:v1.0.0", "proxy:v1.0.0"},
+			wantError: []string{
+				"2 image(s)",
+				host + "/sidecar:v1.0.0 (container 'c1'): not available for linux/arm64 (available: linux/amd64)",
+				host + "/proxy:v1.0.0 (container 'c2'): not found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := make([]string, len(tt.images))
+			for i, image := range tt.images {
+				containers[i] = `{"name": "c` + string(rune('0'+i)) + `", "image": "` + host + "/" + image + `"}`
+			}
+			content := `{"family": "my-app", ` + tt.platform + ` "containerDefinitions": [` + strings.Join(containers, ", ") + `]}`
+			tmpFile := filepath.Join(t.TempDir(), "task-definition.json")
+			if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create temp file: %v", err)
+			}
+
+			err := runVerify([]string{tmpFile}, &VerifyOptions{Mode: taskdef.ModeTask, Platform: true, Registry: client})
+			if tt.wantError == nil {
+				if err != nil {
+					t.Errorf("runVerify() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("runVerify() expected an error")
+			}
+			for _, want := range tt.wantError {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("runVerify() error does not contain %q:\n%v", want, err)
+				}
+			}
+			if strings.Contains(err.Error(), "my-app") {
+				t.Errorf("runVerify() error lists a multi-platform image:\n%v", err)
+			}
+		})
+	}
+}
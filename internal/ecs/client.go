@@ -0,0 +1,186 @@
+// Package ecs calls the Amazon ECS API operations used by ecs-tag-shift
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/aws"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// readOnlyFields are the fields DescribeTaskDefinition returns that RegisterTaskDefinition rejects
+var readOnlyFields = []string{
+	"taskDefinitionArn",
+	"status",
+	"requiresAttributes",
+	"compatibilities",
+	"registeredAt",
+	"registeredBy",
+	"deregisteredAt",
+}
+
+// Client calls the ECS API
+type Client struct {
+	AWS *aws.Client
+}
+
+// NewClient creates a client using the credentials of profile. An empty region is loaded from the
+// AWS config files, and an empty endpoint means the regional ECS endpoint.
+func NewClient(profile, region, endpoint string) (*Client, error) {
+	client, err := aws.NewClient(profile, region, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{AWS: client}, nil
+}
+
+// RegisterTaskDefinition registers a task definition and returns the new revision. Read-only
+// fields of described task definitions, such as the revision and ARN, are left out of the request.
+func (c *Client) RegisterTaskDefinition(ctx context.Context, td *taskdef.TaskDefinition) (*taskdef.TaskDefinition, error) {
+	input := *td
+	input.Revision = 0
+	input.Extra = make(map[string]interface{}, len(td.Extra))
+	for k, v := range td.Extra {
+		input.Extra[k] = v
+	}
+	for _, k := range readOnlyFields {
+		delete(input.Extra, k)
+	}
+
+	var output struct {
+		TaskDefinition *taskdef.TaskDefinition `json:"taskDefinition"`
+	}
+	if err := c.AWS.Call(ctx, aws.ECS, "RegisterTaskDefinition", &input, &output); err != nil {
+		return nil, err
+	}
+	if output.TaskDefinition == nil {
+		return nil, fmt.Errorf("RegisterTaskDefinition returned no task definition")
+	}
+	return output.TaskDefinition, nil
+}
+
+// DescribeTaskDefinition returns the DescribeTaskDefinition output, including tags, for a task
+// definition given as family, family:revision or ARN. The output is returned as-is so that it
+// can be loaded like the output of aws ecs describe-task-definition.
+func (c *Client) DescribeTaskDefinition(ctx context.Context, taskDefinition string) ([]byte, error) {
+	input := map[string]interface{}{
+		"taskDefinition": taskDefinition,
+		"include":        []string{"TAGS"},
+	}
+	var output json.RawMessage
+	if err := c.AWS.Call(ctx, aws.ECS, "DescribeTaskDefinition", input, &output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// TaskDefinitionARN returns the ARN of a registered task definition, or "" if it has none
+func TaskDefinitionARN(td *taskdef.TaskDefinition) string {
+	arn, _ := td.Extra["taskDefinitionArn"].(string)
+	return arn
+}
+
+// ListTaskDefinitions returns the ARNs of the revisions of a family with a status (ACTIVE or
+// INACTIVE), newest first. Families that only share the prefix are left out.
+func (c *Client) ListTaskDefinitions(ctx context.Context, family, status string) ([]string, error) {
+	input := map[string]interface{}{
+		"familyPrefix": family,
+		"status":       status,
+		"sort":         "DESC",
+	}
+	all, err := c.listAll(ctx, "ListTaskDefinitions", input, "taskDefinitionArns")
+	if err != nil {
+		return nil, err
+	}
+	var arns []string
+	for _, arn := range all {
+		if f, _, ok := ParseTaskDefinitionARN(arn); ok && f == family {
+			arns = append(arns, arn)
+		}
+	}
+	return arns, nil
+}
+
+// DeregisterTaskDefinition marks a revision INACTIVE. Services and tasks already using it keep
+// running, but no new ones can be started with it.
+func (c *Client) DeregisterTaskDefinition(ctx context.Context, taskDefinition string) error {
+	input := map[string]interface{}{"taskDefinition": taskDefinition}
+	return c.AWS.Call(ctx, aws.ECS, "DeregisterTaskDefinition", input, nil)
+}
+
+// deleteBatchSize is the maximum number of task definitions of a DeleteTaskDefinitions call
+const deleteBatchSize = 10
+
+// DeleteTaskDefinitions permanently deletes INACTIVE revisions, in batches of up to 10
+func (c *Client) DeleteTaskDefinitions(ctx context.Context, taskDefinitions []string) error {
+	for start := 0; start < len(taskDefinitions); start += deleteBatchSize {
+		end := min(start+deleteBatchSize, len(taskDefinitions))
+		input := map[string]interface{}{"taskDefinitions": taskDefinitions[start:end]}
+		var output struct {
+			Failures []failure `json:"failures"`
+		}
+		if err := c.AWS.Call(ctx, aws.ECS, "DeleteTaskDefinitions", input, &output); err != nil {
+			return err
+		}
+		if len(output.Failures) > 0 {
+			return fmt.Errorf("failed to delete task definition %s: %s", output.Failures[0].Arn, output.Failures[0].Reason)
+		}
+	}
+	return nil
+}
+
+// failure is a resource an ECS batch operation failed for
+type failure struct {
+	Arn    string `json:"arn"`
+	Reason string `json:"reason"`
+}
+
+// listAll calls a List operation until every page is read and returns the strings of field
+func (c *Client) listAll(ctx context.Context, operation string, input map[string]interface{}, field string) ([]string, error) {
+	var all []string
+	for {
+		var output map[string]json.RawMessage
+		if err := c.AWS.Call(ctx, aws.ECS, operation, input, &output); err != nil {
+			return nil, err
+		}
+		var values []string
+		if raw, ok := output[field]; ok {
+			if err := json.Unmarshal(raw, &values); err != nil {
+				return nil, fmt.Errorf("failed to parse %s response: %w", operation, err)
+			}
+		}
+		all = append(all, values...)
+
+		var token string
+		if raw, ok := output["nextToken"]; ok {
+			if err := json.Unmarshal(raw, &token); err != nil {
+				return nil, fmt.Errorf("failed to parse %s response: %w", operation, err)
+			}
+		}
+		if token == "" {
+			return all, nil
+		}
+		input["nextToken"] = token
+	}
+}
+
+// ParseTaskDefinitionARN splits a task definition ARN (or family:revision) into its family and revision
+func ParseTaskDefinitionARN(arn string) (family string, revision int, ok bool) {
+	name := arn
+	if i := strings.LastIndex(arn, "task-definition/"); i != -1 {
+		name = arn[i+len("task-definition/"):]
+	}
+	family, rev, found := strings.Cut(name, ":")
+	if !found || family == "" {
+		return "", 0, false
+	}
+	revision, err := strconv.Atoi(rev)
+	if err != nil {
+		return "", 0, false
+	}
+	return family, revision, true
+}
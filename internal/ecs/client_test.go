@@ -0,0 +1,192 @@
+package ecs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/aws"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// newTestClient returns a client for a fake ECS endpoint. handle receives the operation and the
+// request body and returns the response body.
+func newTestClient(t *testing.T, handle func(operation string, body map[string]interface{}) (int, string)) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.Header.Get("X-Amz-Target")
+		if !strings.HasPrefix(target, "AmazonEC2ContainerServiceV20141113.") {
+			t.Errorf("unexpected X-Amz-Target %q", target)
+		}
+		if !strings.Contains(r.Header.Get("Authorization"), "/us-east-1/ecs/aws4_request") {
+			t.Errorf("request is not signed for ECS: %q", r.Header.Get("Authorization"))
+		}
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		status, response := handle(strings.TrimPrefix(target, "AmazonEC2ContainerServiceV20141113."), body)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return &Client{AWS: &aws.Client{
+		Region:      "us-east-1",
+		Credentials: aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    server.URL,
+	}}
+}
+
+func TestRegisterTaskDefinition(t *testing.T) {
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		if operation != "RegisterTaskDefinition" {
+			t.Errorf("unexpected operation %s", operation)
+		}
+		for _, k := range []string{"revision", "taskDefinitionArn", "status", "registeredAt"} {
+			if _, ok := body[k]; ok {
+				t.Errorf("request contains read-only field %s", k)
+			}
+		}
+		if body["family"] != "my-app" || body["enableFaultInjection"] != true {
+			t.Errorf("unexpected request %v", body)
+		}
+		return http.StatusOK, `{"taskDefinition": {"family": "my-app", "revision": 8, "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8", "containerDefinitions": []}}`
+	})
+
+	td, err := taskdef.LoadTaskDefinition(strings.NewReader(`{
+  "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7",
+  "family": "my-app",
+  "revision": 7,
+  "status": "ACTIVE",
+  "registeredAt": "2024-01-01T00:00:00Z",
+  "enableFaultInjection": true,
+  "containerDefinitions": [{"name": "web", "image": "my-app:v2"}]
+}`))
+	if err != nil {
+		t.Fatalf("LoadTaskDefinition() error = %v", err)
+	}
+
+	registered, err := client.RegisterTaskDefinition(context.Background(), td)
+	if err != nil {
+		t.Fatalf("RegisterTaskDefinition() error = %v", err)
+	}
+	if registered.Revision != 8 || TaskDefinitionARN(registered) != "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8" {
+		t.Errorf("RegisterTaskDefinition() = %+v", registered)
+	}
+	if td.Revision != 7 || TaskDefinitionARN(td) == "" {
+		t.Errorf("RegisterTaskDefinition() modified its input")
+	}
+}
+
+func TestRegisterTaskDefinitionError(t *testing.T) {
+	client := newTestClient(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusBadRequest, `{"__type": "ClientException", "message": "Invalid family"}`
+	})
+	_, err := client.RegisterTaskDefinition(context.Background(), &taskdef.TaskDefinition{Family: "bad family"})
+	if err == nil || !strings.Contains(err.Error(), "ClientException: Invalid family") {
+		t.Errorf("RegisterTaskDefinition() error = %v", err)
+	}
+}
+
+func TestDescribeTaskDefinition(t *testing.T) {
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		if operation != "DescribeTaskDefinition" || body["taskDefinition"] != "my-app:7" {
+			t.Errorf("unexpected %s request %v", operation, body)
+		}
+		if include, _ := body["include"].([]interface{}); len(include) != 1 || include[0] != "TAGS" {
+			t.Errorf("request does not include tags: %v", body)
+		}
+		return http.StatusOK, `{"taskDefinition": {"family": "my-app", "revision": 7, "containerDefinitions": [{"name": "web", "image": "my-app:v1"}]}, "tags": [{"key": "team", "value": "web"}]}`
+	})
+
+	data, err := client.DescribeTaskDefinition(context.Background(), "my-app:7")
+	if err != nil {
+		t.Fatalf("DescribeTaskDefinition() error = %v", err)
+	}
+	td, err := taskdef.LoadTaskDefinition(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("LoadTaskDefinition() error = %v", err)
+	}
+	if td.Family != "my-app" || td.Revision != 7 || len(td.Tags) != 1 {
+		t.Errorf("DescribeTaskDefinition() = %s", data)
+	}
+}
+
+func TestListTaskDefinitions(t *testing.T) {
+	pages := map[string]string{
+		"": `{"taskDefinitionArns": [
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app-worker:3",
+  "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:9"
+], "nextToken": "page2"}`,
+		"page2": `{"taskDefinitionArns": ["arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8"]}`,
+	}
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		if operation != "ListTaskDefinitions" || body["familyPrefix"] != "my-app" || body["status"] != "ACTIVE" || body["sort"] != "DESC" {
+			t.Errorf("unexpected %s request %v", operation, body)
+		}
+		token, _ := body["nextToken"].(string)
+		return http.StatusOK, pages[token]
+	})
+
+	arns, err := client.ListTaskDefinitions(context.Background(), "my-app", "ACTIVE")
+	if err != nil {
+		t.Fatalf("ListTaskDefinitions() error = %v", err)
+	}
+	expected := "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:9,arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8"
+	if strings.Join(arns, ",") != expected {
+		t.Errorf("ListTaskDefinitions() = %v", arns)
+	}
+}
+
+func TestParseTaskDefinitionARN(t *testing.T) {
+	tests := []struct {
+		arn      string
+		family   string
+		revision int
+		ok       bool
+	}{
+		{arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:12", family: "my-app", revision: 12, ok: true},
+		{arn: "my-app:3", family: "my-app", revision: 3, ok: true},
+		{arn: "my-app", ok: false},
+		{arn: "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:latest", ok: false},
+	}
+	for _, tt := range tests {
+		family, revision, ok := ParseTaskDefinitionARN(tt.arn)
+		if family != tt.family || revision != tt.revision || ok != tt.ok {
+			t.Errorf("ParseTaskDefinitionARN(%q) = %q, %d, %v", tt.arn, family, revision, ok)
+		}
+	}
+}
+
+func TestDeleteTaskDefinitions(t *testing.T) {
+	var batches []int
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		if operation != "DeleteTaskDefinitions" {
+			t.Errorf("unexpected operation %s", operation)
+		}
+		arns, _ := body["taskDefinitions"].([]interface{})
+		batches = append(batches, len(arns))
+		if len(batches) == 2 {
+			return http.StatusOK, `{"failures": [{"arn": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:12", "reason": "TASK_DEFINITION_NOT_INACTIVE"}]}`
+		}
+		return http.StatusOK, `{"taskDefinitions": []}`
+	})
+
+	arns := make([]string, 12)
+	for i := range arns {
+		arns[i] = fmt.Sprintf("arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:%d", i+1)
+	}
+	err := client.DeleteTaskDefinitions(context.Background(), arns)
+	if err == nil || !strings.Contains(err.Error(), "my-app:12: TASK_DEFINITION_NOT_INACTIVE") {
+		t.Errorf("DeleteTaskDefinitions() error = %v", err)
+	}
+	if len(batches) != 2 || batches[0] != 10 || batches[1] != 2 {
+		t.Errorf("batch sizes = %v, expected [10 2]", batches)
+	}
+}
@@ -0,0 +1,83 @@
+package ecs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prune actions of a revision
+const (
+	PruneKeep       = "keep"
+	PruneDeregister = "deregister"
+	PruneDelete     = "delete"
+)
+
+// PruneRevision is a revision of a task definition family and what pruning does with it
+type PruneRevision struct {
+	ARN      string `json:"taskDefinitionArn"`
+	Revision int    `json:"revision"`
+	Status   string `json:"status"`
+	Action   string `json:"action"`
+	// Reason explains why a revision is kept, e.g. "latest 10" or "in use by prod/web"
+	Reason string `json:"reason,omitempty"`
+}
+
+// PrunePlanOptions represents options for planning a prune
+type PrunePlanOptions struct {
+	// Keep is the number of the newest ACTIVE revisions to keep
+	Keep int
+	// InUse maps revisions used by services to the services using them, as cluster/service
+	InUse map[int][]string
+	// Delete deletes the removed revisions instead of only deregistering them; INACTIVE
+	// revisions are then deleted as well
+	Delete bool
+}
+
+// PlanPrune decides what to do with the ACTIVE and INACTIVE revisions of a family. The newest
+// ACTIVE revisions and the revisions in use are kept; the other ACTIVE revisions are deregistered,
+// or deleted with Delete. The result is sorted newest first.
+func PlanPrune(active, inactive []string, opts PrunePlanOptions) []PruneRevision {
+	var plan []PruneRevision
+	add := func(arns []string, status string) {
+		for _, arn := range arns {
+			_, revision, ok := ParseTaskDefinitionARN(arn)
+			if !ok {
+				continue
+			}
+			plan = append(plan, PruneRevision{ARN: arn, Revision: revision, Status: status})
+		}
+	}
+	add(active, "ACTIVE")
+	if opts.Delete {
+		add(inactive, "INACTIVE")
+	}
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].Revision > plan[j].Revision
+	})
+
+	latest := 0
+	for i := range plan {
+		r := &plan[i]
+		var reasons []string
+		if r.Status == "ACTIVE" {
+			if latest < opts.Keep {
+				reasons = append(reasons, "latest "+strconv.Itoa(opts.Keep))
+			}
+			latest++
+		}
+		if services := opts.InUse[r.Revision]; len(services) > 0 {
+			reasons = append(reasons, "in use by "+strings.Join(services, ", "))
+		}
+		switch {
+		case len(reasons) > 0:
+			r.Action = PruneKeep
+			r.Reason = strings.Join(reasons, "; ")
+		case opts.Delete:
+			r.Action = PruneDelete
+		default:
+			r.Action = PruneDeregister
+		}
+	}
+	return plan
+}
@@ -0,0 +1,60 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPlanPrune(t *testing.T) {
+	arn := func(revision int) string {
+		return fmt.Sprintf("arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:%d", revision)
+	}
+	active := []string{arn(6), arn(5), arn(4), arn(3), arn(2)}
+	inactive := []string{arn(1)}
+
+	tests := []struct {
+		name     string
+		opts     PrunePlanOptions
+		expected string
+	}{
+		{
+			name:     "Keep the latest",
+			opts:     PrunePlanOptions{Keep: 2},
+			expected: "6:keep,5:keep,4:deregister,3:deregister,2:deregister",
+		},
+		{
+			name:     "Keep revisions in use",
+			opts:     PrunePlanOptions{Keep: 2, InUse: map[int][]string{5: {"prod/web"}, 3: {"prod/web", "stg/web"}}},
+			expected: "6:keep,5:keep,4:deregister,3:keep,2:deregister",
+		},
+		{
+			name:     "Delete including inactive",
+			opts:     PrunePlanOptions{Keep: 1, InUse: map[int][]string{1: {"prod/batch"}}, Delete: true},
+			expected: "6:keep,5:delete,4:delete,3:delete,2:delete,1:keep",
+		},
+		{
+			name:     "Keep none",
+			opts:     PrunePlanOptions{Keep: 0},
+			expected: "6:deregister,5:deregister,4:deregister,3:deregister,2:deregister",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanPrune(active, inactive, tt.opts)
+			var actions []string
+			for _, r := range plan {
+				actions = append(actions, fmt.Sprintf("%d:%s", r.Revision, r.Action))
+			}
+			if got := strings.Join(actions, ","); got != tt.expected {
+				t.Errorf("PlanPrune() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+
+	plan := PlanPrune(active, nil, PrunePlanOptions{Keep: 1, InUse: map[int][]string{6: {"prod/web"}, 3: {"prod/web", "stg/web"}}})
+	if plan[0].Reason != "latest 1; in use by prod/web" || plan[3].Reason != "in use by prod/web, stg/web" {
+		t.Errorf("PlanPrune() reasons = %q, %q", plan[0].Reason, plan[3].Reason)
+	}
+}
@@ -0,0 +1,270 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/aws"
+)
+
+// Rollout states of a deployment
+const (
+	RolloutInProgress = "IN_PROGRESS"
+	RolloutCompleted  = "COMPLETED"
+	RolloutFailed     = "FAILED"
+)
+
+// Service is the part of an ECS service needed to follow deployments
+type Service struct {
+	ServiceName    string         `json:"serviceName"`
+	ServiceArn     string         `json:"serviceArn"`
+	Status         string         `json:"status"`
+	TaskDefinition string         `json:"taskDefinition"`
+	DesiredCount   int            `json:"desiredCount"`
+	RunningCount   int            `json:"runningCount"`
+	PendingCount   int            `json:"pendingCount"`
+	Deployments    []Deployment   `json:"deployments"`
+	TaskSets       []TaskSet      `json:"taskSets"`
+	Events         []ServiceEvent `json:"events"`
+}
+
+// TaskSet is a task set of a service using the CODE_DEPLOY or EXTERNAL deployment controller,
+// which has task sets instead of deployments
+type TaskSet struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	TaskDefinition string `json:"taskDefinition"`
+}
+
+// Deployment is a deployment of an ECS service
+type Deployment struct {
+	ID                 string `json:"id"`
+	Status             string `json:"status"`
+	TaskDefinition     string `json:"taskDefinition"`
+	DesiredCount       int    `json:"desiredCount"`
+	PendingCount       int    `json:"pendingCount"`
+	RunningCount       int    `json:"runningCount"`
+	FailedTasks        int    `json:"failedTasks"`
+	RolloutState       string `json:"rolloutState"`
+	RolloutStateReason string `json:"rolloutStateReason"`
+}
+
+// ServiceEvent is an event of an ECS service. Services list their events newest first.
+type ServiceEvent struct {
+	ID        string  `json:"id"`
+	CreatedAt float64 `json:"createdAt"`
+	Message   string  `json:"message"`
+}
+
+// Time returns the time the event was created
+func (e ServiceEvent) Time() time.Time {
+	return time.Unix(0, int64(e.CreatedAt*float64(time.Second)))
+}
+
+// Primary returns the PRIMARY deployment of the service, or nil if there is none
+func (s *Service) Primary() *Deployment {
+	for i := range s.Deployments {
+		if s.Deployments[i].Status == "PRIMARY" {
+			return &s.Deployments[i]
+		}
+	}
+	return nil
+}
+
+// Deployment returns the deployment with the given ID, or nil if the service no longer has it
+func (s *Service) Deployment(id string) *Deployment {
+	for i := range s.Deployments {
+		if s.Deployments[i].ID == id {
+			return &s.Deployments[i]
+		}
+	}
+	return nil
+}
+
+// UpdateService points a service at a task definition, which starts a new deployment
+func (c *Client) UpdateService(ctx context.Context, cluster, service, taskDefinition string) (*Service, error) {
+	input := map[string]interface{}{
+		"cluster":        cluster,
+		"service":        service,
+		"taskDefinition": taskDefinition,
+	}
+	var output struct {
+		Service *Service `json:"service"`
+	}
+	if err := c.AWS.Call(ctx, aws.ECS, "UpdateService", input, &output); err != nil {
+		return nil, err
+	}
+	if output.Service == nil {
+		return nil, fmt.Errorf("UpdateService returned no service")
+	}
+	return output.Service, nil
+}
+
+// DescribeService returns a service of a cluster
+func (c *Client) DescribeService(ctx context.Context, cluster, service string) (*Service, error) {
+	services, err := c.DescribeServices(ctx, cluster, []string{service})
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("service %s not found in cluster %s", service, cluster)
+	}
+	return &services[0], nil
+}
+
+// describeServicesBatchSize is the maximum number of services of a DescribeServices call
+const describeServicesBatchSize = 10
+
+// DescribeServices returns services of a cluster, in batches of up to 10
+func (c *Client) DescribeServices(ctx context.Context, cluster string, services []string) ([]Service, error) {
+	var described []Service
+	for start := 0; start < len(services); start += describeServicesBatchSize {
+		end := min(start+describeServicesBatchSize, len(services))
+		input := map[string]interface{}{
+			"cluster":  cluster,
+			"services": services[start:end],
+		}
+		var output struct {
+			Services []Service `json:"services"`
+			Failures []failure `json:"failures"`
+		}
+		if err := c.AWS.Call(ctx, aws.ECS, "DescribeServices", input, &output); err != nil {
+			return nil, err
+		}
+		if len(output.Failures) > 0 {
+			return nil, fmt.Errorf("failed to describe service %s: %s", output.Failures[0].Arn, output.Failures[0].Reason)
+		}
+		described = append(described, output.Services...)
+	}
+	return described, nil
+}
+
+// ListClusters returns the ARNs of the clusters of the account in the region
+func (c *Client) ListClusters(ctx context.Context) ([]string, error) {
+	return c.listAll(ctx, "ListClusters", map[string]interface{}{}, "clusterArns")
+}
+
+// ListServices returns the ARNs of the services of a cluster
+func (c *Client) ListServices(ctx context.Context, cluster string) ([]string, error) {
+	return c.listAll(ctx, "ListServices", map[string]interface{}{"cluster": cluster}, "serviceArns")
+}
+
+// TaskDefinitions returns the task definitions a service uses: that of the service and those
+// of its deployments and task sets, which include revisions still running during a rollout or a
+// blue/green deployment
+func (s *Service) TaskDefinitions() []string {
+	var tds []string
+	if s.TaskDefinition != "" {
+		tds = append(tds, s.TaskDefinition)
+	}
+	for _, d := range s.Deployments {
+		if d.TaskDefinition != "" {
+			tds = append(tds, d.TaskDefinition)
+		}
+	}
+	for _, ts := range s.TaskSets {
+		if ts.TaskDefinition != "" {
+			tds = append(tds, ts.TaskDefinition)
+		}
+	}
+	return tds
+}
+
+// ErrRolledBack is returned by WaitForDeployment when the deployment circuit breaker rolled a
+// failed deployment back
+var ErrRolledBack = errors.New("deployment was rolled back")
+
+// ErrSuperseded is returned by WaitForDeployment when another deployment, e.g. of a newer revision
+// registered by a later run, replaced the deployment before it completed
+var ErrSuperseded = errors.New("deployment was superseded")
+
+// WaitOptions represents options for waiting for a deployment
+type WaitOptions struct {
+	// Interval is the time between DescribeServices calls
+	Interval time.Duration
+	// TaskDefinition is the task definition being deployed. If the deployment disappears, it is
+	// reported as rolled back only if the new PRIMARY deployment uses an older revision of it.
+	TaskDefinition string
+	// Progress, if set, is called with the service and the deployment after each poll
+	Progress func(svc *Service, deployment *Deployment)
+}
+
+// WaitForDeployment polls a service until a deployment completes, fails or is rolled back, or ctx
+// is done. Deployments without a rollout state (e.g. of services not using the ECS deployment
+// controller) complete when they are the only deployment and all their tasks are running.
+func (c *Client) WaitForDeployment(ctx context.Context, cluster, service, deploymentID string, opts WaitOptions) (*Deployment, error) {
+	var last *Deployment
+	for {
+		svc, err := c.DescribeService(ctx, cluster, service)
+		if err != nil {
+			if ctx.Err() != nil {
+				return last, timeoutError(deploymentID, last)
+			}
+			return last, err
+		}
+
+		d := svc.Deployment(deploymentID)
+		if opts.Progress != nil {
+			opts.Progress(svc, d)
+		}
+		if d == nil {
+			primary := svc.Primary()
+			if primary == nil {
+				return last, fmt.Errorf("deployment %s not found in service %s", deploymentID, service)
+			}
+			deployed := opts.TaskDefinition
+			if deployed == "" && last != nil {
+				deployed = last.TaskDefinition
+			}
+			// The circuit breaker replaces a failed deployment with one for the previous revision
+			if isOlderRevision(primary.TaskDefinition, deployed) {
+				return last, fmt.Errorf("%w to %s", ErrRolledBack, primary.TaskDefinition)
+			}
+			return last, fmt.Errorf("%w by deployment %s of %s", ErrSuperseded, primary.ID, primary.TaskDefinition)
+		}
+		last = d
+
+		switch d.RolloutState {
+		case RolloutCompleted:
+			return d, nil
+		case RolloutFailed:
+			reason := d.RolloutStateReason
+			if primary := svc.Primary(); primary != nil && primary.ID != d.ID {
+				return d, fmt.Errorf("deployment %s failed (%s): %w to %s", d.ID, reason, ErrRolledBack, primary.TaskDefinition)
+			}
+			return d, fmt.Errorf("deployment %s failed: %s", d.ID, reason)
+		case "":
+			if len(svc.Deployments) == 1 && d.RunningCount == d.DesiredCount && d.PendingCount == 0 {
+				return d, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return d, timeoutError(deploymentID, d)
+		case <-time.After(opts.Interval):
+		}
+	}
+}
+
+// isOlderRevision reports whether task definition a is an earlier revision of the family of b
+func isOlderRevision(a, b string) bool {
+	familyA, revisionA, okA := ParseTaskDefinitionARN(a)
+	familyB, revisionB, okB := ParseTaskDefinitionARN(b)
+	return okA && okB && familyA == familyB && revisionA < revisionB
+}
+
+// timeoutError reports a deployment that did not finish in time, with its last known state
+func timeoutError(deploymentID string, d *Deployment) error {
+	if d == nil {
+		return fmt.Errorf("timed out waiting for deployment %s", deploymentID)
+	}
+	state := fmt.Sprintf("%d/%d tasks running", d.RunningCount, d.DesiredCount)
+	if d.RolloutState != "" {
+		state = strings.ToLower(d.RolloutState) + ", " + state
+	}
+	return fmt.Errorf("timed out waiting for deployment %s (%s)", deploymentID, state)
+}
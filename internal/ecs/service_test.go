@@ -0,0 +1,207 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateService(t *testing.T) {
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		if operation != "UpdateService" || body["cluster"] != "prod" || body["service"] != "web" || body["taskDefinition"] != "my-app:8" {
+			t.Errorf("unexpected %s request %v", operation, body)
+		}
+		return http.StatusOK, `{"service": {"serviceName": "web", "deployments": [
+  {"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "rolloutState": "IN_PROGRESS"},
+  {"id": "ecs-svc/1", "status": "ACTIVE", "taskDefinition": "my-app:7", "rolloutState": "COMPLETED"}
+]}}`
+	})
+
+	svc, err := client.UpdateService(context.Background(), "prod", "web", "my-app:8")
+	if err != nil {
+		t.Fatalf("UpdateService() error = %v", err)
+	}
+	if primary := svc.Primary(); primary == nil || primary.ID != "ecs-svc/2" {
+		t.Errorf("Primary() = %+v", primary)
+	}
+}
+
+func TestDescribeServiceMissing(t *testing.T) {
+	client := newTestClient(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusOK, `{"services": [], "failures": [{"arn": "arn:aws:ecs:us-east-1:123456789012:service/prod/api", "reason": "MISSING"}]}`
+	})
+	if _, err := client.DescribeService(context.Background(), "prod", "api"); err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("DescribeService() error = %v", err)
+	}
+}
+
+func TestWaitForDeployment(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []string
+		timeout    time.Duration
+		wantErr    string
+		rollback   bool
+		superseded bool
+	}{
+		{
+			name: "Completed",
+			responses: []string{
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "rolloutState": "IN_PROGRESS", "desiredCount": 2, "runningCount": 1},
+				 {"id": "ecs-svc/1", "status": "ACTIVE", "taskDefinition": "my-app:7", "rolloutState": "COMPLETED"}`,
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "rolloutState": "COMPLETED", "desiredCount": 2, "runningCount": 2}`,
+			},
+		},
+		{
+			name: "Failed and rolled back by the circuit breaker",
+			responses: []string{
+				`{"id": "ecs-svc/3", "status": "PRIMARY", "taskDefinition": "my-app:7", "rolloutState": "IN_PROGRESS"},
+				 {"id": "ecs-svc/2", "status": "ACTIVE", "taskDefinition": "my-app:8", "rolloutState": "FAILED", "rolloutStateReason": "ECS deployment circuit breaker: tasks failed to start."}`,
+			},
+			wantErr:  "deployment ecs-svc/2 failed (ECS deployment circuit breaker: tasks failed to start.): deployment was rolled back to my-app:7",
+			rollback: true,
+		},
+		{
+			name: "Replaced by the rollback deployment",
+			responses: []string{
+				`{"id": "ecs-svc/3", "status": "PRIMARY", "taskDefinition": "my-app:7", "rolloutState": "IN_PROGRESS"}`,
+			},
+			wantErr:  "deployment was rolled back to my-app:7",
+			rollback: true,
+		},
+		{
+			name: "Superseded by a newer revision",
+			responses: []string{
+				`{"id": "ecs-svc/3", "status": "PRIMARY", "taskDefinition": "my-app:9", "rolloutState": "IN_PROGRESS"}`,
+			},
+			wantErr:    "deployment was superseded by deployment ecs-svc/3 of my-app:9",
+			superseded: true,
+		},
+		{
+			name: "Failed without rollback",
+			responses: []string{
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "rolloutState": "FAILED", "rolloutStateReason": "tasks failed to start"}`,
+			},
+			wantErr: "deployment ecs-svc/2 failed: tasks failed to start",
+		},
+		{
+			name: "No rollout state",
+			responses: []string{
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "desiredCount": 1, "runningCount": 0, "pendingCount": 1},
+				 {"id": "ecs-svc/1", "status": "ACTIVE", "taskDefinition": "my-app:7", "desiredCount": 0, "runningCount": 1}`,
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "desiredCount": 1, "runningCount": 1}`,
+			},
+		},
+		{
+			name: "Timeout",
+			responses: []string{
+				`{"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "my-app:8", "rolloutState": "IN_PROGRESS", "desiredCount": 2, "runningCount": 1}`,
+			},
+			timeout: 20 * time.Millisecond,
+			wantErr: "timed out waiting for deployment ecs-svc/2 (in_progress, 1/2 tasks running)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+				if operation != "DescribeServices" {
+					t.Errorf("unexpected operation %s", operation)
+				}
+				response := tt.responses[len(tt.responses)-1]
+				if calls < len(tt.responses) {
+					response = tt.responses[calls]
+				}
+				calls++
+				return http.StatusOK, `{"services": [{"serviceName": "web", "deployments": [` + response + `]}]}`
+			})
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			polls := 0
+			_, err := client.WaitForDeployment(ctx, "prod", "web", "ecs-svc/2", WaitOptions{
+				Interval:       time.Millisecond,
+				TaskDefinition: "my-app:8",
+				Progress:       func(*Service, *Deployment) { polls++ },
+			})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("WaitForDeployment() error = %v", err)
+				}
+				if polls != len(tt.responses) {
+					t.Errorf("WaitForDeployment() polled %d times, expected %d", polls, len(tt.responses))
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("WaitForDeployment() error = %v, expected %q", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrRolledBack) != tt.rollback {
+				t.Errorf("errors.Is(err, ErrRolledBack) = %v, expected %v", !tt.rollback, tt.rollback)
+			}
+			if errors.Is(err, ErrSuperseded) != tt.superseded {
+				t.Errorf("errors.Is(err, ErrSuperseded) = %v, expected %v", !tt.superseded, tt.superseded)
+			}
+		})
+	}
+}
+
+func TestListServicesAndDescribeServices(t *testing.T) {
+	client := newTestClient(t, func(operation string, body map[string]interface{}) (int, string) {
+		switch operation {
+		case "ListServices":
+			if body["nextToken"] == nil {
+				return http.StatusOK, `{"serviceArns": ["arn:aws:ecs:us-east-1:123456789012:service/prod/web"], "nextToken": "page2"}`
+			}
+			return http.StatusOK, `{"serviceArns": ["arn:aws:ecs:us-east-1:123456789012:service/prod/worker"]}`
+		case "DescribeServices":
+			if services, _ := body["services"].([]interface{}); len(services) != 2 {
+				t.Errorf("unexpected DescribeServices request %v", body)
+			}
+			return http.StatusOK, `{"services": [
+  {"serviceName": "web", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8", "deployments": [
+    {"id": "ecs-svc/2", "status": "PRIMARY", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8"},
+    {"id": "ecs-svc/1", "status": "ACTIVE", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7"}
+  ]},
+  {"serviceName": "worker", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:3", "taskSets": [
+    {"id": "ecs-svc/3", "status": "PRIMARY", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:3"},
+    {"id": "ecs-svc/4", "status": "ACTIVE", "taskDefinition": "arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:4"}
+  ]}
+]}`
+		default:
+			t.Errorf("unexpected operation %s", operation)
+			return http.StatusBadRequest, `{}`
+		}
+	})
+
+	ctx := context.Background()
+	arns, err := client.ListServices(ctx, "prod")
+	if err != nil {
+		t.Fatalf("ListServices() error = %v", err)
+	}
+	services, err := client.DescribeServices(ctx, "prod", arns)
+	if err != nil {
+		t.Fatalf("DescribeServices() error = %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("DescribeServices() = %+v", services)
+	}
+	expected := "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8,arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8,arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:7"
+	if tds := strings.Join(services[0].TaskDefinitions(), ","); tds != expected {
+		t.Errorf("TaskDefinitions() = %s", tds)
+	}
+	// Services using the CODE_DEPLOY or EXTERNAL deployment controller have task sets
+	expected = "arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:3,arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:3,arn:aws:ecs:us-east-1:123456789012:task-definition/my-worker:4"
+	if tds := strings.Join(services[1].TaskDefinitions(), ","); tds != expected {
+		t.Errorf("TaskDefinitions() = %s", tds)
+	}
+}
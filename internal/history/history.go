@@ -0,0 +1,200 @@
+// Package history records the image changes made by ecs-tag-shift in a JSON Lines audit log
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvAuditLog is the environment variable naming the audit log used when no path is given
+const EnvAuditLog = "ECS_TAG_SHIFT_AUDIT_LOG"
+
+// Commands that record entries
+const (
+	CommandShift    = "shift"
+	CommandRollback = "rollback"
+)
+
+// Change is an image change of a single container
+type Change struct {
+	// Resource is the resource of an embedded document the container belongs to (empty otherwise)
+	Resource  string `json:"resource,omitempty"`
+	Container string `json:"container"`
+	OldImage  string `json:"oldImage"`
+	NewImage  string `json:"newImage"`
+	// Tag is the tag the image was shifted to, which --latest-matching may choose per repository
+	Tag string `json:"tag,omitempty"`
+}
+
+// Entry records the changes one command made to a document
+type Entry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user,omitempty"`
+	Command string    `json:"command"`
+	// Source is the absolute path of the file or the ecs:// name the document was read from, or
+	// empty for stdin
+	Source string `json:"source,omitempty"`
+	// File is the absolute path of the file the result was written back to, the ARN of the task
+	// definition it was registered as, or empty if it was written to stdout
+	File string `json:"file,omitempty"`
+	// Tag is the tag of every change, or empty if they were shifted to different tags
+	Tag string `json:"tag,omitempty"`
+	// InputHash and OutputHash are the digests of the bytes read and written (see Digest)
+	InputHash  string   `json:"inputHash,omitempty"`
+	OutputHash string   `json:"outputHash,omitempty"`
+	Changes    []Change `json:"changes"`
+}
+
+// Append appends an entry to an audit log, creating it if needed
+func Append(path string, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit log entry: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Load reads every entry of an audit log in the order they were appended. A missing file has
+// no entries.
+func Load(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid audit log entry: %w", path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// PreviousImage returns the image a container had before it was shifted to its current image,
+// according to the shift entries of a file. Rollbacks are skipped so that repeated rollbacks keep
+// going back.
+func PreviousImage(entries []Entry, file, container, current string) (string, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.File != file || e.Command != CommandShift {
+			continue
+		}
+		for _, c := range e.Changes {
+			if c.Container == container && c.NewImage == current {
+				return c.OldImage, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Digest returns the SHA-256 digest of data as sha256:<hex>
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// CurrentUser returns the name of the user running the command, or "" if it is unknown
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// Filter selects the changes of a query. Empty fields match everything.
+type Filter struct {
+	// File matches the source or the written file of an entry (an absolute path or ecs:// name)
+	File string
+	// Container matches the container name of a change
+	Container string
+	// Image matches changes whose old or new image contains it
+	Image string
+	// Since and Until bound the time of an entry (inclusive)
+	Since time.Time
+	Until time.Time
+}
+
+// Query returns the entries with changes matching a filter, oldest first. Changes of an entry
+// that do not match are left out.
+func Query(entries []Entry, f Filter) []Entry {
+	var matched []Entry
+	for _, e := range entries {
+		if f.File != "" && e.File != f.File && e.Source != f.File {
+			continue
+		}
+		if (!f.Since.IsZero() && e.Time.Before(f.Since)) || (!f.Until.IsZero() && e.Time.After(f.Until)) {
+			continue
+		}
+		var changes []Change
+		for _, c := range e.Changes {
+			if f.Container != "" && c.Container != f.Container {
+				continue
+			}
+			if f.Image != "" && !strings.Contains(c.OldImage, f.Image) && !strings.Contains(c.NewImage, f.Image) {
+				continue
+			}
+			changes = append(changes, c)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		e.Changes = changes
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+// AbsPath returns the absolute path recorded for a file, or "" for stdin
+func AbsPath(file string) string {
+	if file == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	return abs
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+
+	entries, err := Load(path)
+	if err != nil || entries != nil {
+		t.Fatalf("Load() of a missing file = %v, %v", entries, err)
+	}
+
+	first := Entry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Command: CommandShift, File: "/app/task.json", Tag: "v2",
+		Changes: []Change{{Container: "web", OldImage: "my-app:v1", NewImage: "my-app:v2"}}}
+	second := Entry{Time: first.Time.Add(time.Hour), Command: CommandRollback, File: "/app/task.json",
+		Changes: []Change{{Container: "web", OldImage: "my-app:v2", NewImage: "my-app:v1"}}}
+	for _, e := range []Entry{first, second} {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Tag != "v2" || !entries[0].Time.Equal(first.Time) || entries[1].Command != CommandRollback {
+		t.Errorf("Load() = %+v", entries)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte("{\"command\": \"shift\"}\n\nnot json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "audit.jsonl:3: invalid audit log entry") {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestPreviousImage(t *testing.T) {
+	entries := []Entry{
+		{Command: CommandShift, File: "/app/task.json", Changes: []Change{{Container: "web", OldImage: "my-app:v1", NewImage: "my-app:v2"}}},
+		{Command: CommandShift, File: "/app/other.json", Changes: []Change{{Container: "web", OldImage: "my-app:v0", NewImage: "my-app:v3"}}},
+		{Command: CommandShift, File: "/app/task.json", Changes: []Change{{Container: "web", OldImage: "my-app:v2", NewImage: "my-app:v3"}}},
+		{Command: CommandRollback, File: "/app/task.json", Changes: []Change{{Container: "web", OldImage: "my-app:v3", NewImage: "my-app:v2"}}},
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		container string
+		current   string
+		expected  string
+		ok        bool
+	}{
+		{name: "Latest shift", file: "/app/task.json", container: "web", current: "my-app:v3", expected: "my-app:v2", ok: true},
+		{name: "After a rollback", file: "/app/task.json", container: "web", current: "my-app:v2", expected: "my-app:v1", ok: true},
+		{name: "Oldest image", file: "/app/task.json", container: "web", current: "my-app:v1"},
+		{name: "Other file", file: "/app/other.json", container: "web", current: "my-app:v3", expected: "my-app:v0", ok: true},
+		{name: "Other container", file: "/app/task.json", container: "sidecar", current: "my-app:v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, ok := PreviousImage(entries, tt.file, tt.container, tt.current)
+			if image != tt.expected || ok != tt.ok {
+				t.Errorf("PreviousImage() = %q, %v, expected %q, %v", image, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	entries := []Entry{
+		{Time: day(1), Command: CommandShift, File: "/app/task.json", Source: "/app/task.json", Changes: []Change{
+			{Container: "web", OldImage: "my-app:v1.4.1", NewImage: "my-app:v1.4.2"},
+			{Container: "log", OldImage: "fluent-bit:2", NewImage: "fluent-bit:3"},
+		}},
+		{Time: day(2), Command: CommandShift, Source: "ecs://my-app", Changes: []Change{
+			{Container: "web", OldImage: "my-app:v1.4.2", NewImage: "my-app:v1.5.0"},
+		}},
+		{Time: day(3), Command: CommandRollback, File: "/app/task.json", Source: "/app/task.json", Changes: []Change{
+			{Container: "web", OldImage: "my-app:v1.4.2", NewImage: "my-app:v1.4.1"},
+		}},
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected string
+	}{
+		{name: "All", filter: Filter{}, expected: "web,log|web|web"},
+		{name: "File", filter: Filter{File: "/app/task.json"}, expected: "web,log|web"},
+		{name: "ECS source", filter: Filter{File: "ecs://my-app"}, expected: "web"},
+		{name: "Container", filter: Filter{Container: "log"}, expected: "log"},
+		{name: "Image", filter: Filter{Image: "v1.4.2"}, expected: "web|web|web"},
+		{name: "Date range", filter: Filter{Since: day(2), Until: day(3)}, expected: "web|web"},
+		{name: "No match", filter: Filter{Container: "db"}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range Query(entries, tt.filter) {
+				var containers []string
+				for _, c := range e.Changes {
+					containers = append(containers, c.Container)
+				}
+				got = append(got, strings.Join(containers, ","))
+			}
+			if strings.Join(got, "|") != tt.expected {
+				t.Errorf("Query() = %v, expected %s", got, tt.expected)
+			}
+		})
+	}
+	if len(entries[0].Changes) != 2 {
+		t.Errorf("Query() modified its input")
+	}
+}
+
+func TestDigest(t *testing.T) {
+	if d := Digest([]byte("")); d != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("Digest() = %s", d)
+	}
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+)
+
+// DeploymentProgress writes the progress of an ECS deployment as text. The state of the deployment
+// is written when it changes, and each new service event is written once, oldest first.
+type DeploymentProgress struct {
+	w         io.Writer
+	lastState string
+	seen      map[string]bool
+}
+
+// NewDeploymentProgress creates a progress writer. Events in known, such as the events the service
+// had before the deployment started, are not written.
+func NewDeploymentProgress(w io.Writer, known []ecs.ServiceEvent) *DeploymentProgress {
+	p := &DeploymentProgress{w: w, seen: make(map[string]bool)}
+	for _, e := range known {
+		p.seen[e.ID] = true
+	}
+	return p
+}
+
+// Registered writes the ARN of the registered task definition
+func (p *DeploymentProgress) Registered(arn string) error {
+	_, err := fmt.Fprintf(p.w, "Registered %s\n", arn)
+	return err
+}
+
+// ServiceUpdated writes the deployment an UpdateService call started. The events the service
+// already had are not written.
+func (p *DeploymentProgress) ServiceUpdated(cluster string, svc *ecs.Service, d *ecs.Deployment) error {
+	for _, e := range svc.Events {
+		p.seen[e.ID] = true
+	}
+	_, err := fmt.Fprintf(p.w, "Updated service %s in cluster %s (deployment %s)\n", svc.ServiceName, cluster, d.ID)
+	return err
+}
+
+// Update writes the new events of a service and the state of a deployment, which may be nil if
+// the service no longer has it
+func (p *DeploymentProgress) Update(svc *ecs.Service, d *ecs.Deployment) error {
+	for i := len(svc.Events) - 1; i >= 0; i-- {
+		e := svc.Events[i]
+		if p.seen[e.ID] {
+			continue
+		}
+		p.seen[e.ID] = true
+		if _, err := fmt.Fprintf(p.w, "%s %s\n", e.Time().UTC().Format(time.RFC3339), e.Message); err != nil {
+			return err
+		}
+	}
+
+	if d == nil {
+		return nil
+	}
+	state := d.RolloutState
+	if state == "" {
+		state = d.Status
+	}
+	line := fmt.Sprintf("deployment %s: %s, %d/%d running, %d pending, %d failed", d.ID, state, d.RunningCount, d.DesiredCount, d.PendingCount, d.FailedTasks)
+	if line == p.lastState {
+		return nil
+	}
+	p.lastState = line
+	_, err := fmt.Fprintln(p.w, line)
+	return err
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+)
+
+func TestDeploymentProgress(t *testing.T) {
+	var buf bytes.Buffer
+	old := ecs.ServiceEvent{ID: "e1", CreatedAt: 1700000000, Message: "(service web) has reached a steady state."}
+	older := ecs.ServiceEvent{ID: "e0", CreatedAt: 1699990000, Message: "(service web) has started 1 tasks."}
+	progress := NewDeploymentProgress(&buf, []ecs.ServiceEvent{old})
+
+	d := &ecs.Deployment{ID: "ecs-svc/2", RolloutState: "IN_PROGRESS", DesiredCount: 2, RunningCount: 1, PendingCount: 1}
+	if err := progress.Registered("arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8"); err != nil {
+		t.Fatalf("Registered() error = %v", err)
+	}
+	if err := progress.ServiceUpdated("prod", &ecs.Service{ServiceName: "web", Events: []ecs.ServiceEvent{older}}, d); err != nil {
+		t.Fatalf("ServiceUpdated() error = %v", err)
+	}
+	svc := &ecs.Service{Events: []ecs.ServiceEvent{
+		{ID: "e3", CreatedAt: 1700000060, Message: "(service web) registered 1 targets."},
+		{ID: "e2", CreatedAt: 1700000030, Message: "(service web) has started 2 tasks."},
+		old,
+		older,
+	}}
+	for i := 0; i < 2; i++ {
+		if err := progress.Update(svc, d); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+	d.RolloutState = "COMPLETED"
+	d.RunningCount, d.PendingCount = 2, 0
+	if err := progress.Update(svc, d); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	expected := `Registered arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:8
+Updated service web in cluster prod (deployment ecs-svc/2)
+2023-11-14T22:13:50Z (service web) has started 2 tasks.
+2023-11-14T22:14:20Z (service web) registered 1 targets.
+deployment ecs-svc/2: IN_PROGRESS, 1/2 running, 1 pending, 0 failed
+deployment ecs-svc/2: COMPLETED, 2/2 running, 0 pending, 0 failed
+`
+	if buf.String() != expected {
+		t.Errorf("DeploymentProgress output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
 	"gopkg.in/yaml.v3"
@@ -17,6 +18,8 @@ const (
 	FormatJSON OutputFormat = "json"
 	FormatYAML OutputFormat = "yaml"
 	FormatText OutputFormat = "text"
+	// FormatSARIF is only supported for lint findings and validation errors
+	FormatSARIF OutputFormat = "sarif"
 )
 
 // FormatTaskDefinition formats a task definition for output
@@ -51,6 +54,7 @@ func FormatContainerDefinitions(w io.Writer, containers []taskdef.ContainerDefin
 func formatTaskDefinitionJSON(w io.Writer, taskDef *taskdef.TaskDefinition, showAll bool) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
 
 	if showAll {
 		return encoder.Encode(taskDef)
@@ -109,6 +113,11 @@ func formatTaskDefinitionText(w io.Writer, taskDef *taskdef.TaskDefinition, show
 			return err
 		}
 	}
+	if showAll {
+		if err := formatTaskDefinitionDetailsText(w, taskDef); err != nil {
+			return err
+		}
+	}
 	if _, err := fmt.Fprintln(w); err != nil {
 		return err
 	}
@@ -123,10 +132,122 @@ func formatTaskDefinitionText(w io.Writer, taskDef *taskdef.TaskDefinition, show
 	return nil
 }
 
+// formatTaskDefinitionDetailsText formats the task-level fields of a task definition as text.
+// Empty fields are omitted.
+func formatTaskDefinitionDetailsText(w io.Writer, taskDef *taskdef.TaskDefinition) error {
+	fields := [][2]string{
+		{"Task Role", taskDef.TaskRoleArn},
+		{"Execution Role", taskDef.ExecutionRoleArn},
+		{"Network Mode", taskDef.NetworkMode},
+		{"Requires Compatibilities", strings.Join(taskDef.RequiresCompatibilities, ", ")},
+		{"CPU", taskDef.CPU},
+		{"Memory", taskDef.Memory},
+		{"PID Mode", taskDef.PidMode},
+		{"IPC Mode", taskDef.IpcMode},
+	}
+	if p := taskDef.RuntimePlatform; p != nil {
+		fields = append(fields, [2]string{"Runtime Platform", strings.Trim(p.OperatingSystemFamily+"/"+p.CPUArchitecture, "/")})
+	}
+	if e := taskDef.EphemeralStorage; e != nil {
+		fields = append(fields, [2]string{"Ephemeral Storage", fmt.Sprintf("%d GiB", e.SizeInGiB)})
+	}
+	for _, f := range fields {
+		if f[1] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", f[0], f[1]); err != nil {
+			return err
+		}
+	}
+
+	var volumes []string
+	for _, v := range taskDef.Volumes {
+		volumes = append(volumes, fmt.Sprintf("%s: %s", v.Name, describeVolume(v)))
+	}
+	var constraints []string
+	for _, c := range taskDef.PlacementConstraints {
+		constraints = append(constraints, strings.TrimSpace(c.Type+" "+c.Expression))
+	}
+	var proxy []string
+	if p := taskDef.ProxyConfiguration; p != nil {
+		proxy = append(proxy, fmt.Sprintf("%s (container: %s)", p.Type, p.ContainerName))
+		for _, prop := range p.Properties {
+			proxy = append(proxy, fmt.Sprintf("%s: %s", prop.Name, prop.Value))
+		}
+	}
+	var accelerators []string
+	for _, a := range taskDef.InferenceAccelerators {
+		accelerators = append(accelerators, fmt.Sprintf("%s: %s", a.DeviceName, a.DeviceType))
+	}
+	var tags []string
+	for _, t := range taskDef.Tags {
+		tags = append(tags, fmt.Sprintf("%s: %s", t.Key, t.Value))
+	}
+
+	sections := []struct {
+		title string
+		items []string
+	}{
+		{"Volumes", volumes},
+		{"Placement Constraints", constraints},
+		{"Proxy Configuration", proxy},
+		{"Inference Accelerators", accelerators},
+		{"Tags", tags},
+	}
+	for _, section := range sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s:\n", section.title); err != nil {
+			return err
+		}
+		for _, item := range section.items {
+			if _, err := fmt.Fprintf(w, "  - %s\n", item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describeVolume describes the type and source of a volume
+func describeVolume(v taskdef.Volume) string {
+	switch {
+	case v.EFSVolumeConfiguration != nil:
+		c := v.EFSVolumeConfiguration
+		desc := "efs " + c.FileSystemID
+		if c.RootDirectory != "" {
+			desc += ":" + c.RootDirectory
+		}
+		if c.AuthorizationConfig != nil && c.AuthorizationConfig.AccessPointID != "" {
+			desc += " (access point " + c.AuthorizationConfig.AccessPointID + ")"
+		}
+		return desc
+	case v.FSxWindowsFileServerVolumeConfiguration != nil:
+		c := v.FSxWindowsFileServerVolumeConfiguration
+		return "fsx " + c.FileSystemID + ":" + c.RootDirectory
+	case v.DockerVolumeConfiguration != nil:
+		c := v.DockerVolumeConfiguration
+		desc := "docker"
+		if c.Driver != "" {
+			desc += " " + c.Driver
+		}
+		if c.Scope != "" {
+			desc += " (scope: " + c.Scope + ")"
+		}
+		return desc
+	case v.Host != nil && v.Host.SourcePath != "":
+		return "bind " + v.Host.SourcePath
+	default:
+		return "bind"
+	}
+}
+
 // formatContainerDefinitionsJSON formats container definitions as JSON
 func formatContainerDefinitionsJSON(w io.Writer, containers []taskdef.ContainerDefinition, showAll bool) error {
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
 
 	if showAll {
 		return encoder.Encode(containers)
@@ -186,31 +307,36 @@ func formatContainerDefinitionsText(w io.Writer, containers []taskdef.ContainerD
 
 // FormatTaskDefinitionFull formats a full task definition (for shift command output)
 func FormatTaskDefinitionFull(w io.Writer, taskDef *taskdef.TaskDefinition, format OutputFormat) error {
-	switch format {
-	case FormatJSON:
-		encoder := json.NewEncoder(w)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(taskDef)
-	case FormatYAML:
-		encoder := yaml.NewEncoder(w)
-		defer func() {
-			if err := encoder.Close(); err != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to close YAML encoder: %v\n", err)
-			}
-		}()
-		return encoder.Encode(taskDef)
-	default:
-		return fmt.Errorf("unsupported output format: %s", format)
-	}
+	return taskdef.Encode(w, taskDef, taskdef.Encoding(format))
 }
 
 // FormatContainerDefinitionsFull formats full container definitions (for shift command output)
 func FormatContainerDefinitionsFull(w io.Writer, containers []taskdef.ContainerDefinition, format OutputFormat) error {
+	return taskdef.Encode(w, containers, taskdef.Encoding(format))
+}
+
+// FormatDocument formats a summary of a document (for show command output)
+func FormatDocument(w io.Writer, doc taskdef.Document, format OutputFormat, showAll bool) error {
+	switch d := doc.(type) {
+	case *taskdef.TaskDocument:
+		return FormatTaskDefinition(w, d.TaskDefinition, format, showAll)
+	case *taskdef.ContainerDocument:
+		return FormatContainerDefinitions(w, d.ContainerDefinitions, format, showAll)
+	case *taskdef.EmbeddedDocument:
+		return FormatResources(w, d.Resources(), format)
+	default:
+		return fmt.Errorf("unsupported document mode: %s", doc.Mode())
+	}
+}
+
+// FormatResources formats the containers of an embedded document (e.g. CloudFormation template) for output
+func FormatResources(w io.Writer, resources []taskdef.Resource, format OutputFormat) error {
 	switch format {
 	case FormatJSON:
 		encoder := json.NewEncoder(w)
 		encoder.SetIndent("", "  ")
-		return encoder.Encode(containers)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(simplifyResources(resources))
 	case FormatYAML:
 		encoder := yaml.NewEncoder(w)
 		defer func() {
@@ -218,8 +344,43 @@ func FormatContainerDefinitionsFull(w io.Writer, containers []taskdef.ContainerD
 				fmt.Fprintf(os.Stderr, "warning: failed to close YAML encoder: %v\n", err)
 			}
 		}()
-		return encoder.Encode(containers)
+		return encoder.Encode(simplifyResources(resources))
+	case FormatText:
+		return formatResourcesText(w, resources)
 	default:
 		return fmt.Errorf("unsupported output format: %s", format)
 	}
 }
+
+// simplifyResources maps resource names to their container images
+func simplifyResources(resources []taskdef.Resource) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, r := range resources {
+		containers := make(map[string]string)
+		for _, c := range r.Containers {
+			containers[c.Name] = c.Image
+		}
+		result[r.Name] = map[string]interface{}{"containers": containers}
+	}
+	return map[string]interface{}{"resources": result}
+}
+
+// formatResourcesText formats the containers of an embedded document as text
+func formatResourcesText(w io.Writer, resources []taskdef.Resource) error {
+	for i, r := range resources {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s:\n", r.Name); err != nil {
+			return err
+		}
+		for _, c := range r.Containers {
+			if _, err := fmt.Fprintf(w, "  - %s: %s\n", c.Name, c.Image); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
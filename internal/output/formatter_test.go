@@ -197,3 +197,111 @@ func TestFormatContainerDefinitionsFull(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatResources(t *testing.T) {
+	resources := []taskdef.Resource{
+		{Name: "AppTaskDefinition", Containers: []taskdef.ContainerDefinition{{Name: "web", Image: "nginx:latest"}}},
+		{Name: "WorkerTaskDefinition", Containers: []taskdef.ContainerDefinition{{Name: "worker", Image: "worker:v1.0"}}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := FormatResources(buf, resources, FormatJSON); err != nil {
+		t.Errorf("FormatResources() error = %v", err)
+		return
+	}
+
+	var result map[string]map[string]map[string]map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Errorf("Result is not valid JSON: %v", err)
+		return
+	}
+	if result["resources"]["WorkerTaskDefinition"]["containers"]["worker"] != "worker:v1.0" {
+		t.Errorf("FormatResources() worker image = %v, expected worker:v1.0", result)
+	}
+
+	buf.Reset()
+	if err := FormatResources(buf, resources, FormatText); err != nil {
+		t.Errorf("FormatResources() error = %v", err)
+		return
+	}
+	if !strings.Contains(buf.String(), "AppTaskDefinition:\n  - web: nginx:latest") {
+		t.Errorf("FormatResources() text output = %q", buf.String())
+	}
+}
+
+func TestFormatTaskDefinitionFullKeepsPlaceholders(t *testing.T) {
+	td := &taskdef.TaskDefinition{
+		Family: "my-app",
+		ContainerDefinitions: []taskdef.ContainerDefinition{
+			{Name: "web", Image: "<IMAGE1_NAME>"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := FormatTaskDefinitionFull(buf, td, FormatJSON); err != nil {
+		t.Errorf("FormatTaskDefinitionFull() error = %v", err)
+		return
+	}
+	if !strings.Contains(buf.String(), `"image": "<IMAGE1_NAME>"`) {
+		t.Errorf("FormatTaskDefinitionFull() escaped the placeholder: %s", buf.String())
+	}
+}
+
+func TestFormatTaskDefinitionTextShowAll(t *testing.T) {
+	td := &taskdef.TaskDefinition{
+		Family:      "my-app",
+		NetworkMode: "awsvpc",
+		CPU:         "256",
+		RuntimePlatform: &taskdef.RuntimePlatform{
+			CPUArchitecture:       "ARM64",
+			OperatingSystemFamily: "LINUX",
+		},
+		EphemeralStorage: &taskdef.EphemeralStorage{SizeInGiB: 30},
+		Volumes: []taskdef.Volume{
+			{Name: "data", EFSVolumeConfiguration: &taskdef.EFSVolumeConfiguration{FileSystemID: "fs-1234", RootDirectory: "/data"}},
+			{Name: "scratch"},
+		},
+		ProxyConfiguration: &taskdef.ProxyConfiguration{
+			Type:          "APPMESH",
+			ContainerName: "envoy",
+			Properties:    []taskdef.KeyValuePair{{Name: "ProxyIngressPort", Value: "15000"}},
+		},
+		Tags: []taskdef.Tag{{Key: "team", Value: "platform"}},
+		ContainerDefinitions: []taskdef.ContainerDefinition{
+			{Name: "web", Image: "nginx:latest"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := formatTaskDefinitionText(buf, td, true); err != nil {
+		t.Fatalf("formatTaskDefinitionText() error = %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{
+		"Network Mode: awsvpc",
+		"CPU: 256",
+		"Runtime Platform: LINUX/ARM64",
+		"Ephemeral Storage: 30 GiB",
+		"  - data: efs fs-1234:/data",
+		"  - scratch: bind",
+		"  - APPMESH (container: envoy)",
+		"  - ProxyIngressPort: 15000",
+		"  - team: platform",
+		"  - web: nginx:latest",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("formatTaskDefinitionText() output does not contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Memory:") {
+		t.Errorf("formatTaskDefinitionText() output should omit empty fields:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := formatTaskDefinitionText(buf, td, false); err != nil {
+		t.Fatalf("formatTaskDefinitionText() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "Volumes:") {
+		t.Errorf("formatTaskDefinitionText() should only show task-level fields with showAll:\n%s", buf.String())
+	}
+}
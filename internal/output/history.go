@@ -0,0 +1,67 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+)
+
+// FormatHistory formats audit log entries for output. The text format is a table with a row per
+// image change.
+func FormatHistory(w io.Writer, entries []history.Entry, format OutputFormat) error {
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(entries)
+	case FormatText:
+		if len(entries) == 0 {
+			_, err := fmt.Fprintln(w, "No changes found")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(tw, "TIME\tUSER\tCOMMAND\tFILE\tCONTAINER\tCHANGE"); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			for _, c := range e.Changes {
+				container := c.Container
+				if c.Resource != "" {
+					container = c.Resource + "/" + c.Container
+				}
+				if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s -> %s\n",
+					e.Time.UTC().Format(time.RFC3339), orDash(e.User), e.Command, orDash(historyFile(e)), container, c.OldImage, c.NewImage); err != nil {
+					return err
+				}
+			}
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// historyFile returns the file an entry changed, or else the input it was read from
+func historyFile(e history.Entry) string {
+	if e.File != "" {
+		return e.File
+	}
+	return e.Source
+}
+
+// orDash returns s, or "-" if it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/history"
+)
+
+func TestFormatHistoryText(t *testing.T) {
+	entries := []history.Entry{
+		{Time: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), User: "alice", Command: history.CommandShift, File: "/app/task.json", Changes: []history.Change{
+			{Container: "web", OldImage: "my-app:v1.4.1", NewImage: "my-app:v1.4.2"},
+		}},
+		{Time: time.Date(2024, 1, 16, 9, 30, 0, 0, time.UTC), Command: history.CommandShift, Source: "ecs://my-app", Changes: []history.Change{
+			{Resource: "TaskDef", Container: "web", OldImage: "my-app:v1.4.2", NewImage: "my-app:v1.5.0"},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := FormatHistory(&buf, entries, FormatText); err != nil {
+		t.Fatalf("FormatHistory() error = %v", err)
+	}
+	expected := `TIME                  USER   COMMAND  FILE            CONTAINER    CHANGE
+2024-01-15T10:00:00Z  alice  shift    /app/task.json  web          my-app:v1.4.1 -> my-app:v1.4.2
+2024-01-16T09:30:00Z  -      shift    ecs://my-app    TaskDef/web  my-app:v1.4.2 -> my-app:v1.5.0
+`
+	if buf.String() != expected {
+		t.Errorf("FormatHistory() =\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatLintFindings formats lint findings for output. rules and source are only used by the
+// SARIF format to describe the rules that ran and locate the findings in the file.
+func FormatLintFindings(w io.Writer, findings []taskdef.LintFinding, rules []taskdef.LintRule, source Source, format OutputFormat) error {
+	if findings == nil {
+		findings = []taskdef.LintFinding{}
+	}
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(findings)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		defer func() {
+			if err := encoder.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to close YAML encoder: %v\n", err)
+			}
+		}()
+		return encoder.Encode(findings)
+	case FormatSARIF:
+		return formatLintFindingsSARIF(w, findings, rules, source)
+	case FormatText:
+		if len(findings) == 0 {
+			_, err := fmt.Fprintln(w, "No lint findings")
+			return err
+		}
+		for _, f := range findings {
+			if _, err := fmt.Fprintln(w, f.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
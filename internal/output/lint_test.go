@@ -0,0 +1,105 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestFormatLintFindings(t *testing.T) {
+	findings := []taskdef.LintFinding{
+		{Rule: "image-tag", Severity: taskdef.SeverityWarning, Path: "$.containerDefinitions[0].image", Message: "image 'nginx:latest' uses the latest tag"},
+		{Rule: "readonly-root-filesystem", Severity: taskdef.SeverityInfo, Path: "$.containerDefinitions[0]", Message: "container 'web' does not set readonlyRootFilesystem to true"},
+	}
+
+	source := Source{
+		URI: "task-definition.json",
+		Positions: taskdef.Positions{
+			"$":                               {Line: 1, Column: 1},
+			"$.containerDefinitions[0]":       {Line: 4, Column: 5},
+			"$.containerDefinitions[0].image": {Line: 6, Column: 7},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		findings []taskdef.LintFinding
+		format   OutputFormat
+		check    func(t *testing.T, out string)
+	}{
+		{
+			name:     "Text output",
+			findings: findings,
+			format:   FormatText,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, "$.containerDefinitions[0].image: warning: image 'nginx:latest' uses the latest tag [image-tag]") {
+					t.Errorf("unexpected text output: %s", out)
+				}
+			},
+		},
+		{
+			name:     "Text output without findings",
+			findings: nil,
+			format:   FormatText,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, "No lint findings") {
+					t.Errorf("unexpected text output: %s", out)
+				}
+			},
+		},
+		{
+			name:     "JSON output",
+			findings: findings,
+			format:   FormatJSON,
+			check: func(t *testing.T, out string) {
+				var got []taskdef.LintFinding
+				if err := json.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatalf("failed to parse JSON output: %v", err)
+				}
+				if len(got) != 2 || got[1].Severity != taskdef.SeverityInfo {
+					t.Errorf("unexpected JSON output: %s", out)
+				}
+			},
+		},
+		{
+			name:     "SARIF output",
+			findings: findings,
+			format:   FormatSARIF,
+			check: func(t *testing.T, out string) {
+				var got sarifLog
+				if err := json.Unmarshal([]byte(out), &got); err != nil {
+					t.Fatalf("failed to parse SARIF output: %v", err)
+				}
+				if got.Version != "2.1.0" || len(got.Runs) != 1 {
+					t.Fatalf("unexpected SARIF log: %s", out)
+				}
+				run := got.Runs[0]
+				if len(run.Tool.Driver.Rules) != len(taskdef.DefaultLintRules()) {
+					t.Errorf("expected every rule to be described, got %d", len(run.Tool.Driver.Rules))
+				}
+				if len(run.Results) != 2 || run.Results[0].Level != "warning" || run.Results[1].Level != "note" {
+					t.Errorf("unexpected SARIF results: %+v", run.Results)
+				}
+				if loc := run.Results[0].Locations[0].PhysicalLocation; loc.ArtifactLocation.URI != "task-definition.json" || loc.Region == nil || loc.Region.StartLine != 6 || loc.Region.StartColumn != 7 {
+					t.Errorf("unexpected SARIF location: %+v", loc)
+				}
+				if region := run.Results[1].Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 4 {
+					t.Errorf("unexpected SARIF region for container finding: %+v", region)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := FormatLintFindings(&buf, tt.findings, taskdef.DefaultLintRules(), source, tt.format); err != nil {
+				t.Fatalf("FormatLintFindings() error = %v", err)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+)
+
+// FormatPrunePlan formats the revisions of a prune plan for output. The text format is a table of
+// the revisions followed by a summary of the actions.
+func FormatPrunePlan(w io.Writer, plan []ecs.PruneRevision, format OutputFormat) error {
+	if plan == nil {
+		plan = []ecs.PruneRevision{}
+	}
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(plan)
+	case FormatText:
+		if len(plan) == 0 {
+			_, err := fmt.Fprintln(w, "No revisions found")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(tw, "REVISION\tSTATUS\tACTION\tREASON"); err != nil {
+			return err
+		}
+		counts := make(map[string]int)
+		for _, r := range plan {
+			counts[r.Action]++
+			if _, err := fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", r.Revision, r.Status, r.Action, r.Reason); err != nil {
+				return err
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "\n%d to keep, %d to deregister, %d to delete\n", counts[ecs.PruneKeep], counts[ecs.PruneDeregister], counts[ecs.PruneDelete])
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
@@ -0,0 +1,30 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/ecs"
+)
+
+func TestFormatPrunePlanText(t *testing.T) {
+	plan := []ecs.PruneRevision{
+		{Revision: 12, Status: "ACTIVE", Action: ecs.PruneKeep, Reason: "latest 1; in use by prod/web"},
+		{Revision: 11, Status: "ACTIVE", Action: ecs.PruneDeregister},
+		{Revision: 3, Status: "INACTIVE", Action: ecs.PruneDelete},
+	}
+	var buf bytes.Buffer
+	if err := FormatPrunePlan(&buf, plan, FormatText); err != nil {
+		t.Fatalf("FormatPrunePlan() error = %v", err)
+	}
+	expected := `REVISION  STATUS    ACTION      REASON
+12        ACTIVE    keep        latest 1; in use by prod/web
+11        ACTIVE    deregister  
+3         INACTIVE  delete      
+
+1 to keep, 1 to deregister, 1 to delete
+`
+	if buf.String() != expected {
+		t.Errorf("FormatPrunePlan() =\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,185 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "ecs-tag-shift"
+)
+
+// Source describes the file that findings are reported against
+type Source struct {
+	// URI is the file name, or empty for stdin
+	URI       string
+	Positions taskdef.Positions
+}
+
+// sarifLog represents the root of a SARIF 2.1.0 log
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifLevel maps a severity to a SARIF result level
+func sarifLevel(s taskdef.Severity) string {
+	switch s {
+	case taskdef.SeverityError:
+		return "error"
+	case taskdef.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifLocationFor locates a JSON path in the source
+func sarifLocationFor(path string, source Source) sarifLocation {
+	location := sarifLocation{LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: path}}}
+	if source.URI != "" {
+		location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: source.URI}}
+		if pos, ok := source.Positions.Lookup(path); ok {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: pos.Line, StartColumn: pos.Column}
+		}
+	}
+	return location
+}
+
+// formatLintFindingsSARIF writes lint findings as a SARIF 2.1.0 log
+func formatLintFindingsSARIF(w io.Writer, findings []taskdef.LintFinding, rules []taskdef.LintRule, source Source) error {
+	var sarifRules []sarifRule
+	for _, r := range rules {
+		sarifRules = append(sarifRules, sarifRule{
+			ID:                   r.ID,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+		})
+	}
+
+	var results []sarifResult
+	for _, f := range findings {
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{sarifLocationFor(f.Path, source)},
+		})
+	}
+
+	return writeSARIF(w, sarifRules, results)
+}
+
+// validationRuleID is the SARIF rule reported for validation errors
+const validationRuleID = "task-definition-constraints"
+
+// formatValidationErrorsSARIF writes validation errors as a SARIF 2.1.0 log
+func formatValidationErrorsSARIF(w io.Writer, errs []taskdef.ValidationError, source Source) error {
+	rules := []sarifRule{{
+		ID:                   validationRuleID,
+		ShortDescription:     sarifMessage{Text: "Task definitions must satisfy the ECS RegisterTaskDefinition constraints"},
+		DefaultConfiguration: sarifConfiguration{Level: "error"},
+	}}
+
+	var results []sarifResult
+	for _, e := range errs {
+		results = append(results, sarifResult{
+			RuleID:    validationRuleID,
+			Level:     "error",
+			Message:   sarifMessage{Text: e.Message},
+			Locations: []sarifLocation{sarifLocationFor(e.Path, source)},
+		})
+	}
+
+	return writeSARIF(w, rules, results)
+}
+
+// writeSARIF writes a SARIF 2.1.0 log with a single run
+func writeSARIF(w io.Writer, rules []sarifRule, results []sarifResult) error {
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{Name: toolName, Rules: rules}},
+			// Positions count characters, not UTF-16 code units
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(log)
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+	"gopkg.in/yaml.v3"
+)
+
+// FormatValidationErrors formats validation errors for output. source is only used by the SARIF
+// format to locate the errors in the file.
+func FormatValidationErrors(w io.Writer, errs []taskdef.ValidationError, source Source, format OutputFormat) error {
+	if errs == nil {
+		errs = []taskdef.ValidationError{}
+	}
+
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(errs)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		defer func() {
+			if err := encoder.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to close YAML encoder: %v\n", err)
+			}
+		}()
+		return encoder.Encode(errs)
+	case FormatSARIF:
+		return formatValidationErrorsSARIF(w, errs, source)
+	case FormatText:
+		if len(errs) == 0 {
+			_, err := fmt.Fprintln(w, "No validation errors found")
+			return err
+		}
+		for _, e := range errs {
+			if _, err := fmt.Fprintln(w, e.Error()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestFormatValidationErrors(t *testing.T) {
+	errs := []taskdef.ValidationError{
+		{Path: "$.containerDefinitions[0].image", Message: "is required"},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := FormatValidationErrors(buf, errs, Source{}, FormatText); err != nil {
+		t.Errorf("FormatValidationErrors() error = %v", err)
+		return
+	}
+	if !strings.Contains(buf.String(), "$.containerDefinitions[0].image: is required") {
+		t.Errorf("FormatValidationErrors() text output = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := FormatValidationErrors(buf, nil, Source{}, FormatJSON); err != nil {
+		t.Errorf("FormatValidationErrors() error = %v", err)
+		return
+	}
+	var result []taskdef.ValidationError
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil || result == nil || len(result) != 0 {
+		t.Errorf("FormatValidationErrors() JSON output for no errors = %q", buf.String())
+	}
+}
+
+func TestFormatValidationErrorsSARIF(t *testing.T) {
+	errs := []taskdef.ValidationError{
+		{Path: "$.containerDefinitions[0].image", Message: "is required"},
+		{Path: "$.family", Message: "is required"},
+	}
+	source := Source{
+		URI: "task-definition.json",
+		Positions: taskdef.Positions{
+			"$":                         {Line: 1, Column: 1},
+			"$.containerDefinitions[0]": {Line: 3, Column: 5},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := FormatValidationErrors(buf, errs, source, FormatSARIF); err != nil {
+		t.Fatalf("FormatValidationErrors() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 || results[0].Level != "error" || results[0].RuleID != validationRuleID {
+		t.Fatalf("unexpected SARIF results: %+v", results)
+	}
+	// Missing fields are reported at their closest ancestor
+	if region := results[0].Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 3 || region.StartColumn != 5 {
+		t.Errorf("unexpected region for missing image: %+v", region)
+	}
+	if region := results[1].Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 1 {
+		t.Errorf("unexpected region for missing family: %+v", region)
+	}
+}
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Credentials holds the credentials for a registry. The zero value means anonymous access.
+type Credentials struct {
+	Username string
+	Password string
+	// RegistryToken is sent as a bearer token as-is
+	RegistryToken string
+}
+
+// IsZero reports whether the credentials are empty
+func (c Credentials) IsZero() bool {
+	return c == Credentials{}
+}
+
+// CredentialStore looks up the credentials for a registry host
+type CredentialStore interface {
+	Credentials(host string) (Credentials, error)
+}
+
+// dockerConfigAuth is an entry of the "auths" section of a Docker config file
+type dockerConfigAuth struct {
+	Auth          string `json:"auth"`
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	RegistryToken string `json:"registrytoken"`
+}
+
+// DockerConfig is a CredentialStore backed by the "auths" section of a Docker config file
+type DockerConfig struct {
+	Auths map[string]dockerConfigAuth `json:"auths"`
+}
+
+// DefaultDockerConfigPath returns $DOCKER_CONFIG/config.json, or ~/.docker/config.json
+func DefaultDockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".docker", "config.json")
+}
+
+// LoadDockerConfig reads a Docker config file. A missing file yields an empty config, so that
+// every registry is accessed anonymously.
+func LoadDockerConfig(path string) (*DockerConfig, error) {
+	config := &DockerConfig{}
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read docker config: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Credentials returns the credentials stored for host, or empty credentials if there are none.
+// Keys such as "https://index.docker.io/v1/" are matched by their host.
+func (c *DockerConfig) Credentials(host string) (Credentials, error) {
+	for key, entry := range c.Auths {
+		if normalizeConfigHost(key) != host {
+			continue
+		}
+		creds := Credentials{Username: entry.Username, Password: entry.Password, RegistryToken: entry.RegistryToken}
+		if entry.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return Credentials{}, fmt.Errorf("invalid auth for %s in docker config: %w", key, err)
+			}
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return Credentials{}, fmt.Errorf("invalid auth for %s in docker config: expected username:password", key)
+			}
+			creds.Username, creds.Password = username, password
+		}
+		return creds, nil
+	}
+	return Credentials{}, nil
+}
+
+// normalizeConfigHost strips the scheme and path from a Docker config key
+func normalizeConfigHost(key string) string {
+	host := key
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	host, _, _ = strings.Cut(host, "/")
+	if host == "index.docker.io" || host == dockerHubAPIHost {
+		host = dockerHubHost
+	}
+	return host
+}
+
+// challenge is a parsed WWW-Authenticate header
+type challenge struct {
+	scheme string
+	params map[string]string
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`
+func parseChallenge(header string) challenge {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	c := challenge{scheme: strings.ToLower(scheme), params: make(map[string]string)}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			c.params[key] = value
+		}
+	}
+	return c
+}
@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDockerConfigCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "dXNlcjpwYXNz"},
+    "registry.example.com": {"username": "bob", "password": "secret"},
+    "https://token.example.com/": {"registrytoken": "abc"}
+  },
+  "credsStore": "desktop"
+}`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	store, err := LoadDockerConfig(path)
+	if err != nil {
+		t.Fatalf("LoadDockerConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		host     string
+		expected Credentials
+	}{
+		{host: "docker.io", expected: Credentials{Username: "user", Password: "pass"}},
+		{host: "registry.example.com", expected: Credentials{Username: "bob", Password: "secret"}},
+		{host: "token.example.com", expected: Credentials{RegistryToken: "abc"}},
+		{host: "ghcr.io", expected: Credentials{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			creds, err := store.Credentials(tt.host)
+			if err != nil {
+				t.Fatalf("Credentials() error = %v", err)
+			}
+			if creds != tt.expected {
+				t.Errorf("Credentials() = %+v, expected %+v", creds, tt.expected)
+			}
+		})
+	}
+
+	missing, err := LoadDockerConfig(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadDockerConfig() error for missing file = %v", err)
+	}
+	if creds, _ := missing.Credentials("docker.io"); !creds.IsZero() {
+		t.Errorf("Credentials() for missing config = %+v, expected anonymous", creds)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	c := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	if c.scheme != "bearer" {
+		t.Errorf("parseChallenge() scheme = %q", c.scheme)
+	}
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}
+	for k, v := range expected {
+		if c.params[k] != v {
+			t.Errorf("parseChallenge() %s = %q, expected %q", k, c.params[k], v)
+		}
+	}
+
+	if c := parseChallenge(`Basic realm="Registry"`); c.scheme != "basic" || c.params["realm"] != "Registry" {
+		t.Errorf("parseChallenge() = %+v", c)
+	}
+}
@@ -0,0 +1,322 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// manifestMediaTypes are the manifest types accepted when resolving digests. Indexes come first
+// so that multi-platform images resolve to the digest of the index.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ErrNotFound is returned when a manifest does not exist in its repository
+var ErrNotFound = errors.New("not found")
+
+// Client talks to container registries using the OCI Distribution API
+type Client struct {
+	HTTPClient  *http.Client
+	Credentials CredentialStore
+
+	mu sync.Mutex
+	// tokens caches bearer tokens by host and scope
+	tokens map[string]string
+}
+
+// NewClient creates a client that looks up credentials in store
+func NewClient(store CredentialStore) *Client {
+	return &Client{
+		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+		Credentials: store,
+	}
+}
+
+// Digest returns the digest of the manifest an image reference points to. The reference must
+// have a tag; a reference that already has a digest returns it as-is.
+func (c *Client) Digest(ctx context.Context, ref taskdef.ImageReference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	if ref.Tag == "" {
+		return "", fmt.Errorf("image %s has no tag", ref)
+	}
+	repo := ParseRepository(ref)
+
+	resp, err := c.do(ctx, http.MethodHead, repo, "manifests/"+ref.Tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest for %s: %w", ref, err)
+	}
+	_ = resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// Some registries only return the digest header on GET; hash the manifest instead
+	resp, err = c.do(ctx, http.MethodGet, repo, "manifests/"+ref.Tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest for %s: %w", ref, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, resp.Body); err != nil {
+		return "", fmt.Errorf("failed to read manifest for %s: %w", ref, err)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Exists reports whether the manifest an image reference points to exists in its registry.
+// References without a tag or digest refer to "latest".
+func (c *Client) Exists(ctx context.Context, ref taskdef.ImageReference) (bool, error) {
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, ParseRepository(ref), "manifests/"+reference)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", ref, err)
+	}
+	_ = resp.Body.Close()
+	return true, nil
+}
+
+// Tags lists the tags of the repository of an image reference, following pagination links
+func (c *Client) Tags(ctx context.Context, ref taskdef.ImageReference) ([]string, error) {
+	repo := ParseRepository(ref)
+	var tags []string
+	path := "tags/list"
+	for path != "" {
+		resp, err := c.do(ctx, http.MethodGet, repo, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repo, err)
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tag list of %s: %w", repo, err)
+		}
+		tags = append(tags, body.Tags...)
+
+		if path, err = nextPage(resp.Header.Get("Link"), repo); err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %w", repo, err)
+		}
+	}
+	return tags, nil
+}
+
+// nextPage returns the repository-relative path of the rel="next" Link header, or "" if there is none
+func nextPage(link string, repo Repository) (string, error) {
+	if link == "" || !strings.Contains(link, `rel="next"`) {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start {
+		return "", fmt.Errorf("invalid Link header %q", link)
+	}
+	u, err := url.Parse(link[start+1 : end])
+	if err != nil {
+		return "", fmt.Errorf("invalid Link header %q: %w", link, err)
+	}
+	prefix := "/v2/" + repo.Path + "/"
+	if !strings.HasPrefix(u.Path, prefix) {
+		return "", fmt.Errorf("unexpected Link header %q", link)
+	}
+	path := strings.TrimPrefix(u.Path, prefix)
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
+// do sends a request to /v2/<repository>/<path>, authenticating when the registry asks for it.
+// Responses other than 200 OK are returned as errors.
+func (c *Client) do(ctx context.Context, method string, repo Repository, path string) (*http.Response, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/%s", repo.apiHost(), repo.Path, path)
+	creds, err := c.credentials(repo.Host)
+	if err != nil {
+		return nil, err
+	}
+	scope := fmt.Sprintf("repository:%s:pull", repo.Path)
+
+	authorization := ""
+	if token := c.cachedToken(repo.Host, scope); token != "" {
+		authorization = "Bearer " + token
+	} else if creds.RegistryToken != "" {
+		authorization = "Bearer " + creds.RegistryToken
+	}
+
+	resp, err := c.send(ctx, method, endpoint, authorization)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		_ = resp.Body.Close()
+		authorization, err = c.authorize(ctx, repo.Host, scope, creds, parseChallenge(resp.Header.Get("WWW-Authenticate")))
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.send(ctx, method, endpoint, authorization); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, fmt.Errorf("%s %w in %s", path, ErrNotFound, repo)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, fmt.Errorf("access to %s denied (%s)", repo, resp.Status)
+		default:
+			return nil, fmt.Errorf("registry returned %s for %s", resp.Status, repo)
+		}
+	}
+	return resp, nil
+}
+
+// send sends a single request with the given Authorization header
+func (c *Client) send(ctx context.Context, method, endpoint, authorization string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to registry failed: %w", err)
+	}
+	return resp, nil
+}
+
+// authorize answers an authentication challenge and returns the Authorization header to retry with
+func (c *Client) authorize(ctx context.Context, host, scope string, creds Credentials, ch challenge) (string, error) {
+	switch ch.scheme {
+	case "basic":
+		if creds.Username == "" && creds.Password == "" {
+			return "", fmt.Errorf("registry %s requires authentication; log in with docker login", host)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password)), nil
+	case "bearer":
+		token, err := c.fetchToken(ctx, creds, ch, scope)
+		if err != nil {
+			return "", fmt.Errorf("failed to get token for %s: %w", host, err)
+		}
+		c.mu.Lock()
+		if c.tokens == nil {
+			c.tokens = make(map[string]string)
+		}
+		c.tokens[host+" "+scope] = token
+		c.mu.Unlock()
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("registry %s requires unsupported authentication %q", host, ch.scheme)
+	}
+}
+
+// fetchToken gets a bearer token from the realm of a challenge, using basic auth if there are credentials
+func (c *Client) fetchToken(ctx context.Context, creds Credentials, ch challenge, scope string) (string, error) {
+	realm := ch.params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("bearer challenge has no realm")
+	}
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid realm %q: %w", realm, err)
+	}
+	query := u.Query()
+	if service := ch.params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if s := ch.params["scope"]; s != "" {
+		scope = s
+	}
+	query.Set("scope", scope)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	if creds.Username != "" || creds.Password != "" {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token response has no token")
+}
+
+func (c *Client) credentials(host string) (Credentials, error) {
+	if c.Credentials == nil {
+		return Credentials{}, nil
+	}
+	return c.Credentials.Credentials(host)
+}
+
+func (c *Client) cachedToken(host, scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[host+" "+scope]
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
@@ -0,0 +1,236 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// staticCredentials is a CredentialStore returning the same credentials for every host
+type staticCredentials Credentials
+
+func (s staticCredentials) Credentials(host string) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// newTestRegistry starts a registry stand-in serving the manifest my-app:v1. auth checks the
+// Authorization header of manifest requests and returns the challenge to send if it is rejected.
+func newTestRegistry(t *testing.T, auth func(r *http.Request) (challenge string, ok bool)) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/my-app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			t.Errorf("request does not accept OCI indexes: %q", r.Header.Get("Accept"))
+		}
+		if challenge, ok := auth(r); !ok {
+			w.Header().Set("WWW-Authenticate", challenge)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		if strings.TrimPrefix(r.URL.Path, "/v2/my-app/manifests/") != "v1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:my-app:pull" || r.URL.Query().Get("service") != "test-registry" {
+			t.Errorf("unexpected token request: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"token": "test-token"}`))
+	})
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientDigest(t *testing.T) {
+	var server *httptest.Server
+	tests := []struct {
+		name    string
+		auth    func(r *http.Request) (string, bool)
+		creds   Credentials
+		image   string
+		wantErr string
+	}{
+		{
+			name:  "Anonymous",
+			auth:  func(r *http.Request) (string, bool) { return "", true },
+			image: "my-app:v1",
+		},
+		{
+			name: "Basic auth",
+			auth: func(r *http.Request) (string, bool) {
+				user, pass, ok := r.BasicAuth()
+				return `Basic realm="test"`, ok && user == "user" && pass == "pass"
+			},
+			creds: Credentials{Username: "user", Password: "pass"},
+			image: "my-app:v1",
+		},
+		{
+			name: "Basic auth without credentials",
+			auth: func(r *http.Request) (string, bool) {
+				_, _, ok := r.BasicAuth()
+				return `Basic realm="test"`, ok
+			},
+			image:   "my-app:v1",
+			wantErr: "requires authentication",
+		},
+		{
+			name: "Bearer token",
+			auth: func(r *http.Request) (string, bool) {
+				return `Bearer realm="` + server.URL + `/token",service="test-registry"`, r.Header.Get("Authorization") == "Bearer test-token"
+			},
+			creds: Credentials{Username: "user", Password: "pass"},
+			image: "my-app:v1",
+		},
+		{
+			name: "Registry token from docker config",
+			auth: func(r *http.Request) (string, bool) {
+				return `Bearer realm="` + server.URL + `/token"`, r.Header.Get("Authorization") == "Bearer stored-token"
+			},
+			creds: Credentials{RegistryToken: "stored-token"},
+			image: "my-app:v1",
+		},
+		{
+			name:    "Unknown tag",
+			auth:    func(r *http.Request) (string, bool) { return "", true },
+			image:   "my-app:v2",
+			wantErr: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server = newTestRegistry(t, tt.auth)
+			client := NewClient(staticCredentials(tt.creds))
+			client.HTTPClient = server.Client()
+
+			host := strings.TrimPrefix(server.URL, "https://")
+			digest, err := client.Digest(context.Background(), taskdef.ParseImageReference(host+"/"+tt.image))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Digest() error = %v, expected %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Digest() error = %v", err)
+			}
+			if digest != testDigest {
+				t.Errorf("Digest() = %q, expected %q", digest, testDigest)
+			}
+		})
+	}
+}
+
+func TestClientDigestCachesToken(t *testing.T) {
+	tokenRequests := 0
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/v2/my-app/manifests/v1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		_, _ = w.Write([]byte(`{"access_token": "test-token"}`))
+	})
+
+	client := NewClient(nil)
+	client.HTTPClient = server.Client()
+	ref := taskdef.ParseImageReference(strings.TrimPrefix(server.URL, "https://") + "/my-app:v1")
+	for i := 0; i < 2; i++ {
+		if _, err := client.Digest(context.Background(), ref); err != nil {
+			t.Fatalf("Digest() error = %v", err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("token requested %d times, expected 1", tokenRequests)
+	}
+}
+
+func TestClientExists(t *testing.T) {
+	server := newTestRegistry(t, func(r *http.Request) (string, bool) { return "", true })
+	client := NewClient(nil)
+	client.HTTPClient = server.Client()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		image    string
+		expected bool
+	}{
+		{image: "my-app:v1", expected: true},
+		{image: "my-app:v2", expected: false},
+		{image: "my-app@" + testDigest, expected: false},
+		{image: "other:v1", expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			exists, err := client.Exists(context.Background(), taskdef.ParseImageReference(host+"/"+tt.image))
+			if err != nil {
+				t.Fatalf("Exists() error = %v", err)
+			}
+			if exists != tt.expected {
+				t.Errorf("Exists() = %v, expected %v", exists, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientTags(t *testing.T) {
+	pages := map[string]string{
+		"":  `{"name": "my-app", "tags": ["v1.0.0", "v1.1.0"]}`,
+		"b": `{"name": "my-app", "tags": ["v1.2.0", "latest"]}`,
+		"d": `{"name": "my-app", "tags": null}`,
+	}
+	next := map[string]string{"": "b", "b": "d"}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/team/my-app/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		last := r.URL.Query().Get("last")
+		if n, ok := next[last]; ok {
+			w.Header().Set("Link", `</v2/team/my-app/tags/list?last=`+n+`&n=2>; rel="next"`)
+		}
+		_, _ = w.Write([]byte(pages[last]))
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	client.HTTPClient = server.Client()
+	host := strings.TrimPrefix(server.URL, "https://")
+	tags, err := client.Tags(context.Background(), taskdef.ParseImageReference(host+"/team/my-app:v1.0.0"))
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	expected := []string{"v1.0.0", "v1.1.0", "v1.2.0", "latest"}
+	if strings.Join(tags, ",") != strings.Join(expected, ",") {
+		t.Errorf("Tags() = %v, expected %v", tags, expected)
+	}
+
+	if _, err := client.Tags(context.Background(), taskdef.ParseImageReference(host+"/unknown:v1")); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Tags() error = %v, expected ErrNotFound", err)
+	}
+}
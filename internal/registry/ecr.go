@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/aws"
+)
+
+// ecrHostPattern matches ECR registry hosts and captures the region
+var ecrHostPattern = regexp.MustCompile(`^[0-9]{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// ECRCredentials is a CredentialStore that gets registry credentials for ECR hosts from the ECR
+// GetAuthorizationToken API. Other hosts, and ECR hosts when no AWS credentials are configured,
+// get empty credentials.
+type ECRCredentials struct {
+	// NewClient creates an AWS client for a region
+	NewClient func(region string) (*aws.Client, error)
+
+	mu    sync.Mutex
+	cache map[string]Credentials
+}
+
+// NewECRCredentials creates an ECRCredentials using the AWS credentials of profile (see
+// aws.LoadCredentials). The region of each token is the region of the registry host.
+func NewECRCredentials(profile string) *ECRCredentials {
+	return &ECRCredentials{
+		NewClient: func(region string) (*aws.Client, error) {
+			creds, err := aws.LoadCredentials(profile)
+			if err != nil {
+				return nil, err
+			}
+			return &aws.Client{Region: region, Credentials: creds}, nil
+		},
+	}
+}
+
+// Credentials returns the registry credentials for an ECR host
+func (e *ECRCredentials) Credentials(host string) (Credentials, error) {
+	m := ecrHostPattern.FindStringSubmatch(host)
+	if m == nil {
+		return Credentials{}, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if creds, ok := e.cache[host]; ok {
+		return creds, nil
+	}
+
+	client, err := e.NewClient(m[1])
+	if err != nil {
+		return Credentials{}, err
+	}
+	if client.Credentials.IsZero() {
+		return Credentials{}, nil
+	}
+
+	var output struct {
+		AuthorizationData []struct {
+			AuthorizationToken string `json:"authorizationToken"`
+		} `json:"authorizationData"`
+	}
+	if err := client.Call(context.Background(), aws.ECR, "GetAuthorizationToken", struct{}{}, &output); err != nil {
+		return Credentials{}, fmt.Errorf("failed to get ECR token for %s: %w", host, err)
+	}
+	if len(output.AuthorizationData) == 0 {
+		return Credentials{}, fmt.Errorf("failed to get ECR token for %s: no authorization data", host)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(output.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("invalid ECR token for %s: %w", host, err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return Credentials{}, fmt.Errorf("invalid ECR token for %s: expected username:password", host)
+	}
+
+	creds := Credentials{Username: username, Password: password}
+	if e.cache == nil {
+		e.cache = make(map[string]Credentials)
+	}
+	e.cache[host] = creds
+	return creds, nil
+}
+
+// ChainCredentials is a CredentialStore that returns the first non-empty credentials of its stores
+type ChainCredentials []CredentialStore
+
+// chainWarnings receives the errors of the stores ChainCredentials falls back from
+var chainWarnings io.Writer = os.Stderr
+
+// Credentials returns the first non-empty credentials found for host. A store that fails, e.g. ECR
+// without AWS credentials, is reported as a warning and the next store is tried.
+func (c ChainCredentials) Credentials(host string) (Credentials, error) {
+	for _, store := range c {
+		creds, err := store.Credentials(host)
+		if err != nil {
+			fmt.Fprintf(chainWarnings, "warning: %v; trying the next credential source\n", err)
+			continue
+		}
+		if !creds.IsZero() {
+			return creds, nil
+		}
+	}
+	return Credentials{}, nil
+}
+
+// DefaultCredentials returns the credential store used by the CLI: ECR tokens from the AWS API
+// using the credentials of profile for ECR registries, then the Docker config
+func DefaultCredentials(profile string) (CredentialStore, error) {
+	config, err := LoadDockerConfig(DefaultDockerConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	return ChainCredentials{NewECRCredentials(profile), config}, nil
+}
@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/aws"
+)
+
+func TestECRCredentials(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-Amz-Target") != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" {
+			t.Errorf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+		// base64("AWS:ecr-password")
+		_, _ = w.Write([]byte(`{"authorizationData": [{"authorizationToken": "QVdTOmVjci1wYXNzd29yZA=="}]}`))
+	}))
+	defer server.Close()
+
+	var regions []string
+	store := &ECRCredentials{NewClient: func(region string) (*aws.Client, error) {
+		regions = append(regions, region)
+		return &aws.Client{Region: region, Credentials: aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, Endpoint: server.URL}, nil
+	}}
+
+	for i := 0; i < 2; i++ {
+		creds, err := store.Credentials("123456789012.dkr.ecr.ap-northeast-1.amazonaws.com")
+		if err != nil {
+			t.Fatalf("Credentials() error = %v", err)
+		}
+		if creds != (Credentials{Username: "AWS", Password: "ecr-password"}) {
+			t.Errorf("Credentials() = %+v", creds)
+		}
+	}
+	if calls != 1 || len(regions) != 1 || regions[0] != "ap-northeast-1" {
+		t.Errorf("GetAuthorizationToken called %d times for regions %v, expected once for ap-northeast-1", calls, regions)
+	}
+
+	if creds, err := store.Credentials("ghcr.io"); err != nil || !creds.IsZero() {
+		t.Errorf("Credentials() for a non-ECR host = %+v, %v", creds, err)
+	}
+}
+
+func TestNewECRCredentialsProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	content := "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = secret\n\n[deploy]\naws_access_key_id = AKIDDEPLOY\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{"", "AKIDENV"},
+		{"deploy", "AKIDDEPLOY"},
+	}
+	for _, tt := range tests {
+		client, err := NewECRCredentials(tt.profile).NewClient("eu-west-1")
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if client.Credentials.AccessKeyID != tt.want || client.Region != "eu-west-1" {
+			t.Errorf("NewClient() for profile %q = %s in %s, expected %s in eu-west-1", tt.profile, client.Credentials.AccessKeyID, client.Region, tt.want)
+		}
+	}
+}
+
+func TestChainCredentials(t *testing.T) {
+	chain := ChainCredentials{
+		staticCredentials{},
+		staticCredentials{Username: "user", Password: "pass"},
+		staticCredentials{Username: "other"},
+	}
+	creds, err := chain.Credentials("registry.example.com")
+	if err != nil || creds.Username != "user" {
+		t.Errorf("Credentials() = %+v, %v", creds, err)
+	}
+}
+
+// failingCredentials is a CredentialStore that always fails
+type failingCredentials struct{}
+
+func (failingCredentials) Credentials(host string) (Credentials, error) {
+	return Credentials{}, errors.New("failed to get ECR token for " + host)
+}
+
+func TestChainCredentialsFallback(t *testing.T) {
+	var warnings bytes.Buffer
+	chainWarnings = &warnings
+	t.Cleanup(func() { chainWarnings = os.Stderr })
+
+	chain := ChainCredentials{failingCredentials{}, staticCredentials{Username: "docker", Password: "pass"}}
+	creds, err := chain.Credentials("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+	if err != nil || creds.Username != "docker" {
+		t.Errorf("Credentials() = %+v, %v, expected the Docker config credentials", creds, err)
+	}
+	if !strings.Contains(warnings.String(), "warning: failed to get ECR token for 123456789012.dkr.ecr.us-east-1.amazonaws.com") {
+		t.Errorf("warnings = %q", warnings.String())
+	}
+
+	// Without other credentials the registry is accessed anonymously
+	creds, err = ChainCredentials{failingCredentials{}}.Credentials("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+	if err != nil || !creds.IsZero() {
+		t.Errorf("Credentials() = %+v, %v, expected no credentials", creds, err)
+	}
+}
@@ -0,0 +1,165 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// Platform is the operating system and CPU architecture an image runs on, as in the OCI image spec
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String returns the platform as "os/architecture[/variant]"
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Satisfies reports whether an image for p runs on the wanted platform. A wanted platform without a
+// variant accepts any variant.
+func (p Platform) Satisfies(want Platform) bool {
+	return p.OS == want.OS && p.Architecture == want.Architecture && (want.Variant == "" || p.Variant == want.Variant)
+}
+
+// RuntimePlatform returns the image platform required by an ECS runtime platform. Unset fields
+// default to what ECS uses: Linux on X86_64.
+func RuntimePlatform(rp *taskdef.RuntimePlatform) Platform {
+	p := Platform{OS: "linux", Architecture: "amd64"}
+	if rp == nil {
+		return p
+	}
+	if rp.CPUArchitecture == "ARM64" {
+		p.Architecture = "arm64"
+	}
+	if strings.HasPrefix(rp.OperatingSystemFamily, "WINDOWS") {
+		p.OS = "windows"
+	}
+	return p
+}
+
+// manifest holds the fields of image manifests and indexes needed to find their platforms
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Manifests []struct {
+		Digest   string    `json:"digest"`
+		Platform *Platform `json:"platform"`
+	} `json:"manifests"`
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+}
+
+// Platforms returns the platforms an image is available for. For an image index these are the
+// platforms of its manifests; for a single manifest it is the platform of its image config.
+// Attestation manifests (unknown/unknown) are left out.
+func (c *Client) Platforms(ctx context.Context, ref taskdef.ImageReference) ([]Platform, error) {
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+	repo := ParseRepository(ref)
+
+	var m manifest
+	if err := c.getJSON(ctx, repo, "manifests/"+reference, &m); err != nil {
+		return nil, fmt.Errorf("failed to get manifest for %s: %w", ref, err)
+	}
+	if len(m.Manifests) > 0 {
+		var platforms []Platform
+		for _, entry := range m.Manifests {
+			if entry.Platform != nil && entry.Platform.OS != "unknown" {
+				platforms = append(platforms, *entry.Platform)
+			}
+		}
+		return platforms, nil
+	}
+
+	if m.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest for %s has no config", ref)
+	}
+	var config Platform
+	if err := c.getJSON(ctx, repo, "blobs/"+m.Config.Digest, &config); err != nil {
+		return nil, fmt.Errorf("failed to get image config for %s: %w", ref, err)
+	}
+	return []Platform{config}, nil
+}
+
+// imageConfig holds the fields of an image config needed to find when the image was created
+type imageConfig struct {
+	Created string `json:"created"`
+}
+
+// Created returns the creation time recorded in the image config of an image. For an image index,
+// the config of its first manifest that is not an attestation is used. The time is zero if the
+// config does not record it.
+func (c *Client) Created(ctx context.Context, ref taskdef.ImageReference) (time.Time, error) {
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+	repo := ParseRepository(ref)
+
+	var m manifest
+	if err := c.getJSON(ctx, repo, "manifests/"+reference, &m); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get manifest for %s: %w", ref, err)
+	}
+	for _, entry := range m.Manifests {
+		if entry.Platform != nil && entry.Platform.OS == "unknown" {
+			continue
+		}
+		var image manifest
+		if err := c.getJSON(ctx, repo, "manifests/"+entry.Digest, &image); err != nil {
+			return time.Time{}, fmt.Errorf("failed to get manifest for %s: %w", ref, err)
+		}
+		m = image
+		break
+	}
+
+	if m.Config.Digest == "" {
+		return time.Time{}, fmt.Errorf("manifest for %s has no config", ref)
+	}
+	var config imageConfig
+	if err := c.getJSON(ctx, repo, "blobs/"+m.Config.Digest, &config); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get image config for %s: %w", ref, err)
+	}
+	if config.Created == "" {
+		return time.Time{}, nil
+	}
+	created, err := time.Parse(time.RFC3339Nano, config.Created)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid creation time in image config for %s: %w", ref, err)
+	}
+	return created, nil
+}
+
+// getJSON fetches /v2/<repository>/<path> and decodes the JSON response into v
+func (c *Client) getJSON(ctx context.Context, repo Repository, path string, v interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, repo, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestRuntimePlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform *taskdef.RuntimePlatform
+		expected string
+	}{
+		{name: "Unset", platform: nil, expected: "linux/amd64"},
+		{name: "ARM64", platform: &taskdef.RuntimePlatform{CPUArchitecture: "ARM64", OperatingSystemFamily: "LINUX"}, expected: "linux/arm64"},
+		{name: "Windows", platform: &taskdef.RuntimePlatform{CPUArchitecture: "X86_64", OperatingSystemFamily: "WINDOWS_SERVER_2022_CORE"}, expected: "windows/amd64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RuntimePlatform(tt.platform).String(); got != tt.expected {
+				t.Errorf("RuntimePlatform() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlatformSatisfies(t *testing.T) {
+	arm := Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	if !arm.Satisfies(Platform{OS: "linux", Architecture: "arm64"}) {
+		t.Errorf("%s should satisfy linux/arm64", arm)
+	}
+	if arm.Satisfies(Platform{OS: "linux", Architecture: "amd64"}) {
+		t.Errorf("%s should not satisfy linux/amd64", arm)
+	}
+	if arm.Satisfies(Platform{OS: "linux", Architecture: "arm64", Variant: "v7"}) {
+		t.Errorf("%s should not satisfy linux/arm64/v7", arm)
+	}
+}
+
+func TestClientPlatforms(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/multi/manifests/v1":
+			_, _ = w.Write([]byte(`{
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"digest": "sha256:aaa", "platform": {"os": "linux", "architecture": "amd64"}},
+    {"digest": "sha256:bbb", "platform": {"os": "linux", "architecture": "arm64", "variant": "v8"}},
+    {"digest": "sha256:ccc", "platform": {"os": "unknown", "architecture": "unknown"}}
+  ]
+}`))
+		case "/v2/single/manifests/v1":
+			_, _ = w.Write([]byte(`{"mediaType": "application/vnd.oci.image.manifest.v1+json", "config": {"digest": "sha256:cfg"}}`))
+		case "/v2/single/blobs/sha256:cfg":
+			_, _ = w.Write([]byte(`{"architecture": "amd64", "os": "linux", "rootfs": {}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	client := NewClient(nil)
+	client.HTTPClient = server.Client()
+
+	tests := []struct {
+		image    string
+		expected []Platform
+		notFound bool
+	}{
+		{
+			image: "multi:v1",
+			expected: []Platform{
+				{OS: "linux", Architecture: "amd64"},
+				{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			},
+		},
+		{image: "single:v1", expected: []Platform{{OS: "linux", Architecture: "amd64"}}},
+		{image: "multi:v2", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			platforms, err := client.Platforms(context.Background(), taskdef.ParseImageReference(host+"/"+tt.image))
+			if tt.notFound {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Platforms() error = %v, expected ErrNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Platforms() error = %v", err)
+			}
+			if !reflect.DeepEqual(platforms, tt.expected) {
+				t.Errorf("Platforms() = %v, expected %v", platforms, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientCreated(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/multi/manifests/v1":
+			_, _ = w.Write([]byte(`{
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"digest": "sha256:att", "platform": {"os": "unknown", "architecture": "unknown"}},
+    {"digest": "sha256:amd", "platform": {"os": "linux", "architecture": "amd64"}}
+  ]
+}`))
+		case "/v2/multi/manifests/sha256:amd":
+			_, _ = w.Write([]byte(`{"mediaType": "application/vnd.oci.image.manifest.v1+json", "config": {"digest": "sha256:cfg"}}`))
+		case "/v2/multi/blobs/sha256:cfg":
+			_, _ = w.Write([]byte(`{"created": "2024-01-10T09:30:00.123456789Z", "os": "linux"}`))
+		case "/v2/single/manifests/v1":
+			_, _ = w.Write([]byte(`{"mediaType": "application/vnd.oci.image.manifest.v1+json", "config": {"digest": "sha256:cfg"}}`))
+		case "/v2/single/blobs/sha256:cfg":
+			_, _ = w.Write([]byte(`{"os": "linux"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	client := NewClient(nil)
+	client.HTTPClient = server.Client()
+
+	tests := []struct {
+		image    string
+		expected time.Time
+		notFound bool
+	}{
+		{image: "multi:v1", expected: time.Date(2024, 1, 10, 9, 30, 0, 123456789, time.UTC)},
+		{image: "single:v1", expected: time.Time{}},
+		{image: "multi:v2", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			created, err := client.Created(context.Background(), taskdef.ParseImageReference(host+"/"+tt.image))
+			if tt.notFound {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Created() error = %v, expected ErrNotFound", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Created() error = %v", err)
+			}
+			if !created.Equal(tt.expected) {
+				t.Errorf("Created() = %v, expected %v", created, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package registry
+
+import (
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+const (
+	// dockerHubHost is the host used in image references and credentials for Docker Hub
+	dockerHubHost = "docker.io"
+	// dockerHubAPIHost serves the Docker Hub registry API
+	dockerHubAPIHost = "registry-1.docker.io"
+)
+
+// Repository identifies a repository in a registry
+type Repository struct {
+	// Host is the registry host, e.g. "123456789.dkr.ecr.us-east-1.amazonaws.com" or "docker.io"
+	Host string
+	// Path is the repository path in the registry, e.g. "my-app" or "library/nginx"
+	Path string
+}
+
+// ParseRepository splits the repository of an image reference into registry host and path.
+// References without a registry host refer to Docker Hub.
+func ParseRepository(ref taskdef.ImageReference) Repository {
+	host, path, ok := strings.Cut(ref.Repository, "/")
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		host, path = dockerHubHost, ref.Repository
+	}
+	if host == "index.docker.io" {
+		host = dockerHubHost
+	}
+	if host == dockerHubHost && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+	return Repository{Host: host, Path: path}
+}
+
+// apiHost returns the host serving the registry API
+func (r Repository) apiHost() string {
+	if r.Host == dockerHubHost {
+		return dockerHubAPIHost
+	}
+	return r.Host
+}
+
+func (r Repository) String() string {
+	return r.Host + "/" + r.Path
+}
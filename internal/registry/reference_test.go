@@ -0,0 +1,34 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+func TestParseRepository(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected Repository
+	}{
+		{image: "nginx:1.25", expected: Repository{Host: "docker.io", Path: "library/nginx"}},
+		{image: "bitnami/redis:7", expected: Repository{Host: "docker.io", Path: "bitnami/redis"}},
+		{image: "docker.io/nginx", expected: Repository{Host: "docker.io", Path: "library/nginx"}},
+		{image: "index.docker.io/bitnami/redis", expected: Repository{Host: "docker.io", Path: "bitnami/redis"}},
+		{image: "localhost/my-app:v1", expected: Repository{Host: "localhost", Path: "my-app"}},
+		{image: "localhost:5000/team/my-app:v1", expected: Repository{Host: "localhost:5000", Path: "team/my-app"}},
+		{
+			image:    "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1@sha256:abc",
+			expected: Repository{Host: "123456789.dkr.ecr.us-east-1.amazonaws.com", Path: "my-app"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			repo := ParseRepository(taskdef.ParseImageReference(tt.image))
+			if repo != tt.expected {
+				t.Errorf("ParseRepository() = %+v, expected %+v", repo, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// semverPattern matches semantic versions with an optional "v" prefix
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// semver is a parsed semantic version
+type semver struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parseSemver parses a tag such as "v1.2.3" or "1.2.3-rc.1+build.5"
+func parseSemver(tag string) (semver, bool) {
+	m := semverPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return semver{}, false
+	}
+	var v semver
+	var err error
+	if v.major, err = strconv.Atoi(m[1]); err != nil {
+		return semver{}, false
+	}
+	if v.minor, err = strconv.Atoi(m[2]); err != nil {
+		return semver{}, false
+	}
+	if v.patch, err = strconv.Atoi(m[3]); err != nil {
+		return semver{}, false
+	}
+	if m[4] != "" {
+		v.prerelease = strings.Split(m[4], ".")
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 following the semantic versioning precedence rules
+func (v semver) compare(o semver) int {
+	for _, d := range []int{v.major - o.major, v.minor - o.minor, v.patch - o.patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	// A release has higher precedence than its pre-releases
+	switch {
+	case len(v.prerelease) == 0 && len(o.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(o.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(o.prerelease); i++ {
+		if c := compareIdentifier(v.prerelease[i], o.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.prerelease) - len(o.prerelease))
+}
+
+// compareIdentifier compares pre-release identifiers; numeric identifiers sort before others
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return sign(na - nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareNatural compares strings treating runs of digits as numbers, so that "build-10"
+// sorts after "build-9"
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		da, db := digitPrefix(a), digitPrefix(b)
+		if da != "" && db != "" {
+			na, _ := strconv.ParseUint(da, 10, 64)
+			nb, _ := strconv.ParseUint(db, 10, 64)
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return sign(len(a) - len(b))
+}
+
+func digitPrefix(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// LatestTag returns the latest of the tags matching pattern: the highest semantic version if any
+// matching tag is one, or else the most recently created tag, using created to look up the
+// creation time of each tag. Tags created at the same time are ordered naturally (numbers compared
+// by value), so the result is stable when several tags point to the same image.
+func LatestTag(tags []string, pattern *regexp.Regexp, created func(tag string) (time.Time, error)) (string, bool, error) {
+	var matches []string
+	latest, found := "", false
+	var latestVersion semver
+	for _, tag := range tags {
+		if !pattern.MatchString(tag) {
+			continue
+		}
+		matches = append(matches, tag)
+		if v, ok := parseSemver(tag); ok && (!found || v.compare(latestVersion) > 0) {
+			latest, found, latestVersion = tag, true, v
+		}
+	}
+	if found || len(matches) == 0 {
+		return latest, found, nil
+	}
+
+	var latestTime time.Time
+	for _, tag := range matches {
+		t, err := created(tag)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get the creation time of tag %s: %w", tag, err)
+		}
+		if !found || t.After(latestTime) || (t.Equal(latestTime) && compareNatural(tag, latest) > 0) {
+			latest, found, latestTime = tag, true, t
+		}
+	}
+	return latest, found, nil
+}
@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		pattern  string
+		created  map[string]string
+		expected string
+		found    bool
+	}{
+		{
+			name:     "Highest semver",
+			tags:     []string{"v1.2.3", "v1.10.0", "v1.9.9", "latest", "v1.10.0-rc.1"},
+			pattern:  `^v\d+\.\d+\.\d+$`,
+			expected: "v1.10.0",
+			found:    true,
+		},
+		{
+			name:     "Release ranks above pre-release",
+			tags:     []string{"2.0.0-rc.2", "2.0.0", "2.0.0-rc.10"},
+			pattern:  `.`,
+			expected: "2.0.0",
+			found:    true,
+		},
+		{
+			name:     "Pre-release identifiers",
+			tags:     []string{"2.0.0-rc.2", "2.0.0-rc.10", "2.0.0-beta"},
+			pattern:  `-`,
+			expected: "2.0.0-rc.10",
+			found:    true,
+		},
+		{
+			name:     "Semver ranks above other tags",
+			tags:     []string{"v1.0.0", "zzz"},
+			pattern:  `.`,
+			expected: "v1.0.0",
+			found:    true,
+		},
+		{
+			name:     "Natural order for tags created at the same time",
+			tags:     []string{"nightly-20240109", "nightly-20240110", "build-9", "nightly-20231231"},
+			pattern:  `^nightly-`,
+			expected: "nightly-20240110",
+			found:    true,
+		},
+		{
+			name:     "Build numbers",
+			tags:     []string{"build-9", "build-10", "build-2"},
+			pattern:  `^build-\d+$`,
+			expected: "build-10",
+			found:    true,
+		},
+		{
+			name:     "Most recently created",
+			tags:     []string{"main-9f2c1e", "main-a41b07", "main-0c77d2", "release-1"},
+			pattern:  `^main-`,
+			created:  map[string]string{"main-9f2c1e": "2024-01-09T10:00:00Z", "main-a41b07": "2024-01-08T10:00:00Z", "main-0c77d2": "2024-01-10T10:00:00Z"},
+			expected: "main-0c77d2",
+			found:    true,
+		},
+		{
+			name:     "Creation time ranks above natural order",
+			tags:     []string{"build-9", "build-10"},
+			pattern:  `^build-`,
+			created:  map[string]string{"build-9": "2024-01-10T10:00:00Z", "build-10": "2023-12-31T10:00:00Z"},
+			expected: "build-9",
+			found:    true,
+		},
+		{
+			name:    "No match",
+			tags:    []string{"latest"},
+			pattern: `^v`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := func(tag string) (time.Time, error) {
+				if tt.created[tag] == "" {
+					return time.Time{}, nil
+				}
+				return time.Parse(time.RFC3339, tt.created[tag])
+			}
+			tag, found, err := LatestTag(tt.tags, regexp.MustCompile(tt.pattern), created)
+			if err != nil {
+				t.Fatalf("LatestTag() error = %v", err)
+			}
+			if tag != tt.expected || found != tt.found {
+				t.Errorf("LatestTag() = %q, %v, expected %q, %v", tag, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestLatestTagCreatedError(t *testing.T) {
+	calls := 0
+	created := func(tag string) (time.Time, error) {
+		calls++
+		return time.Time{}, errors.New("manifest unknown")
+	}
+
+	// Creation times are not needed when a semantic version matches
+	if tag, found, err := LatestTag([]string{"latest", "v1.0.0"}, regexp.MustCompile(`.`), created); err != nil || tag != "v1.0.0" || !found {
+		t.Errorf("LatestTag() = %q, %v, %v, expected v1.0.0", tag, found, err)
+	}
+	if calls != 0 {
+		t.Errorf("creation time looked up %d times, expected none", calls)
+	}
+
+	if _, _, err := LatestTag([]string{"latest"}, regexp.MustCompile(`.`), created); err == nil || !strings.Contains(err.Error(), "failed to get the creation time of tag latest") {
+		t.Errorf("LatestTag() error = %v, expected the lookup error", err)
+	}
+}
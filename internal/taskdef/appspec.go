@@ -0,0 +1,136 @@
+package taskdef
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTaskDefinitionPlaceholder is the placeholder CodeDeploy substitutes with the task
+// definition ARN in an AppSpec file
+const DefaultTaskDefinitionPlaceholder = "TASK_DEFINITION"
+
+// appSpecTarget represents the TaskDefinition property of a service in an AppSpec file
+type appSpecTarget struct {
+	// resource is the name of the resource, e.g. TargetService
+	resource string
+	value    string
+	literal  *span
+}
+
+// IsAppSpec reports whether data is a CodeDeploy AppSpec file (YAML or JSON) with a version and
+// a list of resources. CloudFormation templates, whose Resources is a mapping, are not AppSpec
+// files.
+func IsAppSpec(data []byte) bool {
+	docs, err := parseYAMLDocuments(data)
+	if err != nil || len(docs) != 1 {
+		return false
+	}
+	resources := mappingValue(docs[0], "Resources")
+	return mappingValue(docs[0], "version") != nil && resources != nil && resources.Kind == yaml.SequenceNode
+}
+
+// parseAppSpec finds the TaskDefinition property of every ECS service in an AppSpec file
+func parseAppSpec(src []byte) ([]appSpecTarget, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("AppSpec file must contain a single document")
+	}
+
+	var targets []appSpecTarget
+	resources := mappingValue(docs[0], "Resources")
+	if resources == nil || resources.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("AppSpec file has no Resources list")
+	}
+	for _, item := range resources.Content {
+		item = resolveAlias(item)
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			name := item.Content[i].Value
+			value := mappingValue(mappingValue(item.Content[i+1], "Properties"), "TaskDefinition")
+			if !isStringScalar(value) {
+				continue
+			}
+			literal, err := locateScalar(src, value)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, appSpecTarget{resource: name, value: value.Value, literal: literal})
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no TaskDefinition found in AppSpec file")
+	}
+	return targets, nil
+}
+
+// RenderAppSpec substitutes placeholders such as <TASK_DEFINITION> in the TaskDefinition
+// properties of an AppSpec file and returns the updated file. values maps placeholder names to
+// task definition ARNs. The rest of the file is left as it is.
+func RenderAppSpec(src []byte, values map[string]string) ([]byte, error) {
+	targets, err := parseAppSpec(src)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]string, len(targets))
+	for i, t := range targets {
+		current[i] = t.value
+	}
+	rendered, err := substitutePlaceholders(current, values)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make(map[span]string)
+	for i, t := range targets {
+		if rendered[i] != t.value {
+			edits[*t.literal] = rendered[i]
+		}
+	}
+	return replaceSpans(src, edits), nil
+}
+
+// ReverseAppSpec replaces the TaskDefinition properties of an AppSpec file with placeholders.
+// placeholders maps placeholder names to resource names (e.g. TargetService); if empty, every
+// TaskDefinition is replaced with <TASK_DEFINITION>.
+func ReverseAppSpec(src []byte, placeholders map[string]string) ([]byte, error) {
+	targets, err := parseAppSpec(src)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make(map[span]string)
+	if len(placeholders) == 0 {
+		for _, t := range targets {
+			edits[*t.literal] = "<" + DefaultTaskDefinitionPlaceholder + ">"
+		}
+		return replaceSpans(src, edits), nil
+	}
+
+	names := make([]string, 0, len(placeholders))
+	for name := range placeholders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		resource := placeholders[name]
+		found := false
+		for _, t := range targets {
+			if t.resource == resource {
+				edits[*t.literal] = "<" + normalizePlaceholder(name) + ">"
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("resource '%s' not found in AppSpec file", resource)
+		}
+	}
+	return replaceSpans(src, edits), nil
+}
@@ -0,0 +1,111 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+const testAppSpec = `version: 0.0
+Resources:
+  - TargetService:
+      Type: AWS::ECS::Service
+      Properties:
+        TaskDefinition: <TASK_DEFINITION> # set by CodeDeploy
+        LoadBalancerInfo:
+          ContainerName: "web"
+          ContainerPort: 80
+`
+
+func TestIsAppSpec(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "YAML AppSpec", input: testAppSpec, expected: true},
+		{name: "JSON AppSpec", input: `{"version": 0.0, "Resources": [{"TargetService": {"Properties": {"TaskDefinition": "<TASK_DEFINITION>"}}}]}`, expected: true},
+		{name: "CloudFormation template", input: "Resources:\n  TaskDefinition:\n    Type: AWS::ECS::TaskDefinition\n"},
+		{name: "Task definition", input: `{"family": "my-app", "containerDefinitions": []}`},
+		{name: "Invalid YAML", input: "version: ["},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAppSpec([]byte(tt.input)); got != tt.expected {
+				t.Errorf("IsAppSpec() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderAppSpec(t *testing.T) {
+	arn := "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:3"
+	tests := []struct {
+		name    string
+		input   string
+		values  map[string]string
+		wantErr string
+		want    string
+	}{
+		{
+			name:   "Substitute the task definition",
+			input:  testAppSpec,
+			values: map[string]string{"TASK_DEFINITION": arn},
+			want:   "TaskDefinition: " + arn + " # set by CodeDeploy\n",
+		},
+		{
+			name:   "JSON",
+			input:  `{"version": 0.0, "Resources": [{"TargetService": {"Properties": {"TaskDefinition": "<TASK_DEFINITION>"}}}]}`,
+			values: map[string]string{"<TASK_DEFINITION>": arn},
+			want:   `"TaskDefinition": "` + arn + `"`,
+		},
+		{
+			name:    "Error: missing value",
+			input:   testAppSpec,
+			values:  map[string]string{"IMAGE1_NAME": "my-app:v1"},
+			wantErr: "no value for placeholder(s): TASK_DEFINITION",
+		},
+		{
+			name:    "Error: no task definition",
+			input:   "version: 0.0\nResources: []\n",
+			values:  map[string]string{"TASK_DEFINITION": arn},
+			wantErr: "no TaskDefinition found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderAppSpec([]byte(tt.input), tt.values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RenderAppSpec() error = %v, expected %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderAppSpec() error = %v", err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("RenderAppSpec() = %s, expected to contain %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReverseAppSpec(t *testing.T) {
+	input := strings.Replace(testAppSpec, "<TASK_DEFINITION>", "arn:aws:ecs:us-east-1:123456789012:task-definition/my-app:3", 1)
+
+	got, err := ReverseAppSpec([]byte(input), nil)
+	if err != nil || string(got) != testAppSpec {
+		t.Errorf("ReverseAppSpec() = %s, %v, expected %s", got, err, testAppSpec)
+	}
+
+	got, err = ReverseAppSpec([]byte(input), map[string]string{"TASKDEF": "TargetService"})
+	if err != nil || !strings.Contains(string(got), "TaskDefinition: <TASKDEF>") {
+		t.Errorf("ReverseAppSpec() with a placeholder = %s, %v", got, err)
+	}
+
+	if _, err := ReverseAppSpec([]byte(input), map[string]string{"TASKDEF": "OtherService"}); err == nil {
+		t.Errorf("ReverseAppSpec() with an unknown resource succeeded")
+	}
+}
@@ -0,0 +1,137 @@
+package taskdef
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// cfnTaskDefinitionType is the CloudFormation resource type of ECS task definitions
+const cfnTaskDefinitionType = "AWS::ECS::TaskDefinition"
+
+// LoadCloudFormationTemplate loads a CloudFormation template (JSON or YAML) from a reader
+func LoadCloudFormationTemplate(r io.Reader) (*EmbeddedDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return newEmbeddedDocument(ModeCFN, data, parseCloudFormationTemplate)
+}
+
+// parseCloudFormationTemplate finds the container images of every AWS::ECS::TaskDefinition resource
+func parseCloudFormationTemplate(src []byte) ([]imageSite, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("input must be a single CloudFormation template")
+	}
+
+	resources := mappingValue(docs[0], "Resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("template has no Resources section")
+	}
+
+	var sites []imageSite
+	found := false
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		logicalID := resources.Content[i].Value
+		resource := resolveAlias(resources.Content[i+1])
+		if typ := mappingValue(resource, "Type"); typ == nil || typ.Value != cfnTaskDefinitionType {
+			continue
+		}
+		found = true
+
+		containers := mappingValue(mappingValue(resource, "Properties"), "ContainerDefinitions")
+		if containers == nil || containers.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, c := range containers.Content {
+			name, _ := cfnString(mappingValue(c, "Name"))
+			image, literal := cfnString(mappingValue(c, "Image"))
+			site := imageSite{resource: logicalID, container: name, image: image}
+			if literal != nil {
+				// Values we cannot find verbatim (e.g. block scalars) are treated as non-literal
+				site.literal, _ = locateScalar(src, literal)
+			}
+			sites = append(sites, site)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no %s resources found in template", cfnTaskDefinitionType)
+	}
+
+	return sites, nil
+}
+
+// cfnString renders a CloudFormation string value. Plain strings and Fn::Sub strings are
+// returned as-is; intrinsic functions that cannot be evaluated locally are rendered as ${...}.
+// literal is the scalar holding the literal suffix of the rendered value, if any.
+func cfnString(n *yaml.Node) (value string, literal *yaml.Node) {
+	n = resolveAlias(n)
+	if n == nil {
+		return "", nil
+	}
+
+	fn, arg := cfnIntrinsic(n)
+	switch fn {
+	case "":
+		if isStringScalar(n) {
+			return n.Value, n
+		}
+		return n.Value, nil
+	case "Fn::Sub":
+		// Fn::Sub takes either a string or a [string, variables] list
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) > 0 {
+			arg = resolveAlias(arg.Content[0])
+		}
+		if arg.Kind == yaml.ScalarNode {
+			return arg.Value, arg
+		}
+	case "Fn::Join":
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+			delimiter := resolveAlias(arg.Content[0])
+			list := resolveAlias(arg.Content[1])
+			if delimiter.Kind == yaml.ScalarNode && list.Kind == yaml.SequenceNode {
+				parts := make([]string, len(list.Content))
+				for i, p := range list.Content {
+					parts[i], literal = cfnString(p)
+				}
+				// Only the last part can hold the literal suffix of the joined value
+				return strings.Join(parts, delimiter.Value), literal
+			}
+		}
+	case "Ref":
+		if arg.Kind == yaml.ScalarNode {
+			return "${" + arg.Value + "}", nil
+		}
+	}
+
+	return "${" + fn + "}", nil
+}
+
+// cfnIntrinsic returns the intrinsic function name and argument of a node in either the
+// long form ({"Fn::Sub": ...}) or the YAML short form (!Sub ...). fn is empty for plain values.
+func cfnIntrinsic(n *yaml.Node) (fn string, arg *yaml.Node) {
+	if tag := n.Tag; strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!") {
+		name := strings.TrimPrefix(tag, "!")
+		if name != "Ref" && name != "Condition" {
+			name = "Fn::" + name
+		}
+		return name, n
+	}
+
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+		key := n.Content[0].Value
+		if key == "Ref" || strings.HasPrefix(key, "Fn::") {
+			return key, resolveAlias(n.Content[1])
+		}
+	}
+
+	return "", nil
+}
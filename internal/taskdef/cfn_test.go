@@ -0,0 +1,184 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCFNTemplateYAML = `Resources:
+  Cluster:
+    Type: AWS::ECS::Cluster
+  AppTaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      ContainerDefinitions:
+        - Name: web
+          Image: !Sub "${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/my-app:v1.0" # app
+        - Name: nginx
+          Image: nginx:latest
+        - Name: param
+          Image: !Ref ImageParam
+  WorkerTaskDefinition:
+    Type: AWS::ECS::TaskDefinition
+    Properties:
+      ContainerDefinitions:
+        - Name: worker
+          Image:
+            Fn::Join: ["", [!Ref "AWS::AccountId", ".dkr.ecr.amazonaws.com/worker:v1.0"]]
+        - Name: sub-tag
+          Image:
+            Fn::Sub: ["worker:${Tag}", {Tag: v1.0}]
+`
+
+const testCFNTemplateJSON = `{
+  "Resources": {
+    "AppTaskDefinition": {
+      "Type": "AWS::ECS::TaskDefinition",
+      "Properties": {
+        "ContainerDefinitions": [
+          {"Name": "web", "Image": {"Fn::Sub": "${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/my-app"}},
+          {"Name": "nginx", "Image": "nginx:latest"}
+        ]
+      }
+    }
+  }
+}
+`
+
+func TestLoadCloudFormationTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		check   func([]Resource) bool
+	}{
+		{
+			name:  "YAML template with intrinsic functions",
+			input: testCFNTemplateYAML,
+			check: func(r []Resource) bool {
+				return len(r) == 2 &&
+					r[0].Name == "AppTaskDefinition" && len(r[0].Containers) == 3 &&
+					r[0].Containers[0].Image == "${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/my-app:v1.0" &&
+					r[0].Containers[2].Image == "${ImageParam}" &&
+					r[1].Containers[0].Image == "${AWS::AccountId}.dkr.ecr.amazonaws.com/worker:v1.0"
+			},
+		},
+		{
+			name:  "JSON template",
+			input: testCFNTemplateJSON,
+			check: func(r []Resource) bool {
+				return len(r) == 1 && len(r[0].Containers) == 2 && r[0].Containers[1].Name == "nginx"
+			},
+		},
+		{
+			name:    "Template without task definitions",
+			input:   "Resources:\n  Cluster:\n    Type: AWS::ECS::Cluster\n",
+			wantErr: true,
+		},
+		{
+			name:    "Not a template",
+			input:   `[{"name": "web"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadCloudFormationTemplate(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadCloudFormationTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				if !tt.check(doc.Resources()) {
+					t.Errorf("LoadCloudFormationTemplate() result does not match expected: %+v", doc.Resources())
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateCloudFormationTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    UpdateOptions
+		wantErr bool
+		want    []string
+	}{
+		{
+			name:  "Update Fn::Sub image in place",
+			input: testCFNTemplateYAML,
+			opts:  UpdateOptions{Tag: "v2.0", ContainerName: "web"},
+			want: []string{
+				`Image: !Sub "${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/my-app:v2.0" # app`,
+				"Image: nginx:latest",
+			},
+		},
+		{
+			name:  "Update Fn::Join image",
+			input: testCFNTemplateYAML,
+			opts:  UpdateOptions{Tag: "v2.0", ContainerName: "worker"},
+			want:  []string{`".dkr.ecr.amazonaws.com/worker:v2.0"`},
+		},
+		{
+			name:  "Filter by resource and image",
+			input: testCFNTemplateYAML,
+			opts:  UpdateOptions{Tag: "stable", ImageName: "nginx", Resource: "AppTaskDefinition"},
+			want:  []string{"Image: nginx:stable", "my-app:v1.0"},
+		},
+		{
+			name:  "Add tag to JSON template image without tag",
+			input: testCFNTemplateJSON,
+			opts:  UpdateOptions{Tag: "v2.0"},
+			want: []string{
+				`{"Name": "web", "Image": {"Fn::Sub": "${AWS::AccountId}.dkr.ecr.${AWS::Region}.amazonaws.com/my-app:v2.0"}}`,
+				`{"Name": "nginx", "Image": "nginx:v2.0"}`,
+			},
+		},
+		{
+			name:    "Error: Ref image",
+			input:   testCFNTemplateYAML,
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "param"},
+			wantErr: true,
+		},
+		{
+			name:    "Error: tag is a substitution",
+			input:   testCFNTemplateYAML,
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "sub-tag"},
+			wantErr: true,
+		},
+		{
+			name:    "Error: resource not found",
+			input:   testCFNTemplateYAML,
+			opts:    UpdateOptions{Tag: "v2.0", Resource: "Missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadCloudFormationTemplate(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("LoadCloudFormationTemplate() error = %v", err)
+			}
+			_, err = doc.Update(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if string(doc.Bytes()) != tt.input {
+					t.Errorf("Update() modified the document on error")
+				}
+				return
+			}
+			result := string(doc.Bytes())
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("Update() result should contain %q, got:\n%s", w, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package taskdef
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeServicesResource is the resource name under which compose services are reported
+const composeServicesResource = "services"
+
+// LoadCompose loads a docker-compose (or ECS Compose-X) file from a reader
+func LoadCompose(r io.Reader) (*EmbeddedDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return newEmbeddedDocument(ModeCompose, data, parseCompose)
+}
+
+// parseCompose finds the images of compose services. Each service is treated as a container
+// named after the service; services without an image (build only) are skipped.
+func parseCompose(src []byte) ([]imageSite, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("input must be a single compose file")
+	}
+
+	services := mappingValue(docs[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("compose file has no services section")
+	}
+
+	var sites []imageSite
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		image := mappingValue(services.Content[i+1], "image")
+		if image == nil || image.Kind != yaml.ScalarNode {
+			continue
+		}
+		site := imageSite{resource: composeServicesResource, container: services.Content[i].Value, image: image.Value}
+		if isStringScalar(image) {
+			site.literal, _ = locateScalar(src, image)
+		}
+		sites = append(sites, site)
+	}
+
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("no services with an image found in compose file")
+	}
+	return sites, nil
+}
@@ -0,0 +1,120 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCompose = `name: my-app
+
+x-common: &common
+  restart: always
+
+services:
+  web:
+    <<: *common
+    image: 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.0 # app
+    ports:
+      - "80:80"
+  nginx:
+    image: "nginx:latest"
+  builder:
+    build: .
+  env:
+    image: ${REGISTRY}/env:${TAG:-latest}
+`
+
+func TestLoadCompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		check   func([]Resource) bool
+	}{
+		{
+			name:  "Compose file with services",
+			input: testCompose,
+			check: func(r []Resource) bool {
+				return len(r) == 1 && r[0].Name == "services" && len(r[0].Containers) == 3 &&
+					r[0].Containers[0].Name == "web" &&
+					r[0].Containers[1].Image == "nginx:latest" &&
+					r[0].Containers[2].Image == "${REGISTRY}/env:${TAG:-latest}"
+			},
+		},
+		{
+			name:    "No services",
+			input:   "version: '3'\n",
+			wantErr: true,
+		},
+		{
+			name:    "Only build services",
+			input:   "services:\n  web:\n    build: .\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadCompose(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadCompose() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				if !tt.check(doc.Resources()) {
+					t.Errorf("LoadCompose() result does not match expected: %+v", doc.Resources())
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateCompose(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    UpdateOptions
+		wantErr bool
+		want    []string
+	}{
+		{
+			name: "Update service by name",
+			opts: UpdateOptions{Tag: "v2.0", ContainerName: "web"},
+			want: []string{"image: 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v2.0 # app", `image: "nginx:latest"`},
+		},
+		{
+			name: "Update by image filter keeps quoting",
+			opts: UpdateOptions{Tag: "stable", ImageName: "nginx"},
+			want: []string{`image: "nginx:stable"`, "my-app:v1.0"},
+		},
+		{
+			name:    "Error: tag is a variable",
+			opts:    UpdateOptions{Tag: "v2.0"},
+			wantErr: true,
+		},
+		{
+			name:    "Error: service not found",
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "builder"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadCompose(strings.NewReader(testCompose))
+			if err != nil {
+				t.Fatalf("LoadCompose() error = %v", err)
+			}
+			_, err = doc.Update(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			result := string(doc.Bytes())
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("Update() result should contain %q, got:\n%s", w, result)
+				}
+			}
+		})
+	}
+}
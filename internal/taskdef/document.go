@@ -0,0 +1,181 @@
+package taskdef
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Encoding represents the serialization used to write task and container definitions
+type Encoding string
+
+const (
+	EncodingJSON Encoding = "json"
+	EncodingYAML Encoding = "yaml"
+)
+
+// Document represents a loaded input of any mode. Commands work on documents so that
+// new input kinds only need a Document implementation.
+type Document interface {
+	// Mode returns the mode the document was loaded in
+	Mode() LoadMode
+	// Source returns the file the document was loaded from, or "" for stdin
+	Source() string
+	// Containers returns the containers of the document. For task and container documents the
+	// slice shares storage with the document, so changes to its elements are kept.
+	Containers() []ContainerDefinition
+	// Update updates the container image tags in the document. Match indexes in the result
+	// refer to Containers().
+	Update(opts UpdateOptions) (*UpdateResult, error)
+	// Encode writes the document. Task and container definitions are written with enc;
+	// embedded documents are written back in their original format.
+	Encode(w io.Writer, enc Encoding) error
+}
+
+// TaskDocument is a Document holding a task definition
+type TaskDocument struct {
+	TaskDefinition *TaskDefinition
+	source         string
+}
+
+// Mode returns ModeTask
+func (d *TaskDocument) Mode() LoadMode {
+	return ModeTask
+}
+
+// Source returns the file the document was loaded from, or "" for stdin
+func (d *TaskDocument) Source() string {
+	return d.source
+}
+
+// Containers returns the container definitions of the task definition
+func (d *TaskDocument) Containers() []ContainerDefinition {
+	return d.TaskDefinition.ContainerDefinitions
+}
+
+// Update updates the container image tags in the task definition
+func (d *TaskDocument) Update(opts UpdateOptions) (*UpdateResult, error) {
+	return Update(d.TaskDefinition.ContainerDefinitions, opts)
+}
+
+// Encode writes the full task definition
+func (d *TaskDocument) Encode(w io.Writer, enc Encoding) error {
+	return Encode(w, d.TaskDefinition, enc)
+}
+
+// ContainerDocument is a Document holding a list of container definitions
+type ContainerDocument struct {
+	ContainerDefinitions []ContainerDefinition
+	source               string
+}
+
+// Mode returns ModeContainer
+func (d *ContainerDocument) Mode() LoadMode {
+	return ModeContainer
+}
+
+// Source returns the file the document was loaded from, or "" for stdin
+func (d *ContainerDocument) Source() string {
+	return d.source
+}
+
+// Containers returns the container definitions
+func (d *ContainerDocument) Containers() []ContainerDefinition {
+	return d.ContainerDefinitions
+}
+
+// Update updates the container image tags in the container definitions
+func (d *ContainerDocument) Update(opts UpdateOptions) (*UpdateResult, error) {
+	return Update(d.ContainerDefinitions, opts)
+}
+
+// Encode writes the full container definitions
+func (d *ContainerDocument) Encode(w io.Writer, enc Encoding) error {
+	return Encode(w, d.ContainerDefinitions, enc)
+}
+
+// Mode returns the mode the document was loaded in
+func (d *EmbeddedDocument) Mode() LoadMode {
+	return d.mode
+}
+
+// Source returns the file the document was loaded from, or "" for stdin
+func (d *EmbeddedDocument) Source() string {
+	return d.source
+}
+
+// Containers returns the containers of all resources in document order. The containers are
+// copies; use Update to change the document.
+func (d *EmbeddedDocument) Containers() []ContainerDefinition {
+	containers := make([]ContainerDefinition, len(d.sites))
+	for i, s := range d.sites {
+		containers[i] = ContainerDefinition{Name: s.container, Image: s.image}
+	}
+	return containers
+}
+
+// Encode writes the source of the document including any updates. enc is ignored.
+func (d *EmbeddedDocument) Encode(w io.Writer, enc Encoding) error {
+	_, err := w.Write(d.src)
+	return err
+}
+
+// Encode writes task or container definitions as JSON or YAML
+func Encode(w io.Writer, v interface{}, enc Encoding) error {
+	switch enc {
+	case EncodingJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		// Keep image placeholders such as <IMAGE1_NAME> as-is for CodeDeploy
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(v)
+	case EncodingYAML:
+		encoder := yaml.NewEncoder(w)
+		defer func() {
+			if err := encoder.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to close YAML encoder: %v\n", err)
+			}
+		}()
+		return encoder.Encode(v)
+	default:
+		return fmt.Errorf("unsupported output format: %s", enc)
+	}
+}
+
+// LoadDocument loads a document from a reader based on mode and options.
+// source is the file name recorded in the document and in parse errors, or "" for stdin.
+func LoadDocument(r io.Reader, source string, mode LoadMode, opts LoadOptions) (Document, error) {
+	data, err := LoadWithOptions(r, mode, opts)
+	if err != nil {
+		return nil, withFile(err, source)
+	}
+	return newDocument(data, source)
+}
+
+// LoadDocumentFromFile loads a document from a file based on mode and options
+func LoadDocumentFromFile(filename string, mode LoadMode, opts LoadOptions) (Document, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return LoadDocument(bytes.NewReader(data), filename, mode, opts)
+}
+
+// newDocument wraps the result of Load in a Document
+func newDocument(data interface{}, source string) (Document, error) {
+	switch v := data.(type) {
+	case *TaskDefinition:
+		return &TaskDocument{TaskDefinition: v, source: source}, nil
+	case []ContainerDefinition:
+		return &ContainerDocument{ContainerDefinitions: v, source: source}, nil
+	case *EmbeddedDocument:
+		v.source = source
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported document type %T", data)
+	}
+}
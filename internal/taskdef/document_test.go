@@ -0,0 +1,85 @@
+package taskdef
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLoadDocument(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       LoadMode
+		input      string
+		containers int
+		expected   string
+	}{
+		{
+			name:       "Task definition",
+			mode:       ModeTask,
+			input:      `{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "my-app:v1"}]}`,
+			containers: 1,
+			expected:   `"image": "my-app:v2"`,
+		},
+		{
+			name:       "Container definitions",
+			mode:       ModeContainer,
+			input:      `[{"name": "web", "image": "my-app:v1"}, {"name": "nginx", "image": "nginx:1.25"}]`,
+			containers: 2,
+			expected:   `"image": "my-app:v2"`,
+		},
+		{
+			name:       "Compose file",
+			mode:       ModeCompose,
+			input:      "services:\n  web:\n    image: my-app:v1 # app\n",
+			containers: 1,
+			expected:   "image: my-app:v2 # app\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadDocument(strings.NewReader(tt.input), "input", tt.mode, LoadOptions{})
+			if err != nil {
+				t.Fatalf("LoadDocument() error = %v", err)
+			}
+			if doc.Mode() != tt.mode || doc.Source() != "input" {
+				t.Errorf("LoadDocument() mode = %s, source = %q", doc.Mode(), doc.Source())
+			}
+			if len(doc.Containers()) != tt.containers {
+				t.Errorf("Containers() = %+v, expected %d containers", doc.Containers(), tt.containers)
+			}
+
+			if _, err := doc.Update(UpdateOptions{Tag: "v2", ImageName: "my-app"}); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			buf := &bytes.Buffer{}
+			if err := doc.Encode(buf, EncodingJSON); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("Encode() output does not contain %q:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestDocumentContainersShareStorage(t *testing.T) {
+	doc, err := LoadDocument(strings.NewReader(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "<IMAGE1_NAME>"}]}`), "", ModeTask, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadDocument() error = %v", err)
+	}
+	if err := RenderPlaceholders(doc.Containers(), map[string]string{"IMAGE1_NAME": "my-app:v1"}); err != nil {
+		t.Fatalf("RenderPlaceholders() error = %v", err)
+	}
+	if image := doc.(*TaskDocument).TaskDefinition.ContainerDefinitions[0].Image; image != "my-app:v1" {
+		t.Errorf("rendered image = %q, expected my-app:v1", image)
+	}
+}
+
+func TestLoadDocumentParseErrorFile(t *testing.T) {
+	_, err := LoadDocument(strings.NewReader(`{"family": }`), "task.json", ModeTask, LoadOptions{})
+	if err == nil || !strings.HasPrefix(err.Error(), "task.json:1:") {
+		t.Errorf("LoadDocument() error = %v, expected a position in task.json", err)
+	}
+}
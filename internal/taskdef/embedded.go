@@ -0,0 +1,245 @@
+package taskdef
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resource represents a group of containers found under a single resource of an embedded document
+type Resource struct {
+	Name       string
+	Containers []ContainerDefinition
+}
+
+// span represents a byte range in a source document
+type span struct {
+	start int
+	end   int
+}
+
+// imageSite represents a container image found in an embedded document
+type imageSite struct {
+	resource  string
+	container string
+	// image is the rendered image; non-literal parts are written as ${...}
+	image string
+	// literal locates the source text holding the literal suffix of image (nil if there is none)
+	literal *span
+}
+
+// EmbeddedDocument represents a document that embeds container definitions in another format,
+// such as a CloudFormation template. Updates rewrite only the affected image values in the source.
+type EmbeddedDocument struct {
+	mode   LoadMode
+	source string
+	src    []byte
+	sites  []imageSite
+	parse  func(src []byte) ([]imageSite, error)
+}
+
+// newEmbeddedDocument parses src with the given parser and creates a document
+func newEmbeddedDocument(mode LoadMode, src []byte, parse func([]byte) ([]imageSite, error)) (*EmbeddedDocument, error) {
+	sites, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return &EmbeddedDocument{mode: mode, src: src, sites: sites, parse: parse}, nil
+}
+
+// Bytes returns the source of the document including any updates
+func (d *EmbeddedDocument) Bytes() []byte {
+	return d.src
+}
+
+// Resources returns the containers of the document grouped by resource, in document order
+func (d *EmbeddedDocument) Resources() []Resource {
+	var resources []Resource
+	index := make(map[string]int)
+	for _, s := range d.sites {
+		i, ok := index[s.resource]
+		if !ok {
+			i = len(resources)
+			index[s.resource] = i
+			resources = append(resources, Resource{Name: s.resource})
+		}
+		resources[i].Containers = append(resources[i].Containers, ContainerDefinition{Name: s.container, Image: s.image})
+	}
+	return resources
+}
+
+// Update updates the container image tags in the document. Match indexes refer to Containers().
+func (d *EmbeddedDocument) Update(opts UpdateOptions) (*UpdateResult, error) {
+	var sites []imageSite
+	var indexes []int
+	for i, s := range d.sites {
+		if opts.Resource == "" || s.resource == opts.Resource {
+			sites = append(sites, s)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(sites) == 0 && opts.Resource != "" {
+		return nil, fmt.Errorf("resource '%s' not found in document", opts.Resource)
+	}
+
+	containers := make([]ContainerDefinition, len(sites))
+	for i, s := range sites {
+		containers[i] = ContainerDefinition{Name: s.container, Image: s.image}
+	}
+	result, err := Update(containers, opts)
+	if err != nil {
+		return result, err
+	}
+	for i := range result.Matches {
+		m := &result.Matches[i]
+		m.Resource = sites[m.Index].resource
+		m.Index = indexes[m.Index]
+	}
+
+	edits := make(map[span]string)
+	for i, c := range containers {
+		s := sites[i]
+		if c.Image == s.image {
+			continue
+		}
+		newText, err := d.literalReplacement(s, c.Image)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := edits[*s.literal]; ok && prev != newText {
+			return nil, fmt.Errorf("cannot shift container '%s' in '%s': its image is shared with another container", s.container, s.resource)
+		}
+		edits[*s.literal] = newText
+	}
+
+	src := replaceSpans(d.src, edits)
+	newSites, err := d.parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-read updated document: %w", err)
+	}
+	d.src = src
+	d.sites = newSites
+	return result, nil
+}
+
+// literalReplacement returns the new source text for the literal part of a site's image
+func (d *EmbeddedDocument) literalReplacement(s imageSite, newImage string) (string, error) {
+	if s.literal == nil {
+		return "", fmt.Errorf("cannot shift container '%s' in '%s': image is not a literal string", s.container, s.resource)
+	}
+	if _, tag := parseImage(s.image); strings.Contains(tag, "${") {
+		return "", fmt.Errorf("cannot shift container '%s' in '%s': tag of image '%s' is not a literal", s.container, s.resource, s.image)
+	}
+	prefix := s.image[:len(s.image)-(s.literal.end-s.literal.start)]
+	if !strings.HasPrefix(newImage, prefix) {
+		return "", fmt.Errorf("cannot shift container '%s' in '%s': tag of image '%s' is not a literal suffix", s.container, s.resource, s.image)
+	}
+	return newImage[len(prefix):], nil
+}
+
+// replaceSpans returns a copy of src with each span replaced by its new text
+func replaceSpans(src []byte, edits map[span]string) []byte {
+	spans := make([]span, 0, len(edits))
+	for sp := range edits {
+		spans = append(spans, sp)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var buf bytes.Buffer
+	last := 0
+	for _, sp := range spans {
+		buf.Write(src[last:sp.start])
+		buf.WriteString(edits[sp])
+		last = sp.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+// parseYAMLDocuments parses every YAML (or JSON) document in src
+func parseYAMLDocuments(src []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(doc.Content) > 0 {
+			docs = append(docs, doc.Content[0])
+		}
+	}
+}
+
+// resolveAlias follows YAML aliases to the node they refer to
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// mappingValue returns the value for key in a YAML mapping node, or nil if not present
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	n = resolveAlias(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolveAlias(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// isStringScalar reports whether n is an untagged string scalar
+func isStringScalar(n *yaml.Node) bool {
+	return n != nil && n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str"
+}
+
+// nodeOffset returns the byte offset in src at which a node starts
+func nodeOffset(src []byte, n *yaml.Node) (int, error) {
+	offset := 0
+	for line := 1; line < n.Line; line++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return 0, fmt.Errorf("line %d is out of range", n.Line)
+		}
+		offset += i + 1
+	}
+
+	// Columns count characters, not bytes
+	for col := 1; col < n.Column && offset < len(src) && src[offset] != '\n'; col++ {
+		_, size := utf8.DecodeRune(src[offset:])
+		offset += size
+	}
+	return offset, nil
+}
+
+// locateScalar finds the source text of a single-line scalar node
+func locateScalar(src []byte, n *yaml.Node) (*span, error) {
+	offset, err := nodeOffset(src, n)
+	if err != nil {
+		return nil, err
+	}
+	lineEnd := len(src)
+	if i := bytes.IndexByte(src[offset:], '\n'); i >= 0 {
+		lineEnd = offset + i
+	}
+
+	i := bytes.Index(src[offset:lineEnd], []byte(n.Value))
+	if i < 0 || n.Value == "" {
+		return nil, fmt.Errorf("cannot locate value %q at line %d", n.Value, n.Line)
+	}
+	return &span{start: offset + i, end: offset + i + len(n.Value)}, nil
+}
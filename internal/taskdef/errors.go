@@ -0,0 +1,159 @@
+package taskdef
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError represents an error in a JSON or JSONC input, located in the original source
+type ParseError struct {
+	// File is the input file name, or empty for stdin
+	File   string
+	Line   int
+	Column int
+	// Path is the JSON path of the offending field, if known
+	Path    string
+	Message string
+	// Snippet is the offending source line
+	Snippet string
+}
+
+func (e *ParseError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<stdin>"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d:%d: ", file, e.Line, e.Column)
+	if e.Path != "" {
+		fmt.Fprintf(&b, "%s: ", e.Path)
+	}
+	b.WriteString(e.Message)
+
+	if e.Snippet != "" {
+		gutter := fmt.Sprintf("%d", e.Line)
+		fmt.Fprintf(&b, "\n %s | %s", gutter, e.Snippet)
+		fmt.Fprintf(&b, "\n %s | %s^", strings.Repeat(" ", len(gutter)), caretIndent(e.Snippet, e.Column))
+	}
+	return b.String()
+}
+
+// caretIndent returns the whitespace that puts a caret under the given column of a line,
+// keeping tabs so that the caret lines up
+func caretIndent(line string, column int) string {
+	var b strings.Builder
+	col := 1
+	for _, r := range line {
+		if col >= column {
+			break
+		}
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+		col++
+	}
+	return b.String()
+}
+
+// newParseError locates a JSON decoding error in the original source. The error offsets refer
+// to the comment-stripped input, which keeps the byte offsets of data (see removeJSONComments).
+func newParseError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		// Offset points just past the offending byte, or at the end for truncated input
+		offset := int(syntaxErr.Offset) - 1
+		if syntaxErr.Error() == "unexpected end of JSON input" {
+			offset = len(bytes.TrimRight(removeJSONComments(data), " \t\r\n"))
+		}
+		return parseErrorAt(data, offset, "", syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("cannot use %s as %s", typeErr.Value, typeErr.Type)
+		if s, scanErr := scanJSONC(data, nil); scanErr == nil {
+			if path, sp, ok := s.pathAt(int(typeErr.Offset) - 1); ok {
+				return parseErrorAt(data, sp.start, path, message)
+			}
+		}
+		path := ""
+		if typeErr.Field != "" {
+			path = "$." + typeErr.Field
+		}
+		return parseErrorAt(data, int(typeErr.Offset)-1, path, message)
+	default:
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+}
+
+// parseErrorAt creates a ParseError for a byte offset in data
+func parseErrorAt(data []byte, offset int, path, message string) *ParseError {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	lineEnd := len(data)
+	if i := bytes.IndexByte(data[lineStart:], '\n'); i >= 0 {
+		lineEnd = lineStart + i
+	}
+
+	return &ParseError{
+		Line:    bytes.Count(data[:lineStart], []byte("\n")) + 1,
+		Column:  utf8.RuneCount(data[lineStart:offset]) + 1,
+		Path:    path,
+		Message: message,
+		Snippet: strings.TrimRight(string(data[lineStart:lineEnd]), "\r"),
+	}
+}
+
+// StrictProblem represents a duplicate or unknown key found in strict mode
+type StrictProblem struct {
+	Path     string
+	Position Position
+	Message  string
+}
+
+// StrictError lists every duplicate and unknown key found in strict mode
+type StrictError struct {
+	// File is the input file name, or empty for stdin
+	File     string
+	Problems []StrictProblem
+}
+
+func (e *StrictError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<stdin>"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "strict mode: %d problem(s) found", len(e.Problems))
+	for _, p := range e.Problems {
+		fmt.Fprintf(&b, "\n  %s:%d:%d: %s: %s", file, p.Position.Line, p.Position.Column, p.Path, p.Message)
+	}
+	return b.String()
+}
+
+// withFile records the input file name in a ParseError or StrictError
+func withFile(err error, filename string) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.File = filename
+	}
+	var strictErr *StrictError
+	if errors.As(err, &strictErr) {
+		strictErr.File = filename
+	}
+	return err
+}
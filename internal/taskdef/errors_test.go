@@ -0,0 +1,123 @@
+package taskdef
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		mode      LoadMode
+		wantLine  int
+		wantCol   int
+		wantPath  string
+		wantMsg   string
+		wantCaret string
+	}{
+		{
+			name:      "Syntax error after comments",
+			input:     "{\n  // \"family\": broken,\n  \"family\": \"x\", /* note */ \"cpu\": ,\n  \"containerDefinitions\": []\n}",
+			mode:      ModeTask,
+			wantLine:  3,
+			wantCol:   36,
+			wantMsg:   "invalid character ','",
+			wantCaret: " 3 |   \"family\": \"x\", /* note */ \"cpu\": ,\n   |                                    ^",
+		},
+		{
+			name:     "Truncated input",
+			input:    "{\n  \"family\": \"x\",\n",
+			mode:     ModeTask,
+			wantLine: 2,
+			wantCol:  17,
+			wantMsg:  "unexpected end of JSON input",
+		},
+		{
+			name:     "Type error in container",
+			input:    "{\n  \"family\": \"x\",\n  \"containerDefinitions\": [\n    {\"name\": \"web\"},\n    {\"name\": \"app\", \"cpu\": \"256\"}\n  ]\n}",
+			mode:     ModeTask,
+			wantLine: 5,
+			wantCol:  28,
+			wantPath: "$.containerDefinitions[1].cpu",
+			wantMsg:  "cannot use string as int",
+		},
+		{
+			name:     "Type error in container mode",
+			input:    `[{"name": "web", "essential": "yes"}]`,
+			mode:     ModeContainer,
+			wantLine: 1,
+			wantCol:  31,
+			wantPath: "$[0].essential",
+			wantMsg:  "cannot use string as bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(tt.input), tt.mode)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Load() error = %v, expected a ParseError", err)
+			}
+			if parseErr.Line != tt.wantLine || parseErr.Column != tt.wantCol {
+				t.Errorf("position = %d:%d, expected %d:%d", parseErr.Line, parseErr.Column, tt.wantLine, tt.wantCol)
+			}
+			if parseErr.Path != tt.wantPath {
+				t.Errorf("path = %q, expected %q", parseErr.Path, tt.wantPath)
+			}
+			if !strings.Contains(parseErr.Message, tt.wantMsg) {
+				t.Errorf("message = %q, expected to contain %q", parseErr.Message, tt.wantMsg)
+			}
+			if tt.wantCaret != "" && !strings.Contains(err.Error(), tt.wantCaret) {
+				t.Errorf("Error() = %q, expected snippet %q", err.Error(), tt.wantCaret)
+			}
+		})
+	}
+}
+
+func TestParseErrorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.json")
+	if err := os.WriteFile(path, []byte("{\n\t\"family\": }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadFromFile(path, ModeTask)
+	if err == nil {
+		t.Fatal("LoadFromFile() expected error")
+	}
+	want := path + ":2:12: invalid character '}' looking for beginning of value\n 2 | \t\"family\": }\n   | \t          ^"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, expected %q", err.Error(), want)
+	}
+
+	_, err = Load(strings.NewReader("{]"), ModeTask)
+	if err == nil || !strings.HasPrefix(err.Error(), "<stdin>:1:2: ") {
+		t.Errorf("Load() error = %v, expected a <stdin> location", err)
+	}
+}
+
+func TestRemoveJSONCommentsKeepsOffsets(t *testing.T) {
+	input := "{\n  /* multi\n     line */ \"a\": \"// not a comment\", // trailing\n  \"b\": \"/* nor this */\"\n}"
+	result := removeJSONComments([]byte(input))
+	if len(result) != len(input) {
+		t.Fatalf("length = %d, expected %d", len(result), len(input))
+	}
+	if strings.Count(string(result), "\n") != strings.Count(input, "\n") {
+		t.Errorf("newlines were not preserved: %q", result)
+	}
+	if strings.Index(string(result), `"a"`) != strings.Index(input, `"a"`) {
+		t.Errorf("offsets were not preserved: %q", result)
+	}
+	for _, s := range []string{"// not a comment", "/* nor this */"} {
+		if !strings.Contains(string(result), s) {
+			t.Errorf("string content %q was removed: %q", s, result)
+		}
+	}
+	if strings.Contains(string(result), "trailing") || strings.Contains(string(result), "multi") {
+		t.Errorf("comments were not removed: %q", result)
+	}
+}
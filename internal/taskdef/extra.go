@@ -0,0 +1,476 @@
+package taskdef
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Task and container definitions, and the types nested in them, keep the fields they do not model
+// in Extra. Every struct with an Extra field is decoded and encoded by the reflection-based codec
+// below (decodeJSON and encodeJSON), which walks the nested types itself, so only the types
+// decoded on their own have methods. Extra fields of nested types are encoded as part of the
+// task or container definition they belong to.
+
+// The plain types have the fields but not the methods of their counterparts, so that they can be
+// encoded to YAML without recursion
+type (
+	plainContainerDefinition ContainerDefinition
+	plainTaskDefinition      TaskDefinition
+)
+
+// UnmarshalJSON decodes a container definition, keeping unknown fields in Extra
+func (c *ContainerDefinition) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+// MarshalJSON encodes a container definition followed by its unknown fields
+func (c ContainerDefinition) MarshalJSON() ([]byte, error) {
+	return encodeJSON(c)
+}
+
+// MarshalYAML encodes a container definition followed by its unknown fields
+func (c ContainerDefinition) MarshalYAML() (interface{}, error) {
+	return marshalYAMLWithExtra(c, plainContainerDefinition(c))
+}
+
+// UnmarshalJSON decodes a task definition, keeping unknown fields in Extra
+func (t *TaskDefinition) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, t)
+}
+
+// MarshalJSON encodes a task definition followed by its unknown fields
+func (t TaskDefinition) MarshalJSON() ([]byte, error) {
+	return encodeJSON(t)
+}
+
+// MarshalYAML encodes a task definition followed by its unknown fields
+func (t TaskDefinition) MarshalYAML() (interface{}, error) {
+	return marshalYAMLWithExtra(t, plainTaskDefinition(t))
+}
+
+// marshalYAMLWithExtra returns plain if v has no extra fields, or else v (which encodes its extra
+// fields to JSON) as a YAML node
+func marshalYAMLWithExtra(v, plain interface{}) (interface{}, error) {
+	if !hasExtraValues(reflect.ValueOf(v)) {
+		return plain, nil
+	}
+	return yamlNodeFromJSON(v)
+}
+
+var extraType = reflect.TypeOf(map[string]interface{}(nil))
+
+// hasExtraField reports whether a struct type keeps its unknown fields in an Extra field
+func hasExtraField(t reflect.Type) bool {
+	f, ok := t.FieldByName("Extra")
+	return ok && f.Type == extraType && f.Tag.Get("json") == "-"
+}
+
+// containsExtraCache maps types to the result of containsExtra
+var containsExtraCache sync.Map
+
+// containsExtra reports whether values of a type contain structs with an Extra field, and so are
+// decoded and encoded by the codec rather than by encoding/json
+func containsExtra(t reflect.Type) bool {
+	if contains, ok := containsExtraCache.Load(t); ok {
+		return contains.(bool)
+	}
+	contains := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		contains = containsExtra(t.Elem())
+	case reflect.Struct:
+		contains = hasExtraField(t)
+		for _, f := range jsonFields(t) {
+			contains = contains || containsExtra(t.Field(f.index).Type)
+		}
+	}
+	containsExtraCache.Store(t, contains)
+	return contains
+}
+
+// jsonField is an exported struct field encoded to JSON
+type jsonField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// fieldCache maps struct types to their JSON fields
+var fieldCache sync.Map
+
+// jsonFields returns the fields of a struct type encoded to JSON, in order
+func jsonFields(t reflect.Type) []jsonField {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]jsonField)
+	}
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{index: i, name: name, omitEmpty: options == "omitempty"})
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// lookupField finds the field of a JSON key, preferring an exact match like encoding/json
+func lookupField(fields []jsonField, key string) (jsonField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return jsonField{}, false
+}
+
+// decodeJSON decodes data into v like json.Unmarshal, keeping unknown members in the Extra fields
+// of the structs it contains. The offsets of type errors, including those in nested values, are
+// relative to data.
+func decodeJSON(data []byte, v interface{}) error {
+	// Report syntax errors like encoding/json
+	if err := json.Unmarshal(data, new(json.RawMessage)); err != nil {
+		return err
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	d := &extraDecoder{}
+	if err := d.decode(trimmed, int64(len(data)-len(trimmed)), reflect.ValueOf(v).Elem(), ""); err != nil {
+		return err
+	}
+	return d.typeErr
+}
+
+// extraDecoder decodes JSON values, keeping the first type error and carrying on like
+// encoding/json
+type extraDecoder struct {
+	typeErr error
+}
+
+// decode decodes raw, found at offset in the input, into v. path is the dotted path of v for
+// type errors.
+func (d *extraDecoder) decode(raw []byte, offset int64, v reflect.Value, path string) error {
+	if !containsExtra(v.Type()) {
+		err := json.Unmarshal(raw, v.Addr().Interface())
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		typeErr.Offset += offset
+		typeErr.Field = joinPath(path, typeErr.Field)
+		d.saveError(typeErr)
+		return nil
+	}
+
+	if string(raw) == "null" {
+		if v.Kind() != reflect.Struct {
+			v.SetZero()
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(raw, offset, v.Elem(), path)
+	case reflect.Slice:
+		if raw[0] != '[' {
+			d.saveError(mismatchError(raw, offset, v.Type(), path))
+			return nil
+		}
+		elems := reflect.MakeSlice(v.Type(), 0, 0)
+		err := eachJSONValue(raw, func(_ string, value []byte, at int64) error {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(value, offset+at, elem, path); err != nil {
+				return err
+			}
+			elems = reflect.Append(elems, elem)
+			return nil
+		})
+		v.Set(elems)
+		return err
+	default:
+		return d.decodeStruct(raw, offset, v, path)
+	}
+}
+
+// decodeStruct decodes a JSON object into a struct, keeping the members that are not fields in
+// Extra if the struct has one
+func (d *extraDecoder) decodeStruct(raw []byte, offset int64, v reflect.Value, path string) error {
+	if raw[0] != '{' {
+		d.saveError(mismatchError(raw, offset, v.Type(), path))
+		return nil
+	}
+	fields := jsonFields(v.Type())
+	var extra map[string]interface{}
+	err := eachJSONValue(raw, func(key string, value []byte, at int64) error {
+		if f, ok := lookupField(fields, key); ok {
+			return d.decode(value, offset+at, v.Field(f.index), joinPath(path, f.name))
+		}
+		// Keep numbers as written, e.g. large integers
+		decoder := json.NewDecoder(bytes.NewReader(value))
+		decoder.UseNumber()
+		var unknown interface{}
+		if err := decoder.Decode(&unknown); err != nil {
+			return err
+		}
+		if extra == nil {
+			extra = make(map[string]interface{})
+		}
+		extra[key] = unknown
+		return nil
+	})
+	if hasExtraField(v.Type()) {
+		v.FieldByName("Extra").Set(reflect.ValueOf(extra))
+	}
+	return err
+}
+
+func (d *extraDecoder) saveError(err error) {
+	if d.typeErr == nil {
+		d.typeErr = err
+	}
+}
+
+// mismatchError reports a JSON value that is not an object or array as expected by t
+func mismatchError(raw []byte, offset int64, t reflect.Type, path string) *json.UnmarshalTypeError {
+	value := "number"
+	switch raw[0] {
+	case '{':
+		value = "object"
+	case '[':
+		value = "array"
+	case '"':
+		value = "string"
+	case 't', 'f':
+		value = "bool"
+	}
+	// Like encoding/json, the offset points just past the start of the value
+	return &json.UnmarshalTypeError{Value: value, Type: t, Offset: offset + 1, Field: path}
+}
+
+// joinPath joins dotted field paths as encoding/json reports them
+func joinPath(path, field string) string {
+	if path == "" || field == "" {
+		return path + field
+	}
+	return path + "." + field
+}
+
+// eachJSONValue calls fn with every member of a JSON object or element of a JSON array and its
+// offset in raw. Keys are empty for arrays.
+func eachJSONValue(raw []byte, fn func(key string, value []byte, offset int64) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key := ""
+		if raw[0] == '{' {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ = token.(string)
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if err := fn(key, value, decoder.InputOffset()-int64(len(value))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeJSON encodes v like marshalJSON, writing the extra fields of every struct it contains
+// after its known fields in key order. Known fields take precedence over extra fields with the
+// same name.
+func encodeJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeValue(buf *bytes.Buffer, v reflect.Value) error {
+	if !containsExtra(v.Type()) {
+		data, err := marshalJSON(v.Interface())
+		buf.Write(data)
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeValue(buf, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeValue(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		return encodeStruct(buf, v)
+	}
+}
+
+// encodeStruct encodes a struct as a JSON object, followed by its extra fields
+func encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	present := make(map[string]bool)
+	writeMember := func(key string, encode func() error) error {
+		if len(present) > 0 {
+			buf.WriteByte(',')
+		}
+		present[key] = true
+		k, err := marshalJSON(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		return encode()
+	}
+
+	buf.WriteByte('{')
+	for _, f := range jsonFields(v.Type()) {
+		field := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(field) {
+			continue
+		}
+		if err := writeMember(f.name, func() error { return encodeValue(buf, field) }); err != nil {
+			return err
+		}
+	}
+
+	if hasExtraField(v.Type()) {
+		extra := v.FieldByName("Extra").Interface().(map[string]interface{})
+		keys := make([]string, 0, len(extra))
+		for key := range extra {
+			if !present[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			err := writeMember(key, func() error {
+				data, err := marshalJSON(extra[key])
+				buf.Write(data)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// isEmptyValue reports whether a field tagged omitempty is omitted, like encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// hasExtraValues reports whether v or any struct it contains has extra fields
+func hasExtraValues(v reflect.Value) bool {
+	if !containsExtra(v.Type()) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		return !v.IsNil() && hasExtraValues(v.Elem())
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if hasExtraValues(v.Index(i)) {
+				return true
+			}
+		}
+		return false
+	default:
+		if hasExtraField(v.Type()) && v.FieldByName("Extra").Len() > 0 {
+			return true
+		}
+		for _, f := range jsonFields(v.Type()) {
+			if hasExtraValues(v.Field(f.index)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// marshalJSON encodes v without escaping HTML characters, so that image placeholders such as
+// <IMAGE1_NAME> are kept as-is
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// yamlNodeFromJSON converts the JSON encoding of v into a YAML node in block style
+func yamlNodeFromJSON(v interface{}) (*yaml.Node, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	clearYAMLStyle(&doc)
+	return doc.Content[0], nil
+}
+
+// clearYAMLStyle resets the flow and quoting styles that JSON input leaves on YAML nodes
+func clearYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearYAMLStyle(c)
+	}
+}
@@ -0,0 +1,157 @@
+package taskdef
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestContainerDefinitionRoundTrip(t *testing.T) {
+	input := `{
+  "name": "web",
+  "image": "<IMAGE1_NAME>",
+  "secrets": [{"name": "DB_PASSWORD", "valueFrom": "arn:aws:secretsmanager:us-east-1:123456789:secret:db"}],
+  "logConfiguration": {"logDriver": "awsfirelens", "options": {"Name": "cloudwatch"}},
+  "healthCheck": {"command": ["CMD-SHELL", "curl -f http://localhost/ || exit 1"], "interval": 30},
+  "dependsOn": [{"containerName": "log_router", "condition": "START"}],
+  "mountPoints": [{"sourceVolume": "data", "containerPath": "/data", "readOnly": true}],
+  "ulimits": [{"name": "nofile", "softLimit": 0, "hardLimit": 65536}],
+  "linuxParameters": {"capabilities": {"drop": ["ALL"]}, "initProcessEnabled": true, "swappiness": 0},
+  "repositoryCredentials": {"credentialsParameter": "arn:aws:secretsmanager:us-east-1:123456789:secret:registry"},
+  "resourceRequirements": [{"value": "1", "type": "GPU"}],
+  "firelensConfiguration": {"type": "fluentbit"},
+  "futureField": {"enabled": true, "limit": 12345678901234567890},
+  "futureList": ["a", "b"]
+}`
+
+	var c ContainerDefinition
+	if err := json.Unmarshal([]byte(input), &c); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if len(c.Secrets) != 1 || c.Secrets[0].ValueFrom == "" {
+		t.Errorf("Secrets = %+v", c.Secrets)
+	}
+	if c.LinuxParameters == nil || c.LinuxParameters.Swappiness == nil || *c.LinuxParameters.Swappiness != 0 || c.LinuxParameters.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("LinuxParameters = %+v", c.LinuxParameters)
+	}
+	if len(c.Ulimits) != 1 || c.Ulimits[0].HardLimit != 65536 {
+		t.Errorf("Ulimits = %+v", c.Ulimits)
+	}
+	if c.DependsOn[0].Condition != "START" || c.FirelensConfiguration.Type != "fluentbit" || c.RepositoryCredentials == nil {
+		t.Errorf("unexpected container definition: %+v", c)
+	}
+	if len(c.Extra) != 2 || c.Extra["futureList"] == nil {
+		t.Fatalf("Extra = %v, expected the two unknown fields", c.Extra)
+	}
+
+	// Encode like the output package does, without escaping HTML characters
+	out, err := marshalJSON(c)
+	if err != nil {
+		t.Fatalf("marshalJSON() error = %v", err)
+	}
+	for _, want := range []string{`"image":"<IMAGE1_NAME>"`, `"futureField":{"enabled":true,"limit":12345678901234567890}`, `"futureList":["a","b"]`, `"softLimit":0`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("marshalJSON() = %s, expected to contain %s", out, want)
+		}
+	}
+
+	var again ContainerDefinition
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatalf("json.Unmarshal() of output error = %v", err)
+	}
+	again2, _ := marshalJSON(again)
+	if string(again2) != string(out) {
+		t.Errorf("round trip is not stable:\n%s\n%s", out, again2)
+	}
+}
+
+func TestTaskDefinitionExtraYAML(t *testing.T) {
+	input := `{"family": "app", "futureTaskField": {"flag": "true", "count": 3}, "containerDefinitions": [{"name": "web", "image": "nginx", "futureField": "x"}]}`
+
+	var td TaskDefinition
+	if err := json.Unmarshal([]byte(input), &td); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	out, err := yaml.Marshal(td)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	for _, want := range []string{"family: app", "futureTaskField:\n    count: 3\n    flag: \"true\"", "futureField: x"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("yaml.Marshal() = %s, expected to contain %q", out, want)
+		}
+	}
+}
+
+func TestMarshalExtraDoesNotOverrideKnownFields(t *testing.T) {
+	c := ContainerDefinition{Name: "web", Image: "nginx", Extra: map[string]interface{}{"image": "other", "zeta": 1}}
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(out) != `{"name":"web","image":"nginx","zeta":1}` {
+		t.Errorf("json.Marshal() = %s", out)
+	}
+}
+
+func TestNestedExtraRoundTrip(t *testing.T) {
+	input := `{"family": "app", "containerDefinitions": [{"name": "web", "image": "nginx",
+  "portMappings": [{"containerPort": 80, "futurePortField": "a"}],
+  "logConfiguration": {"logDriver": "awslogs", "secretOptions": [{"name": "key", "valueFrom": "arn", "futureSecretField": 1}]}}],
+  "volumes": [{"name": "data", "efsVolumeConfiguration": {"fileSystemId": "fs-1", "authorizationConfig": {"iam": "ENABLED", "futureAuthField": true}}}]}`
+
+	var td TaskDefinition
+	if err := json.Unmarshal([]byte(input), &td); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if td.ContainerDefinitions[0].PortMappings[0].Extra["futurePortField"] != "a" {
+		t.Errorf("PortMapping.Extra = %v", td.ContainerDefinitions[0].PortMappings[0].Extra)
+	}
+
+	out, err := marshalJSON(td)
+	if err != nil {
+		t.Fatalf("marshalJSON() error = %v", err)
+	}
+	for _, want := range []string{`"containerPort":80,"futurePortField":"a"`, `"valueFrom":"arn","futureSecretField":1`, `"iam":"ENABLED","futureAuthField":true`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("marshalJSON() = %s, expected to contain %s", out, want)
+		}
+	}
+
+	yamlOut, err := yaml.Marshal(td)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	for _, want := range []string{"futurePortField: a", "futureSecretField: 1", "futureAuthField: true"} {
+		if !strings.Contains(string(yamlOut), want) {
+			t.Errorf("yaml.Marshal() = %s, expected to contain %q", yamlOut, want)
+		}
+	}
+}
+
+func TestNestedTypeErrorPosition(t *testing.T) {
+	input := "{\"family\": \"app\", \"containerDefinitions\": [{\"name\": \"web\", \"image\": \"nginx\",\n  \"portMappings\": [{\"containerPort\": \"80\"}]}]}"
+	_, err := LoadTaskDefinition(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), ":2:") || !strings.Contains(err.Error(), "$.containerDefinitions[0].portMappings[0].containerPort") {
+		t.Errorf("LoadTaskDefinition() error = %v, expected the line and path of containerPort", err)
+	}
+}
+
+func TestTypeErrorPositionWithRepeatedObject(t *testing.T) {
+	// The unknown field holds a copy of the invalid container, which must not be reported instead
+	input := "{\"family\": \"app\",\n  \"futureCopy\": {\"name\": \"web\", \"memory\": \"512\"},\n  \"containerDefinitions\": [{\"name\": \"web\", \"memory\": \"512\"}]}"
+	_, err := LoadTaskDefinition(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), ":3:") || !strings.Contains(err.Error(), "$.containerDefinitions[0].memory") {
+		t.Errorf("LoadTaskDefinition() error = %v, expected the line and path of the container's memory", err)
+	}
+
+	input = "{\"taskDefinition\": {\"family\": \"app\",\n  \"containerDefinitions\": [{\"name\": \"web\", \"portMappings\": [{\"containerPort\": 80}, {\"containerPort\": 80}]},\n    {\"name\": \"web\", \"portMappings\": [{\"containerPort\": 80}, {\"containerPort\": \"80\"}]}]}}"
+	_, err = LoadTaskDefinition(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), ":3:") || !strings.Contains(err.Error(), "$.taskDefinition.containerDefinitions[1].portMappings[1].containerPort") {
+		t.Errorf("LoadTaskDefinition() error = %v, expected the line and path of the second container's port", err)
+	}
+}
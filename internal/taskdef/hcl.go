@@ -0,0 +1,363 @@
+package taskdef
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// hclTokenKind represents the kind of an HCL token
+type hclTokenKind int
+
+const (
+	hclIdent hclTokenKind = iota
+	hclString
+	hclHeredoc
+	hclNumber
+	hclNewline
+	hclPunct
+	hclEOF
+)
+
+// hclToken represents an HCL token. pos covers the whole token; content covers the text
+// between the quotes of a string or the body of a heredoc.
+type hclToken struct {
+	kind    hclTokenKind
+	text    string
+	pos     span
+	content span
+}
+
+// lexHCL splits HCL source into tokens. Comments are dropped and newlines are kept,
+// as they separate attributes.
+func lexHCL(src []byte) ([]hclToken, error) {
+	var tokens []hclToken
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '\n':
+			tokens = append(tokens, hclToken{kind: hclNewline, text: "\n", pos: span{i, i + 1}})
+			i++
+		case c == '#' || (c == '/' && i+1 < len(src) && src[i+1] == '/'):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 4
+		case c == '"':
+			end, err := scanHCLString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, hclToken{kind: hclString, pos: span{i, end}, content: span{i + 1, end - 1}})
+			i = end
+		case c == '<' && i+1 < len(src) && src[i+1] == '<':
+			tok, err := scanHCLHeredoc(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.pos.end
+		case isHCLIdentStart(c):
+			start := i
+			for i < len(src) && (isHCLIdentStart(src[i]) || (src[i] >= '0' && src[i] <= '9') || src[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, hclToken{kind: hclIdent, text: string(src[start:i]), pos: span{start, i}})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && ((src[i] >= '0' && src[i] <= '9') || src[i] == '.' || src[i] == 'e' || src[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, hclToken{kind: hclNumber, text: string(src[start:i]), pos: span{start, i}})
+		default:
+			tokens = append(tokens, hclToken{kind: hclPunct, text: string(c), pos: span{i, i + 1}})
+			i++
+		}
+	}
+	tokens = append(tokens, hclToken{kind: hclEOF, pos: span{len(src), len(src)}})
+	return tokens, nil
+}
+
+// isHCLIdentStart reports whether c can start an identifier
+func isHCLIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// scanHCLString scans a quoted template starting at its opening quote and returns
+// the offset just past the closing quote
+func scanHCLString(src []byte, i int) (int, error) {
+	start := i
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		case '\n':
+			return 0, fmt.Errorf("unterminated string at offset %d", start)
+		case '$', '%':
+			if i+2 < len(src) && src[i+1] == src[i] && src[i+2] == '{' {
+				// "$${" and "%%{" are escaped literals
+				i += 2
+				continue
+			}
+			if i+1 < len(src) && src[i+1] == '{' {
+				end, err := skipHCLInterpolation(src, i+2)
+				if err != nil {
+					return 0, err
+				}
+				i = end - 1
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// skipHCLInterpolation returns the offset just past the brace closing a template
+// interpolation whose expression starts at i
+func skipHCLInterpolation(src []byte, i int) (int, error) {
+	start := i
+	depth := 1
+	for i < len(src) {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		case '"':
+			end, err := scanHCLString(src, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return 0, fmt.Errorf("unterminated interpolation at offset %d", start-2)
+}
+
+// scanHCLHeredoc scans a heredoc (<<EOT or <<-EOT) starting at its "<<"
+func scanHCLHeredoc(src []byte, i int) (hclToken, error) {
+	start := i
+	i += 2
+	if i < len(src) && src[i] == '-' {
+		i++
+	}
+	markerStart := i
+	for i < len(src) && (isHCLIdentStart(src[i]) || (src[i] >= '0' && src[i] <= '9')) {
+		i++
+	}
+	marker := string(src[markerStart:i])
+	nl := bytes.IndexByte(src[i:], '\n')
+	if marker == "" || nl < 0 {
+		return hclToken{}, fmt.Errorf("invalid heredoc at offset %d", start)
+	}
+
+	contentStart := i + nl + 1
+	for lineStart := contentStart; lineStart < len(src); {
+		lineEnd := len(src)
+		if n := bytes.IndexByte(src[lineStart:], '\n'); n >= 0 {
+			lineEnd = lineStart + n
+		}
+		if string(bytes.TrimSpace(src[lineStart:lineEnd])) == marker {
+			end := lineStart + bytes.Index(src[lineStart:lineEnd], []byte(marker)) + len(marker)
+			return hclToken{kind: hclHeredoc, pos: span{start, end}, content: span{contentStart, lineStart}}, nil
+		}
+		lineStart = lineEnd + 1
+	}
+	return hclToken{}, fmt.Errorf("unterminated heredoc %s at offset %d", marker, start)
+}
+
+// hclValueKind represents the kind of a parsed HCL expression
+type hclValueKind int
+
+const (
+	hclValueString hclValueKind = iota
+	hclValueHeredoc
+	hclValueList
+	hclValueObject
+	hclValueOther
+)
+
+// hclValue represents a parsed HCL expression. Only strings, heredocs, tuples and objects
+// are modelled; anything else (references, function calls, operators) is kept as raw source.
+type hclValue struct {
+	kind    hclValueKind
+	pos     span
+	content span
+	items   []*hclValue
+	fields  []hclField
+}
+
+// hclField represents an attribute of an HCL object
+type hclField struct {
+	key   string
+	value *hclValue
+}
+
+// field returns the value of an object attribute, or nil if not present
+func (v *hclValue) field(key string) *hclValue {
+	for _, f := range v.fields {
+		if f.key == key {
+			return f.value
+		}
+	}
+	return nil
+}
+
+// hclParser parses HCL expressions from a token stream
+type hclParser struct {
+	src    []byte
+	tokens []hclToken
+	pos    int
+}
+
+func (p *hclParser) peek() hclToken {
+	return p.tokens[p.pos]
+}
+
+func (p *hclParser) next() hclToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != hclEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *hclParser) skipNewlines() {
+	for p.peek().kind == hclNewline {
+		p.pos++
+	}
+}
+
+// isPunct reports whether tok is the given punctuation
+func (tok hclToken) isPunct(s string) bool {
+	return tok.kind == hclPunct && tok.text == s
+}
+
+// atValueEnd reports whether the next token terminates an expression
+func (p *hclParser) atValueEnd() bool {
+	tok := p.peek()
+	switch tok.kind {
+	case hclNewline, hclEOF:
+		return true
+	case hclPunct:
+		return tok.text == "," || tok.text == "]" || tok.text == "}" || tok.text == ")"
+	}
+	return false
+}
+
+// parseValue parses an expression
+func (p *hclParser) parseValue() (*hclValue, error) {
+	start := p.pos
+	tok := p.peek()
+
+	var v *hclValue
+	var err error
+	// Tuples and objects that cannot be modelled (e.g. for expressions) fall back to raw source
+	switch {
+	case tok.isPunct("["):
+		v, err = p.parseList()
+	case tok.isPunct("{"):
+		v, err = p.parseObject()
+	case tok.kind == hclString:
+		p.next()
+		v = &hclValue{kind: hclValueString, pos: tok.pos, content: tok.content}
+	case tok.kind == hclHeredoc:
+		p.next()
+		v = &hclValue{kind: hclValueHeredoc, pos: tok.pos, content: tok.content}
+	}
+	if err == nil && v != nil && p.atValueEnd() {
+		return v, nil
+	}
+
+	// Anything else is kept as raw source up to the end of the expression
+	p.pos = start
+	depth := 0
+	for depth > 0 || !p.atValueEnd() {
+		tok := p.next()
+		switch {
+		case tok.kind == hclEOF:
+			return nil, fmt.Errorf("unexpected end of input")
+		case tok.isPunct("(") || tok.isPunct("[") || tok.isPunct("{"):
+			depth++
+		case tok.isPunct(")") || tok.isPunct("]") || tok.isPunct("}"):
+			depth--
+		}
+	}
+	return &hclValue{kind: hclValueOther, pos: span{p.tokens[start].pos.start, p.tokens[p.pos-1].pos.end}}, nil
+}
+
+// parseList parses a tuple expression
+func (p *hclParser) parseList() (*hclValue, error) {
+	start := p.next()
+	v := &hclValue{kind: hclValueList}
+	for {
+		p.skipNewlines()
+		if p.peek().isPunct("]") {
+			break
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		v.items = append(v.items, item)
+		p.skipNewlines()
+		if p.peek().isPunct(",") {
+			p.next()
+		} else if !p.peek().isPunct("]") {
+			return nil, fmt.Errorf("expected ',' or ']' at offset %d", p.peek().pos.start)
+		}
+	}
+	end := p.next()
+	v.pos = span{start.pos.start, end.pos.end}
+	return v, nil
+}
+
+// parseObject parses an object expression
+func (p *hclParser) parseObject() (*hclValue, error) {
+	start := p.next()
+	v := &hclValue{kind: hclValueObject}
+	for {
+		for p.peek().kind == hclNewline || p.peek().isPunct(",") {
+			p.next()
+		}
+		if p.peek().isPunct("}") {
+			break
+		}
+
+		keyTok := p.next()
+		var key string
+		switch keyTok.kind {
+		case hclIdent:
+			key = keyTok.text
+		case hclString:
+			key = string(p.src[keyTok.content.start:keyTok.content.end])
+		default:
+			return nil, fmt.Errorf("unexpected object key at offset %d", keyTok.pos.start)
+		}
+		if sep := p.next(); !sep.isPunct("=") && !sep.isPunct(":") {
+			return nil, fmt.Errorf("expected '=' or ':' after object key at offset %d", sep.pos.start)
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		v.fields = append(v.fields, hclField{key: key, value: value})
+	}
+	end := p.next()
+	v.pos = span{start.pos.start, end.pos.end}
+	return v, nil
+}
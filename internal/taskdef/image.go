@@ -0,0 +1,38 @@
+package taskdef
+
+import "strings"
+
+// ImageReference represents a parsed container image reference
+type ImageReference struct {
+	// Repository includes the registry host, e.g. "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app"
+	Repository string `json:"repository" yaml:"repository"`
+	Tag        string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+// ParseImageReference parses an image reference of the form repository[:tag][@digest]
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+	if idx := strings.LastIndex(image, "@"); idx != -1 && !strings.Contains(image[idx:], "}") {
+		image, ref.Digest = image[:idx], image[idx+1:]
+	}
+	ref.Repository, ref.Tag = parseImage(image)
+	return ref
+}
+
+// Name returns the repository name without the registry and namespace, e.g. "my-app"
+func (r ImageReference) Name() string {
+	return r.Repository[strings.LastIndex(r.Repository, "/")+1:]
+}
+
+// String returns the image reference in repository[:tag][@digest] form
+func (r ImageReference) String() string {
+	s := r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
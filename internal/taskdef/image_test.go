@@ -0,0 +1,47 @@
+package taskdef
+
+import "testing"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected ImageReference
+		name     string
+	}{
+		{
+			image:    "nginx:latest",
+			expected: ImageReference{Repository: "nginx", Tag: "latest"},
+			name:     "nginx",
+		},
+		{
+			image:    "123456789.dkr.ecr.us-east-1.amazonaws.com/team/my-app:v1.2.3",
+			expected: ImageReference{Repository: "123456789.dkr.ecr.us-east-1.amazonaws.com/team/my-app", Tag: "v1.2.3"},
+			name:     "my-app",
+		},
+		{
+			image:    "localhost:5000/my-app@sha256:abcdef",
+			expected: ImageReference{Repository: "localhost:5000/my-app", Digest: "sha256:abcdef"},
+			name:     "my-app",
+		},
+		{
+			image:    "my-app:v1@sha256:abcdef",
+			expected: ImageReference{Repository: "my-app", Tag: "v1", Digest: "sha256:abcdef"},
+			name:     "my-app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref := ParseImageReference(tt.image)
+			if ref != tt.expected {
+				t.Errorf("ParseImageReference() = %+v, expected %+v", ref, tt.expected)
+			}
+			if ref.Name() != tt.name {
+				t.Errorf("Name() = %q, expected %q", ref.Name(), tt.name)
+			}
+			if ref.String() != tt.image {
+				t.Errorf("String() = %q, expected %q", ref.String(), tt.image)
+			}
+		})
+	}
+}
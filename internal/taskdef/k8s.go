@@ -0,0 +1,103 @@
+package taskdef
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// k8sPodSpecPaths maps workload kinds to the path of their pod spec
+var k8sPodSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// LoadKubernetes loads Kubernetes manifests (multi-document YAML) from a reader
+func LoadKubernetes(r io.Reader) (*EmbeddedDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return newEmbeddedDocument(ModeK8s, data, parseKubernetes)
+}
+
+// parseKubernetes finds the images of the containers and init containers of every workload.
+// Resources are named kind/name, or namespace/kind/name if a namespace is set; List documents
+// are expanded.
+func parseKubernetes(src []byte) ([]imageSite, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []imageSite
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		kind := mappingValue(n, "kind")
+		if kind == nil {
+			return
+		}
+		if strings.HasSuffix(kind.Value, "List") {
+			if items := mappingValue(n, "items"); items != nil && items.Kind == yaml.SequenceNode {
+				for _, item := range items.Content {
+					walk(resolveAlias(item))
+				}
+			}
+			return
+		}
+
+		path, ok := k8sPodSpecPaths[kind.Value]
+		if !ok {
+			return
+		}
+		podSpec := n
+		for _, key := range path {
+			podSpec = mappingValue(podSpec, key)
+		}
+
+		resource := kind.Value
+		metadata := mappingValue(n, "metadata")
+		if name := mappingValue(metadata, "name"); name != nil {
+			resource += "/" + name.Value
+		}
+		if namespace := mappingValue(metadata, "namespace"); namespace != nil && namespace.Value != "" {
+			resource = namespace.Value + "/" + resource
+		}
+		for _, key := range []string{"initContainers", "containers"} {
+			containers := mappingValue(podSpec, key)
+			if containers == nil || containers.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, c := range containers.Content {
+				site := imageSite{resource: resource}
+				if name := mappingValue(c, "name"); name != nil {
+					site.container = name.Value
+				}
+				if image := mappingValue(c, "image"); image != nil {
+					site.image = image.Value
+					if isStringScalar(image) {
+						site.literal, _ = locateScalar(src, image)
+					}
+				}
+				sites = append(sites, site)
+			}
+		}
+	}
+	for _, doc := range docs {
+		walk(doc)
+	}
+
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("no workloads with containers found in manifests")
+	}
+	return sites, nil
+}
@@ -0,0 +1,162 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKubernetesManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: my-app:v1.0
+      containers:
+        - name: web
+          image: my-app:v1.0 # app
+        - name: nginx
+          image: "nginx:latest"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: report
+              image: my-app:v1.0
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: debug
+      namespace: tools
+    spec:
+      containers:
+        - name: debug
+          image: busybox
+`
+
+func TestLoadKubernetes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		check   func([]Resource) bool
+	}{
+		{
+			name:  "Multi-document manifests",
+			input: testKubernetesManifests,
+			check: func(r []Resource) bool {
+				return len(r) == 3 &&
+					r[0].Name == "Deployment/web" && len(r[0].Containers) == 3 &&
+					r[0].Containers[0].Name == "migrate" &&
+					r[1].Name == "CronJob/report" && r[1].Containers[0].Image == "my-app:v1.0" &&
+					r[2].Name == "tools/Pod/debug" && r[2].Containers[0].Image == "busybox"
+			},
+		},
+		{
+			name:    "No workloads",
+			input:   "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid YAML",
+			input:   "kind: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadKubernetes(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadKubernetes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				if !tt.check(doc.Resources()) {
+					t.Errorf("LoadKubernetes() result does not match expected: %+v", doc.Resources())
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateKubernetes(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    UpdateOptions
+		wantErr bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "Update image across workloads including init containers",
+			opts: UpdateOptions{Tag: "v2.0", ImageName: "my-app"},
+			want: []string{
+				"name: migrate\n          image: my-app:v2.0",
+				"image: my-app:v2.0 # app",
+				"              image: my-app:v2.0",
+				`image: "nginx:latest"`,
+			},
+		},
+		{
+			name:    "Filter by resource",
+			opts:    UpdateOptions{Tag: "v2.0", Resource: "CronJob/report"},
+			want:    []string{"              image: my-app:v2.0"},
+			notWant: []string{"image: my-app:v2.0 # app"},
+		},
+		{
+			name: "Add tag to image without tag",
+			opts: UpdateOptions{Tag: "1.36", ContainerName: "debug"},
+			want: []string{"image: busybox:1.36"},
+		},
+		{
+			name:    "Error: container not found",
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "notfound"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadKubernetes(strings.NewReader(testKubernetesManifests))
+			if err != nil {
+				t.Fatalf("LoadKubernetes() error = %v", err)
+			}
+			_, err = doc.Update(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			result := string(doc.Bytes())
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("Update() result should contain %q, got:\n%s", w, result)
+				}
+			}
+			for _, w := range tt.notWant {
+				if strings.Contains(result, w) {
+					t.Errorf("Update() result should not contain %q, got:\n%s", w, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package taskdef
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severity represents the severity of a lint finding
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// severityRank orders severities from least to most severe
+var severityRank = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity parses a severity name
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(s))
+	if _, ok := severityRank[sev]; !ok {
+		return "", fmt.Errorf("invalid severity: %s (must be error, warning, or info)", s)
+	}
+	return sev, nil
+}
+
+// AtLeast reports whether s is at least as severe as other
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRank[s] >= severityRank[other]
+}
+
+// LintFinding represents a best-practice violation reported by a lint rule
+type LintFinding struct {
+	Rule     string   `json:"rule" yaml:"rule"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	// Path is the JSON path of the offending field, e.g. $.containerDefinitions[0].image
+	Path    string `json:"path" yaml:"path"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", f.Path, f.Severity, f.Message, f.Rule)
+}
+
+// LintRule represents a lint rule. Check reports findings for a task definition; the rule ID and
+// severity of the findings are filled in by Lint.
+type LintRule struct {
+	ID          string
+	Description string
+	Severity    Severity
+	Check       func(taskDef *TaskDefinition) []LintFinding
+}
+
+// secretNamePattern matches environment variable names that usually hold secrets
+var secretNamePattern = regexp.MustCompile(`(?i)(^|_)(PASSWORD|PASSWD|TOKEN|SECRET)$`)
+
+// DefaultLintRules returns the built-in lint rules
+func DefaultLintRules() []LintRule {
+	return []LintRule{
+		{
+			ID:          "image-tag",
+			Description: "Images should be pinned to a tag other than latest",
+			Severity:    SeverityWarning,
+			Check:       checkImageTag,
+		},
+		{
+			ID:          "log-configuration",
+			Description: "Containers should have a logConfiguration",
+			Severity:    SeverityWarning,
+			Check:       checkLogConfiguration,
+		},
+		{
+			ID:          "sidecar-health-check",
+			Description: "Essential sidecar containers should have a healthCheck",
+			Severity:    SeverityWarning,
+			Check:       checkSidecarHealthCheck,
+		},
+		{
+			ID:          "plaintext-secret",
+			Description: "Secrets should be passed with secrets instead of environment",
+			Severity:    SeverityError,
+			Check:       checkPlaintextSecret,
+		},
+		{
+			ID:          "readonly-root-filesystem",
+			Description: "Containers should set readonlyRootFilesystem to true",
+			Severity:    SeverityInfo,
+			Check:       checkReadonlyRootFilesystem,
+		},
+		{
+			ID:          "privileged",
+			Description: "Containers should not run privileged",
+			Severity:    SeverityError,
+			Check:       checkPrivileged,
+		},
+	}
+}
+
+// Lint runs the given rules over a task definition, skipping the disabled ones
+func Lint(taskDef *TaskDefinition, rules []LintRule, disabled map[string]bool) []LintFinding {
+	var findings []LintFinding
+	for _, rule := range rules {
+		if disabled[rule.ID] {
+			continue
+		}
+		for _, f := range rule.Check(taskDef) {
+			f.Rule = rule.ID
+			f.Severity = rule.Severity
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// LintContainerDefinitions runs the given rules over container definitions
+func LintContainerDefinitions(containers []ContainerDefinition, rules []LintRule, disabled map[string]bool) []LintFinding {
+	findings := Lint(&TaskDefinition{ContainerDefinitions: containers}, rules, disabled)
+	for i := range findings {
+		findings[i].Path = "$" + strings.TrimPrefix(findings[i].Path, "$.containerDefinitions")
+	}
+	return findings
+}
+
+// lintDirective is the comment prefix that disables lint rules for a file
+const lintDirective = "ecs-tag-shift-disable"
+
+// ParseLintDirectives returns the rules disabled by comments in a JSONC document, e.g.
+//
+//	// ecs-tag-shift-disable image-tag, privileged
+//
+// A directive disables the rules for the whole file wherever it appears; rules cannot be
+// disabled for a single container or line.
+func ParseLintDirectives(data []byte) map[string]bool {
+	disabled := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, lintDirective)
+		if idx < 0 || !isInComment(line, idx) {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimSpace(line[idx+len(lintDirective):]), "*/")
+		for _, id := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			disabled[id] = true
+		}
+	}
+	return disabled
+}
+
+// isInComment reports whether the text at idx follows a // or /* comment marker on the same line
+func isInComment(line string, idx int) bool {
+	prefix := line[:idx]
+	marker := strings.LastIndex(prefix, "//")
+	if m := strings.LastIndex(prefix, "/*"); m > marker {
+		marker = m
+	}
+	if marker < 0 {
+		return false
+	}
+	// The marker must not be inside a string
+	inString := false
+	escaped := false
+	for i := 0; i < marker; i++ {
+		if line[i] == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		if line[i] == '"' && !escaped {
+			inString = !inString
+		}
+		escaped = false
+	}
+	return !inString
+}
+
+// containerPath returns the JSON path of a container definition
+func containerPath(i int) string {
+	return fmt.Sprintf("$.containerDefinitions[%d]", i)
+}
+
+func checkImageTag(taskDef *TaskDefinition) []LintFinding {
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		if _, ok := ImagePlaceholder(c.Image); ok || c.Image == "" || strings.Contains(c.Image, "@") {
+			continue
+		}
+		switch _, tag := parseImage(c.Image); tag {
+		case "":
+			findings = append(findings, LintFinding{Path: containerPath(i) + ".image", Message: fmt.Sprintf("image '%s' has no tag", c.Image)})
+		case "latest":
+			findings = append(findings, LintFinding{Path: containerPath(i) + ".image", Message: fmt.Sprintf("image '%s' uses the latest tag", c.Image)})
+		}
+	}
+	return findings
+}
+
+func checkLogConfiguration(taskDef *TaskDefinition) []LintFinding {
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		if c.LogConfiguration == nil || c.LogConfiguration.LogDriver == "" {
+			findings = append(findings, LintFinding{Path: containerPath(i), Message: fmt.Sprintf("container '%s' has no logConfiguration", c.Name)})
+		}
+	}
+	return findings
+}
+
+// checkSidecarHealthCheck treats a container as a sidecar if it has no port mappings and another
+// container depends on it, e.g. a proxy or log router the application waits for
+func checkSidecarHealthCheck(taskDef *TaskDefinition) []LintFinding {
+	dependedOn := make(map[string]bool)
+	for _, c := range taskDef.ContainerDefinitions {
+		for _, d := range c.DependsOn {
+			if d.ContainerName != c.Name {
+				dependedOn[d.ContainerName] = true
+			}
+		}
+	}
+
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		if !dependedOn[c.Name] || len(c.PortMappings) > 0 || (c.Essential != nil && !*c.Essential) {
+			continue
+		}
+		if c.HealthCheck == nil || len(c.HealthCheck.Command) == 0 {
+			findings = append(findings, LintFinding{Path: containerPath(i), Message: fmt.Sprintf("essential sidecar '%s' has no healthCheck", c.Name)})
+		}
+	}
+	return findings
+}
+
+func checkPlaintextSecret(taskDef *TaskDefinition) []LintFinding {
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		for j, env := range c.Environment {
+			if secretNamePattern.MatchString(env.Name) && env.Value != "" {
+				findings = append(findings, LintFinding{
+					Path:    fmt.Sprintf("%s.environment[%d]", containerPath(i), j),
+					Message: fmt.Sprintf("environment variable '%s' of container '%s' looks like a plaintext secret; use secrets instead", env.Name, c.Name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkReadonlyRootFilesystem(taskDef *TaskDefinition) []LintFinding {
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		if c.ReadonlyRootFilesystem == nil || !*c.ReadonlyRootFilesystem {
+			findings = append(findings, LintFinding{Path: containerPath(i), Message: fmt.Sprintf("container '%s' does not set readonlyRootFilesystem to true", c.Name)})
+		}
+	}
+	return findings
+}
+
+func checkPrivileged(taskDef *TaskDefinition) []LintFinding {
+	var findings []LintFinding
+	for i, c := range taskDef.ContainerDefinitions {
+		if c.Privileged != nil && *c.Privileged {
+			findings = append(findings, LintFinding{Path: containerPath(i) + ".privileged", Message: fmt.Sprintf("container '%s' runs privileged", c.Name)})
+		}
+	}
+	return findings
+}
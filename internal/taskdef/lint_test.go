@@ -0,0 +1,130 @@
+package taskdef
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	trueValue := true
+	falseValue := false
+	logConfig := &LogConfiguration{LogDriver: "awslogs"}
+	healthCheck := &HealthCheck{Command: []string{"CMD-SHELL", "exit 0"}}
+
+	tests := []struct {
+		name     string
+		taskDef  *TaskDefinition
+		disabled map[string]bool
+		want     []string // "rule path" pairs
+	}{
+		{
+			name: "Compliant task definition",
+			taskDef: &TaskDefinition{
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "app", Image: "my-app:v1.0.0", LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue, DependsOn: []ContainerDependency{{ContainerName: "proxy", Condition: "HEALTHY"}}},
+					{Name: "proxy", Image: "envoy@sha256:abc", LogConfiguration: logConfig, HealthCheck: healthCheck, ReadonlyRootFilesystem: &trueValue},
+					{Name: "init", Image: "<IMAGE1_NAME>", Essential: &falseValue, LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue},
+				},
+			},
+		},
+		{
+			name: "Every rule violated",
+			taskDef: &TaskDefinition{
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "app", Image: "my-app", Environment: []EnvironmentVariable{{Name: "LOG_LEVEL", Value: "info"}, {Name: "DB_PASSWORD", Value: "hunter2"}}, DependsOn: []ContainerDependency{{ContainerName: "proxy", Condition: "START"}}},
+					{Name: "proxy", Image: "registry:5000/envoy:latest", Privileged: &trueValue, ReadonlyRootFilesystem: &falseValue},
+				},
+			},
+			want: []string{
+				"image-tag $.containerDefinitions[0].image",
+				"image-tag $.containerDefinitions[1].image",
+				"log-configuration $.containerDefinitions[0]",
+				"log-configuration $.containerDefinitions[1]",
+				"sidecar-health-check $.containerDefinitions[1]",
+				"plaintext-secret $.containerDefinitions[0].environment[1]",
+				"readonly-root-filesystem $.containerDefinitions[0]",
+				"readonly-root-filesystem $.containerDefinitions[1]",
+				"privileged $.containerDefinitions[1].privileged",
+			},
+		},
+		{
+			name: "Sidecars are containers others depend on without port mappings",
+			taskDef: &TaskDefinition{
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "log-router", Image: "fluent-bit:2", LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue},
+					{Name: "app", Image: "my-app:v1.0.0", LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue, PortMappings: []PortMapping{{ContainerPort: 80}},
+						DependsOn: []ContainerDependency{{ContainerName: "log-router", Condition: "START"}, {ContainerName: "api", Condition: "START"}}},
+					{Name: "api", Image: "my-api:v1.0.0", LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue, PortMappings: []PortMapping{{ContainerPort: 8080}}},
+					{Name: "worker", Image: "my-worker:v1.0.0", LogConfiguration: logConfig, ReadonlyRootFilesystem: &trueValue},
+				},
+			},
+			want: []string{"sidecar-health-check $.containerDefinitions[0]"},
+		},
+		{
+			name: "Disabled rules",
+			taskDef: &TaskDefinition{
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "app", Image: "my-app:latest", Environment: []EnvironmentVariable{{Name: "API_TOKEN", Value: "abc"}}},
+				},
+			},
+			disabled: map[string]bool{"image-tag": true, "log-configuration": true, "readonly-root-filesystem": true},
+			want:     []string{"plaintext-secret $.containerDefinitions[0].environment[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := Lint(tt.taskDef, DefaultLintRules(), tt.disabled)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("Lint() returned %d findings, expected %d: %v", len(findings), len(tt.want), findings)
+			}
+			for i, f := range findings {
+				if got := f.Rule + " " + f.Path; got != tt.want[i] {
+					t.Errorf("finding[%d] = %s, expected %s", i, got, tt.want[i])
+				}
+				if f.Severity == "" {
+					t.Errorf("finding[%d] has no severity", i)
+				}
+			}
+		})
+	}
+}
+
+func TestLintContainerDefinitions(t *testing.T) {
+	trueValue := true
+	findings := LintContainerDefinitions([]ContainerDefinition{
+		{Name: "app", Image: "my-app:v1", LogConfiguration: &LogConfiguration{LogDriver: "awslogs"}, Privileged: &trueValue, ReadonlyRootFilesystem: &trueValue},
+	}, DefaultLintRules(), nil)
+	if len(findings) != 1 || findings[0].Path != "$[0].privileged" || findings[0].Severity != SeverityError {
+		t.Errorf("LintContainerDefinitions() = %v, expected a single privileged error at $[0].privileged", findings)
+	}
+}
+
+func TestParseLintDirectives(t *testing.T) {
+	input := []byte(`{
+  // ecs-tag-shift-disable image-tag, privileged
+  "family": "my-app", /* ecs-tag-shift-disable log-configuration */
+  "description": "ecs-tag-shift-disable readonly-root-filesystem"
+}`)
+
+	disabled := ParseLintDirectives(input)
+	for _, id := range []string{"image-tag", "privileged", "log-configuration"} {
+		if !disabled[id] {
+			t.Errorf("ParseLintDirectives() did not disable %s", id)
+		}
+	}
+	if disabled["readonly-root-filesystem"] {
+		t.Errorf("ParseLintDirectives() should ignore directives inside strings")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("WARNING"); err != nil || s != SeverityWarning {
+		t.Errorf("ParseSeverity(WARNING) = %v, %v", s, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("ParseSeverity(fatal) expected error")
+	}
+	if !SeverityError.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) {
+		t.Errorf("AtLeast() ordering is wrong")
+	}
+}
@@ -1,81 +1,63 @@
 package taskdef
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"strings"
 )
 
-// LoadMode represents the input mode (task or container)
+// LoadMode represents the input mode (task, container, cfn, terraform, compose or k8s)
 type LoadMode string
 
 const (
 	ModeTask      LoadMode = "task"
 	ModeContainer LoadMode = "container"
+	ModeCFN       LoadMode = "cfn"
+	ModeTerraform LoadMode = "terraform"
+	ModeCompose   LoadMode = "compose"
+	ModeK8s       LoadMode = "k8s"
 )
 
-// removeJSONComments removes single-line (//) and multi-line (/* */) comments from JSONC
+// removeJSONComments removes single-line (//) and multi-line (/* */) comments from JSONC.
+// Comments are replaced with spaces so that byte offsets, lines and columns still match the input.
 func removeJSONComments(data []byte) []byte {
-	var result bytes.Buffer
-	scanner := bufio.NewScanner(bytes.NewReader(data))
-
-	inMultiLineComment := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Handle multi-line comments
-		if inMultiLineComment {
-			if idx := strings.Index(line, "*/"); idx != -1 {
-				line = line[idx+2:]
-				inMultiLineComment = false
-			} else {
-				continue
+	result := make([]byte, len(data))
+	copy(result, data)
+
+	inString := false
+	for i := 0; i < len(result); i++ {
+		c := result[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
 			}
-		}
-
-		// Check for multi-line comment start
-		if idx := strings.Index(line, "/*"); idx != -1 {
-			endIdx := strings.Index(line[idx:], "*/")
-			if endIdx != -1 {
-				// Comment starts and ends on same line
-				line = line[:idx] + line[idx+endIdx+2:]
-			} else {
-				// Comment continues to next line
-				line = line[:idx]
-				inMultiLineComment = true
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
 			}
-		}
-
-		// Remove single-line comments
-		if idx := strings.Index(line, "//"); idx != -1 {
-			// Make sure it's not inside a string
-			inString := false
-			escaped := false
-			for i := 0; i < idx; i++ {
-				if line[i] == '\\' && !escaped {
-					escaped = true
-					continue
-				}
-				if line[i] == '"' && !escaped {
-					inString = !inString
-				}
-				escaped = false
+		case c == '/' && i+1 < len(result) && result[i+1] == '*':
+			end := bytes.Index(result[i+2:], []byte("*/"))
+			stop := len(result)
+			if end >= 0 {
+				stop = i + 2 + end + 2
 			}
-			if !inString {
-				line = line[:idx]
+			for ; i < stop; i++ {
+				if result[i] != '\n' && result[i] != '\r' {
+					result[i] = ' '
+				}
 			}
+			i--
 		}
-
-		result.WriteString(line)
-		result.WriteString("\n")
 	}
 
-	return result.Bytes()
+	return result
 }
 
 // LoadTaskDefinition loads a task definition from a reader
@@ -88,14 +70,53 @@ func LoadTaskDefinition(r io.Reader) (*TaskDefinition, error) {
 	// Remove JSONC comments
 	cleanData := removeJSONComments(data)
 
+	if isDescribeOutput(cleanData) {
+		return unwrapDescribeOutput(data, cleanData)
+	}
+
 	var taskDef TaskDefinition
-	if err := json.Unmarshal(cleanData, &taskDef); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	if err := decodeJSON(cleanData, &taskDef); err != nil {
+		return nil, newParseError(data, err)
 	}
 
 	return &taskDef, nil
 }
 
+// describeOutput is the output of the DescribeTaskDefinition API, e.g. of
+// aws ecs describe-task-definition --include TAGS
+type describeOutput struct {
+	TaskDefinition *TaskDefinition `json:"taskDefinition"`
+	Tags           []Tag           `json:"tags"`
+}
+
+// isDescribeOutput reports whether a JSON object is DescribeTaskDefinition output rather than a
+// task definition
+func isDescribeOutput(cleanData []byte) bool {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(cleanData, &raw); err != nil {
+		return false
+	}
+	_, wrapped := raw["taskDefinition"]
+	_, containers := raw["containerDefinitions"]
+	return wrapped && !containers
+}
+
+// unwrapDescribeOutput loads the task definition of DescribeTaskDefinition output. Tags returned
+// next to the task definition are moved into it so that it can be registered as-is.
+func unwrapDescribeOutput(data, cleanData []byte) (*TaskDefinition, error) {
+	var out describeOutput
+	if err := decodeJSON(cleanData, &out); err != nil {
+		return nil, newParseError(data, err)
+	}
+	if out.TaskDefinition == nil {
+		return nil, fmt.Errorf("taskDefinition of DescribeTaskDefinition output is null")
+	}
+	if len(out.TaskDefinition.Tags) == 0 {
+		out.TaskDefinition.Tags = out.Tags
+	}
+	return out.TaskDefinition, nil
+}
+
 // LoadContainerDefinitions loads container definitions from a reader
 func LoadContainerDefinitions(r io.Reader) ([]ContainerDefinition, error) {
 	data, err := io.ReadAll(r)
@@ -108,20 +129,31 @@ func LoadContainerDefinitions(r io.Reader) ([]ContainerDefinition, error) {
 
 	// First check if it's an array
 	var containers []ContainerDefinition
-	if err := json.Unmarshal(cleanData, &containers); err != nil {
+	if err := decodeJSON(cleanData, &containers); err != nil {
 		// Check if it's a single object (which should be an error)
 		var singleContainer ContainerDefinition
 		if err2 := json.Unmarshal(cleanData, &singleContainer); err2 == nil {
 			return nil, fmt.Errorf("input must be an array of container definitions")
 		}
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, newParseError(data, err)
 	}
 
 	return containers, nil
 }
 
+// LoadOptions represents options for loading input
+type LoadOptions struct {
+	// Strict rejects duplicate keys and keys that are not part of the ECS task definition schema
+	Strict bool
+}
+
 // LoadFromFile loads data from a file based on mode
 func LoadFromFile(filename string, mode LoadMode) (interface{}, error) {
+	return LoadFromFileWithOptions(filename, mode, LoadOptions{})
+}
+
+// LoadFromFileWithOptions loads data from a file based on mode and options
+func LoadFromFileWithOptions(filename string, mode LoadMode, opts LoadOptions) (interface{}, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -132,7 +164,71 @@ func LoadFromFile(filename string, mode LoadMode) (interface{}, error) {
 		}
 	}()
 
-	return Load(file, mode)
+	result, err := LoadWithOptions(file, mode, opts)
+	return result, withFile(err, filename)
+}
+
+// LoadWithPositions loads a document from a reader based on mode and records the position of each
+// field in the original source. Positions are only recorded in task and container modes; for
+// DescribeTaskDefinition output they are those of the wrapped task definition. filename is used in
+// parse errors and may be empty for stdin.
+func LoadWithPositions(r io.Reader, filename string, mode LoadMode, opts LoadOptions) (Document, Positions, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	doc, err := LoadDocument(bytes.NewReader(data), filename, mode, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mode != ModeTask && mode != ModeContainer {
+		return doc, nil, nil
+	}
+
+	positions, err := ScanPositions(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if mode == ModeTask && isDescribeOutput(removeJSONComments(data)) {
+		// Paths of the document are relative to the unwrapped task definition
+		positions = positions.under("$.taskDefinition")
+	}
+	return doc, positions, nil
+}
+
+// LoadWithOptions loads data from a reader based on mode and options
+func LoadWithOptions(r io.Reader, mode LoadMode, opts LoadOptions) (interface{}, error) {
+	if !opts.Strict {
+		return Load(r, mode)
+	}
+
+	schema := schemaFor(mode)
+	if schema == nil {
+		return nil, fmt.Errorf("strict mode is only supported in task and container modes")
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if mode == ModeTask && isDescribeOutput(removeJSONComments(data)) {
+		schema = describeOutputSchema
+	}
+
+	result, err := Load(bytes.NewReader(data), mode)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := scanJSONC(data, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if len(s.problems) > 0 {
+		return nil, &StrictError{Problems: s.problems}
+	}
+	return result, nil
 }
 
 // Load loads data from a reader based on mode
@@ -142,6 +238,14 @@ func Load(r io.Reader, mode LoadMode) (interface{}, error) {
 		return LoadTaskDefinition(r)
 	case ModeContainer:
 		return LoadContainerDefinitions(r)
+	case ModeCFN:
+		return LoadCloudFormationTemplate(r)
+	case ModeTerraform:
+		return LoadTerraform(r)
+	case ModeCompose:
+		return LoadCompose(r)
+	case ModeK8s:
+		return LoadKubernetes(r)
 	default:
 		return nil, fmt.Errorf("invalid mode: %s", mode)
 	}
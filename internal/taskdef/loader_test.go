@@ -82,6 +82,20 @@ func TestLoadTaskDefinition(t *testing.T) {
 				return td.Family == "app"
 			},
 		},
+		{
+			name:    "DescribeTaskDefinition output",
+			input:   `{"taskDefinition": {"family": "app", "revision": 3, "containerDefinitions": [{"name": "web", "image": "nginx:latest"}]}, "tags": [{"key": "team", "value": "web"}]}`,
+			wantErr: false,
+			check: func(td *TaskDefinition) bool {
+				return td.Family == "app" && td.Revision == 3 && len(td.ContainerDefinitions) == 1 && len(td.Tags) == 1 && td.Tags[0].Key == "team"
+			},
+		},
+		{
+			name:    "DescribeTaskDefinition output without a task definition",
+			input:   `{"taskDefinition": null}`,
+			wantErr: true,
+			check:   nil,
+		},
 		{
 			name:    "Invalid JSON",
 			input:   `{invalid json}`,
@@ -160,3 +174,98 @@ func TestLoadContainerDefinitions(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadStrict(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		mode      LoadMode
+		wantPaths []string
+	}{
+		{
+			name: "Valid task definition with nested fields",
+			input: `{
+  // comments are allowed
+  "family": "my-app",
+  "taskDefinitionArn": "arn:aws:ecs:us-east-1:123456789:task-definition/my-app:1",
+  "volumes": [{"name": "data", "efsVolumeConfiguration": {"fileSystemId": "fs-1", "authorizationConfig": {"iam": "ENABLED"}}}],
+  "containerDefinitions": [
+    {"name": "web", "image": "nginx", "dockerLabels": {"any.label": "x"}, "logConfiguration": {"logDriver": "awslogs", "options": {"awslogs-group": "g"}}}
+  ]
+}`,
+			mode: ModeTask,
+		},
+		{
+			name: "Duplicate and unknown keys everywhere",
+			input: `{
+  "family": "my-app",
+  "famliy": "typo",
+  "containerDefinitions": [
+    {"name": "web", "imgae": "nginx", "image": "nginx", "image": "nginx:1.21"},
+    {"name": "app", "image": "app", "portMappings": [{"containerPort": 80, "port": 80}]}
+  ]
+}`,
+			mode: ModeTask,
+			wantPaths: []string{
+				"$.famliy",
+				"$.containerDefinitions[0].imgae",
+				"$.containerDefinitions[0].image",
+				"$.containerDefinitions[1].portMappings[0].port",
+			},
+		},
+		{
+			name:      "DescribeTaskDefinition output",
+			input:     `{"taskDefinition": {"family": "my-app", "famliy": "typo", "containerDefinitions": []}, "tags": [{"key": "team", "value": "web"}]}`,
+			mode:      ModeTask,
+			wantPaths: []string{"$.taskDefinition.famliy"},
+		},
+		{
+			name:      "Container mode",
+			input:     `[{"name": "web", "image": "nginx", "healthCheck": {"command": ["CMD"], "intervals": 5}}]`,
+			mode:      ModeContainer,
+			wantPaths: []string{"$[0].healthCheck.intervals"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadWithOptions(strings.NewReader(tt.input), tt.mode, LoadOptions{Strict: true})
+			if len(tt.wantPaths) == 0 {
+				if err != nil {
+					t.Errorf("LoadWithOptions() unexpected error = %v", err)
+				}
+				return
+			}
+
+			strictErr, ok := err.(*StrictError)
+			if !ok {
+				t.Fatalf("LoadWithOptions() error = %v, expected a StrictError", err)
+			}
+			if len(strictErr.Problems) != len(tt.wantPaths) {
+				t.Fatalf("LoadWithOptions() found %d problems, expected %d: %v", len(strictErr.Problems), len(tt.wantPaths), err)
+			}
+			for i, p := range strictErr.Problems {
+				if p.Path != tt.wantPaths[i] {
+					t.Errorf("problem[%d] path = %s, expected %s", i, p.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadStrictUnsupportedMode(t *testing.T) {
+	if _, err := LoadWithOptions(strings.NewReader("services: {}"), ModeCompose, LoadOptions{Strict: true}); err == nil {
+		t.Errorf("LoadWithOptions() expected error for strict compose input")
+	}
+}
+
+func TestLoadNonStrictIgnoresUnknownKeys(t *testing.T) {
+	input := `{"family": "x", "famliy": "y", "family": "z", "containerDefinitions": []}`
+	data, err := LoadWithOptions(strings.NewReader(input), ModeTask, LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadWithOptions() error = %v", err)
+	}
+	if td := data.(*TaskDefinition); td.Family != "z" {
+		t.Errorf("Family = %s, expected the last duplicate to win", td.Family)
+	}
+}
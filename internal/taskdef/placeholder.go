@@ -0,0 +1,121 @@
+package taskdef
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// imagePlaceholderPattern matches CodeDeploy image placeholders such as <IMAGE1_NAME>
+var imagePlaceholderPattern = regexp.MustCompile(`^<([A-Za-z0-9_]+)>$`)
+
+// ImagePlaceholder returns the placeholder name if image is a placeholder
+// e.g., "<IMAGE1_NAME>" -> ("IMAGE1_NAME", true)
+func ImagePlaceholder(image string) (string, bool) {
+	m := imagePlaceholderPattern.FindStringSubmatch(image)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// normalizePlaceholder accepts a placeholder name with or without angle brackets
+func normalizePlaceholder(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, "<"), ">")
+}
+
+// RenderPlaceholders substitutes image placeholders with concrete images.
+// values maps placeholder names (e.g. IMAGE1_NAME) to images (e.g. my-app:v1.2.3).
+func RenderPlaceholders(containers []ContainerDefinition, values map[string]string) error {
+	images := make([]string, len(containers))
+	for i, c := range containers {
+		images[i] = c.Image
+	}
+	rendered, err := substitutePlaceholders(images, values)
+	if err != nil {
+		return err
+	}
+	for i := range containers {
+		containers[i].Image = rendered[i]
+	}
+	return nil
+}
+
+// substitutePlaceholders returns texts with every placeholder replaced by its value. It is an
+// error if a placeholder has no value or a value is not used.
+func substitutePlaceholders(texts []string, values map[string]string) ([]string, error) {
+	normalized := make(map[string]string)
+	for name, value := range values {
+		normalized[normalizePlaceholder(name)] = value
+	}
+
+	result := make([]string, len(texts))
+	used := make(map[string]bool)
+	reported := make(map[string]bool)
+	var missing []string
+	for i, text := range texts {
+		result[i] = text
+		name, ok := ImagePlaceholder(text)
+		if !ok {
+			continue
+		}
+		value, ok := normalized[name]
+		if !ok {
+			if !reported[name] {
+				reported[name] = true
+				missing = append(missing, name)
+			}
+			continue
+		}
+		result[i] = value
+		used[name] = true
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no value for placeholder(s): %s", strings.Join(missing, ", "))
+	}
+	var unused []string
+	for name := range normalized {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return nil, fmt.Errorf("placeholder(s) not found in definitions: %s", strings.Join(unused, ", "))
+	}
+	return result, nil
+}
+
+// ReversePlaceholders replaces concrete images with placeholders. placeholders maps placeholder
+// names to container names; if empty, every container is numbered IMAGE1_NAME, IMAGE2_NAME, ...
+func ReversePlaceholders(containers []ContainerDefinition, placeholders map[string]string) error {
+	if len(placeholders) == 0 {
+		for i := range containers {
+			containers[i].Image = fmt.Sprintf("<IMAGE%d_NAME>", i+1)
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(placeholders))
+	for name := range placeholders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		containerName := placeholders[name]
+		found := false
+		for i := range containers {
+			if containers[i].Name == containerName {
+				containers[i].Image = "<" + normalizePlaceholder(name) + ">"
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("container '%s' not found in definitions", containerName)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package taskdef
+
+import (
+	"testing"
+)
+
+func TestImagePlaceholder(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+		ok       bool
+	}{
+		{image: "<IMAGE1_NAME>", expected: "IMAGE1_NAME", ok: true},
+		{image: "<TASK_DEFINITION>", expected: "TASK_DEFINITION", ok: true},
+		{image: "nginx:latest", ok: false},
+		{image: "<IMAGE1_NAME>:v1", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			name, ok := ImagePlaceholder(tt.image)
+			if name != tt.expected || ok != tt.ok {
+				t.Errorf("ImagePlaceholder() = (%q, %v), expected (%q, %v)", name, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRenderPlaceholders(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]string
+		wantErr bool
+		check   func([]ContainerDefinition) bool
+	}{
+		{
+			name:   "Substitute placeholders",
+			values: map[string]string{"IMAGE1_NAME": "my-app:v1.2.3", "<IMAGE2_NAME>": "worker:v1.2.3"},
+			check: func(cd []ContainerDefinition) bool {
+				return cd[0].Image == "my-app:v1.2.3" && cd[1].Image == "worker:v1.2.3" && cd[2].Image == "nginx:latest"
+			},
+		},
+		{
+			name:    "Error: missing value",
+			values:  map[string]string{"IMAGE1_NAME": "my-app:v1.2.3"},
+			wantErr: true,
+		},
+		{
+			name:    "Error: unknown placeholder",
+			values:  map[string]string{"IMAGE1_NAME": "a", "IMAGE2_NAME": "b", "IMAGE3_NAME": "c"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := []ContainerDefinition{
+				{Name: "web", Image: "<IMAGE1_NAME>"},
+				{Name: "worker", Image: "<IMAGE2_NAME>"},
+				{Name: "nginx", Image: "nginx:latest"},
+			}
+			err := RenderPlaceholders(containers, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenderPlaceholders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !tt.check(containers) {
+				t.Errorf("RenderPlaceholders() result does not match expected: %+v", containers)
+			}
+		})
+	}
+
+	// A placeholder used by several containers is reported once
+	containers := []ContainerDefinition{{Name: "web", Image: "<IMAGE1_NAME>"}, {Name: "web-canary", Image: "<IMAGE1_NAME>"}}
+	err := RenderPlaceholders(containers, map[string]string{})
+	if err == nil || err.Error() != "no value for placeholder(s): IMAGE1_NAME" {
+		t.Errorf("RenderPlaceholders() error = %v", err)
+	}
+}
+
+func TestReversePlaceholders(t *testing.T) {
+	tests := []struct {
+		name         string
+		placeholders map[string]string
+		wantErr      bool
+		check        func([]ContainerDefinition) bool
+	}{
+		{
+			name: "Number every container",
+			check: func(cd []ContainerDefinition) bool {
+				return cd[0].Image == "<IMAGE1_NAME>" && cd[1].Image == "<IMAGE2_NAME>"
+			},
+		},
+		{
+			name:         "Assign placeholder to container",
+			placeholders: map[string]string{"IMAGE1_NAME": "web"},
+			check: func(cd []ContainerDefinition) bool {
+				return cd[0].Image == "<IMAGE1_NAME>" && cd[1].Image == "nginx:latest"
+			},
+		},
+		{
+			name:         "Error: container not found",
+			placeholders: map[string]string{"IMAGE1_NAME": "notfound"},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := []ContainerDefinition{
+				{Name: "web", Image: "my-app:v1.2.3"},
+				{Name: "nginx", Image: "nginx:latest"},
+			}
+			err := ReversePlaceholders(containers, tt.placeholders)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReversePlaceholders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !tt.check(containers) {
+				t.Errorf("ReversePlaceholders() result does not match expected: %+v", containers)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package taskdef
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position represents a 1-based line and column (in characters) in a source document
+type Position struct {
+	Line   int `json:"line" yaml:"line"`
+	Column int `json:"column" yaml:"column"`
+}
+
+// Positions maps JSON paths (e.g. $.containerDefinitions[0].image) to their position in the source.
+// Object members are located at their key, array elements at their first character.
+type Positions map[string]Position
+
+// Lookup returns the position of a path, falling back to its closest ancestor present in the source
+func (p Positions) Lookup(path string) (Position, bool) {
+	for {
+		if pos, ok := p[path]; ok {
+			return pos, true
+		}
+		parent, ok := parentPath(path)
+		if !ok {
+			return Position{}, false
+		}
+		path = parent
+	}
+}
+
+// under returns the positions of the value at prefix with paths relative to it, e.g. $.memory for
+// $.taskDefinition.memory. Positions outside of prefix are kept unless they collide, so that members
+// next to the value, such as the tags of DescribeTaskDefinition output, can still be located.
+func (p Positions) under(prefix string) Positions {
+	result := make(Positions, len(p))
+	for path, pos := range p {
+		switch {
+		case path == prefix:
+			result["$"] = pos
+		case strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"["):
+			result["$"+path[len(prefix):]] = pos
+		}
+	}
+	for path, pos := range p {
+		if _, ok := result[path]; !ok && path != prefix && !strings.HasPrefix(path, prefix+".") && !strings.HasPrefix(path, prefix+"[") {
+			result[path] = pos
+		}
+	}
+	return result
+}
+
+// parentPath strips the last member or index from a JSON path
+func parentPath(path string) (string, bool) {
+	if path == "$" || path == "" {
+		return "", false
+	}
+	if strings.HasSuffix(path, "]") {
+		if i := strings.LastIndex(path, "["); i >= 0 {
+			return path[:i], true
+		}
+	}
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i], true
+	}
+	return "$", true
+}
+
+// jsoncScanner walks a JSONC document and records the position of every value
+type jsoncScanner struct {
+	src       []byte
+	offset    int
+	line      int
+	column    int
+	positions Positions
+	// values maps JSON paths to the byte range of their value
+	values map[string]span
+	// problems lists duplicate keys, and keys missing from the schema
+	problems []StrictProblem
+}
+
+// ScanPositions records the position of every object member and array element of a JSONC document
+func ScanPositions(data []byte) (Positions, error) {
+	s, err := scanJSONC(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.positions, nil
+}
+
+// scanJSONC walks a whole JSONC document, checking object keys against schema if not nil
+func scanJSONC(data []byte, schema *schemaNode) (*jsoncScanner, error) {
+	s := &jsoncScanner{src: data, line: 1, column: 1, positions: make(Positions), values: make(map[string]span)}
+	s.skipSpace()
+	s.positions["$"] = s.position()
+	if err := s.scanValue("$", schema); err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	if s.offset < len(s.src) {
+		return nil, s.errorf("unexpected %q after top-level value", s.src[s.offset])
+	}
+	return s, nil
+}
+
+// pathAt returns the JSON path of the innermost value containing a byte offset
+func (s *jsoncScanner) pathAt(offset int) (string, span, bool) {
+	best, bestSpan, found := "", span{}, false
+	for path, sp := range s.values {
+		if offset >= sp.start && offset < sp.end && (!found || sp.end-sp.start < bestSpan.end-bestSpan.start) {
+			best, bestSpan, found = path, sp, true
+		}
+	}
+	return best, bestSpan, found
+}
+
+func (s *jsoncScanner) position() Position {
+	return Position{Line: s.line, Column: s.column}
+}
+
+func (s *jsoncScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d, column %d: %s", s.line, s.column, fmt.Sprintf(format, args...))
+}
+
+// advance moves past n bytes, keeping track of lines and columns
+func (s *jsoncScanner) advance(n int) {
+	end := s.offset + n
+	for s.offset < end && s.offset < len(s.src) {
+		r, size := utf8.DecodeRune(s.src[s.offset:])
+		s.offset += size
+		if r == '\n' {
+			s.line++
+			s.column = 1
+		} else {
+			s.column++
+		}
+	}
+}
+
+func (s *jsoncScanner) peek() byte {
+	if s.offset < len(s.src) {
+		return s.src[s.offset]
+	}
+	return 0
+}
+
+// skipSpace skips whitespace and comments
+func (s *jsoncScanner) skipSpace() {
+	for s.offset < len(s.src) {
+		switch c := s.src[s.offset]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			s.advance(1)
+		case c == '/' && s.offset+1 < len(s.src) && s.src[s.offset+1] == '/':
+			for s.offset < len(s.src) && s.src[s.offset] != '\n' {
+				s.advance(1)
+			}
+		case c == '/' && s.offset+1 < len(s.src) && s.src[s.offset+1] == '*':
+			end := strings.Index(string(s.src[s.offset+2:]), "*/")
+			if end < 0 {
+				s.advance(len(s.src) - s.offset)
+				return
+			}
+			s.advance(end + 4)
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsoncScanner) scanValue(path string, schema *schemaNode) error {
+	start := s.offset
+	if err := s.scanRawValue(path, schema); err != nil {
+		return err
+	}
+	s.values[path] = span{start, s.offset}
+	return nil
+}
+
+func (s *jsoncScanner) scanRawValue(path string, schema *schemaNode) error {
+	switch c := s.peek(); {
+	case c == '{':
+		return s.scanObject(path, schema)
+	case c == '[':
+		return s.scanArray(path, schema)
+	case c == '"':
+		_, err := s.scanString()
+		return err
+	case c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z'):
+		// Numbers and literals are validated by encoding/json
+		for s.offset < len(s.src) && strings.IndexByte(",]} \t\r\n/", s.src[s.offset]) < 0 {
+			s.advance(1)
+		}
+		return nil
+	case c == 0:
+		return s.errorf("unexpected end of input")
+	default:
+		return s.errorf("unexpected %q", c)
+	}
+}
+
+func (s *jsoncScanner) scanObject(path string, schema *schemaNode) error {
+	s.advance(1)
+	seen := make(map[string]bool)
+	for {
+		s.skipSpace()
+		if s.peek() == '}' {
+			s.advance(1)
+			return nil
+		}
+		if s.peek() != '"' {
+			return s.errorf("expected object key")
+		}
+		pos := s.position()
+		key, err := s.scanString()
+		if err != nil {
+			return err
+		}
+		memberPath := path + "." + key
+		s.positions[memberPath] = pos
+
+		var child *schemaNode
+		if seen[key] {
+			s.problems = append(s.problems, StrictProblem{Path: memberPath, Position: pos, Message: "duplicate key"})
+		}
+		seen[key] = true
+		if schema != nil && schema.fields != nil {
+			var known bool
+			if child, known = schema.fields[key]; !known {
+				s.problems = append(s.problems, StrictProblem{Path: memberPath, Position: pos, Message: "unknown key"})
+			}
+		}
+
+		s.skipSpace()
+		if s.peek() != ':' {
+			return s.errorf("expected ':' after object key")
+		}
+		s.advance(1)
+		s.skipSpace()
+		if err := s.scanValue(memberPath, child); err != nil {
+			return err
+		}
+
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.advance(1)
+		case '}':
+		default:
+			return s.errorf("expected ',' or '}' after object member")
+		}
+	}
+}
+
+func (s *jsoncScanner) scanArray(path string, schema *schemaNode) error {
+	var elem *schemaNode
+	if schema != nil {
+		elem = schema.elem
+	}
+	s.advance(1)
+	for i := 0; ; i++ {
+		s.skipSpace()
+		if s.peek() == ']' {
+			s.advance(1)
+			return nil
+		}
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		s.positions[elemPath] = s.position()
+		if err := s.scanValue(elemPath, elem); err != nil {
+			return err
+		}
+
+		s.skipSpace()
+		switch s.peek() {
+		case ',':
+			s.advance(1)
+		case ']':
+		default:
+			return s.errorf("expected ',' or ']' after array element")
+		}
+	}
+}
+
+// scanString scans a string literal and returns its unquoted value
+func (s *jsoncScanner) scanString() (string, error) {
+	start := s.offset
+	s.advance(1)
+	for s.offset < len(s.src) {
+		switch s.src[s.offset] {
+		case '\\':
+			s.advance(2)
+		case '"':
+			s.advance(1)
+			value, err := strconv.Unquote(string(s.src[start:s.offset]))
+			if err != nil {
+				// Escapes such as \/ are valid JSON but not valid Go; keep the raw text
+				return string(s.src[start+1 : s.offset-1]), nil
+			}
+			return value, nil
+		case '\n':
+			return "", s.errorf("unterminated string")
+		default:
+			s.advance(1)
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
@@ -0,0 +1,107 @@
+package taskdef
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanPositions(t *testing.T) {
+	input := []byte(`{
+  // comment with "quotes" and { braces
+  "family": "my-app", /* inline */
+  "containerDefinitions": [
+    {
+      "name": "wéb",
+      "image": "nginx:1.21"
+    },
+    {"name": "app", "image": "app:v1"}
+  ]
+}`)
+
+	positions, err := ScanPositions(input)
+	if err != nil {
+		t.Fatalf("ScanPositions() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want Position
+	}{
+		{path: "$", want: Position{Line: 1, Column: 1}},
+		{path: "$.family", want: Position{Line: 3, Column: 3}},
+		{path: "$.containerDefinitions", want: Position{Line: 4, Column: 3}},
+		{path: "$.containerDefinitions[0]", want: Position{Line: 5, Column: 5}},
+		{path: "$.containerDefinitions[0].image", want: Position{Line: 7, Column: 7}},
+		{path: "$.containerDefinitions[1]", want: Position{Line: 9, Column: 5}},
+		{path: "$.containerDefinitions[1].image", want: Position{Line: 9, Column: 21}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := positions[tt.path]; got != tt.want {
+				t.Errorf("position of %s = %+v, expected %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionsLookup(t *testing.T) {
+	positions := Positions{
+		"$":                         {Line: 1, Column: 1},
+		"$.containerDefinitions":    {Line: 2, Column: 3},
+		"$.containerDefinitions[0]": {Line: 3, Column: 5},
+	}
+
+	tests := []struct {
+		path string
+		want Position
+	}{
+		{path: "$.containerDefinitions[0]", want: Position{Line: 3, Column: 5}},
+		{path: "$.containerDefinitions[0].portMappings[1].hostPort", want: Position{Line: 3, Column: 5}},
+		{path: "$.family", want: Position{Line: 1, Column: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := positions.Lookup(tt.path)
+			if !ok || got != tt.want {
+				t.Errorf("Lookup(%s) = %+v, %v, expected %+v", tt.path, got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := (Positions{}).Lookup("$.family"); ok {
+		t.Errorf("Lookup() on empty positions should fail")
+	}
+}
+
+func TestPositionsUnder(t *testing.T) {
+	positions := Positions{
+		"$":                       {Line: 1, Column: 1},
+		"$.taskDefinition":        {Line: 2, Column: 3},
+		"$.taskDefinition.memory": {Line: 3, Column: 5},
+		"$.taskDefinition.tags":   {Line: 4, Column: 5},
+		"$.taskDefinitionArn":     {Line: 6, Column: 3},
+		"$.tags":                  {Line: 7, Column: 3},
+		"$.tags[0]":               {Line: 7, Column: 12},
+	}
+
+	expected := Positions{
+		"$":                   {Line: 2, Column: 3},
+		"$.memory":            {Line: 3, Column: 5},
+		"$.tags":              {Line: 4, Column: 5},
+		"$.taskDefinitionArn": {Line: 6, Column: 3},
+		"$.tags[0]":           {Line: 7, Column: 12},
+	}
+	if got := positions.under("$.taskDefinition"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("under() = %+v, expected %+v", got, expected)
+	}
+}
+
+func TestScanPositionsErrors(t *testing.T) {
+	for _, input := range []string{`{"a": 1`, `{"a" 1}`, `[1, 2`, `{"a": "b}`, `{} {}`} {
+		if _, err := ScanPositions([]byte(input)); err == nil {
+			t.Errorf("ScanPositions(%q) expected error", input)
+		}
+	}
+}
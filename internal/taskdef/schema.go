@@ -0,0 +1,147 @@
+package taskdef
+
+// schemaNode describes the keys allowed in a JSON value of the ECS task definition schema
+type schemaNode struct {
+	// fields lists the keys of an object; nil means the value is not checked
+	fields map[string]*schemaNode
+	// elem describes the elements of an array
+	elem *schemaNode
+}
+
+// schemaAny is a scalar or free-form value (e.g. dockerLabels) whose keys are not checked
+var schemaAny = &schemaNode{}
+
+func schemaObject(fields map[string]*schemaNode) *schemaNode {
+	return &schemaNode{fields: fields}
+}
+
+func schemaArray(elem *schemaNode) *schemaNode {
+	return &schemaNode{elem: elem}
+}
+
+var (
+	keyValuePair = schemaObject(map[string]*schemaNode{"name": schemaAny, "value": schemaAny})
+	secretSchema = schemaObject(map[string]*schemaNode{"name": schemaAny, "valueFrom": schemaAny})
+
+	containerDefinitionSchema = schemaObject(map[string]*schemaNode{
+		"name":                   schemaAny,
+		"image":                  schemaAny,
+		"repositoryCredentials":  schemaObject(map[string]*schemaNode{"credentialsParameter": schemaAny}),
+		"cpu":                    schemaAny,
+		"memory":                 schemaAny,
+		"memoryReservation":      schemaAny,
+		"links":                  schemaAny,
+		"portMappings":           schemaArray(schemaObject(map[string]*schemaNode{"containerPort": schemaAny, "hostPort": schemaAny, "protocol": schemaAny, "name": schemaAny, "appProtocol": schemaAny, "containerPortRange": schemaAny})),
+		"essential":              schemaAny,
+		"restartPolicy":          schemaObject(map[string]*schemaNode{"enabled": schemaAny, "ignoredExitCodes": schemaAny, "restartAttemptPeriod": schemaAny}),
+		"entryPoint":             schemaAny,
+		"command":                schemaAny,
+		"environment":            schemaArray(keyValuePair),
+		"environmentFiles":       schemaArray(schemaObject(map[string]*schemaNode{"value": schemaAny, "type": schemaAny})),
+		"mountPoints":            schemaArray(schemaObject(map[string]*schemaNode{"sourceVolume": schemaAny, "containerPath": schemaAny, "readOnly": schemaAny})),
+		"volumesFrom":            schemaArray(schemaObject(map[string]*schemaNode{"sourceContainer": schemaAny, "readOnly": schemaAny})),
+		"linuxParameters":        linuxParametersSchema,
+		"secrets":                schemaArray(secretSchema),
+		"dependsOn":              schemaArray(schemaObject(map[string]*schemaNode{"containerName": schemaAny, "condition": schemaAny})),
+		"startTimeout":           schemaAny,
+		"stopTimeout":            schemaAny,
+		"versionConsistency":     schemaAny,
+		"hostname":               schemaAny,
+		"user":                   schemaAny,
+		"workingDirectory":       schemaAny,
+		"disableNetworking":      schemaAny,
+		"privileged":             schemaAny,
+		"readonlyRootFilesystem": schemaAny,
+		"dnsServers":             schemaAny,
+		"dnsSearchDomains":       schemaAny,
+		"extraHosts":             schemaArray(schemaObject(map[string]*schemaNode{"hostname": schemaAny, "ipAddress": schemaAny})),
+		"dockerSecurityOptions":  schemaAny,
+		"interactive":            schemaAny,
+		"pseudoTerminal":         schemaAny,
+		"dockerLabels":           schemaAny,
+		"ulimits":                schemaArray(schemaObject(map[string]*schemaNode{"name": schemaAny, "softLimit": schemaAny, "hardLimit": schemaAny})),
+		"logConfiguration":       schemaObject(map[string]*schemaNode{"logDriver": schemaAny, "options": schemaAny, "secretOptions": schemaArray(secretSchema)}),
+		"healthCheck":            schemaObject(map[string]*schemaNode{"command": schemaAny, "interval": schemaAny, "timeout": schemaAny, "retries": schemaAny, "startPeriod": schemaAny}),
+		"systemControls":         schemaArray(schemaObject(map[string]*schemaNode{"namespace": schemaAny, "value": schemaAny})),
+		"resourceRequirements":   schemaArray(schemaObject(map[string]*schemaNode{"value": schemaAny, "type": schemaAny})),
+		"firelensConfiguration":  schemaObject(map[string]*schemaNode{"type": schemaAny, "options": schemaAny}),
+		"credentialSpecs":        schemaAny,
+	})
+
+	linuxParametersSchema = schemaObject(map[string]*schemaNode{
+		"capabilities":       schemaObject(map[string]*schemaNode{"add": schemaAny, "drop": schemaAny}),
+		"devices":            schemaArray(schemaObject(map[string]*schemaNode{"hostPath": schemaAny, "containerPath": schemaAny, "permissions": schemaAny})),
+		"initProcessEnabled": schemaAny,
+		"sharedMemorySize":   schemaAny,
+		"tmpfs":              schemaArray(schemaObject(map[string]*schemaNode{"containerPath": schemaAny, "size": schemaAny, "mountOptions": schemaAny})),
+		"maxSwap":            schemaAny,
+		"swappiness":         schemaAny,
+	})
+
+	volumeSchema = schemaObject(map[string]*schemaNode{
+		"name":               schemaAny,
+		"host":               schemaObject(map[string]*schemaNode{"sourcePath": schemaAny}),
+		"configuredAtLaunch": schemaAny,
+		"dockerVolumeConfiguration": schemaObject(map[string]*schemaNode{
+			"scope": schemaAny, "autoprovision": schemaAny, "driver": schemaAny, "driverOpts": schemaAny, "labels": schemaAny,
+		}),
+		"efsVolumeConfiguration": schemaObject(map[string]*schemaNode{
+			"fileSystemId": schemaAny, "rootDirectory": schemaAny, "transitEncryption": schemaAny, "transitEncryptionPort": schemaAny,
+			"authorizationConfig": schemaObject(map[string]*schemaNode{"accessPointId": schemaAny, "iam": schemaAny}),
+		}),
+		"fsxWindowsFileServerVolumeConfiguration": schemaObject(map[string]*schemaNode{
+			"fileSystemId": schemaAny, "rootDirectory": schemaAny,
+			"authorizationConfig": schemaObject(map[string]*schemaNode{"credentialsParameter": schemaAny, "domain": schemaAny}),
+		}),
+	})
+
+	taskDefinitionSchema = schemaObject(map[string]*schemaNode{
+		"family":                  schemaAny,
+		"taskRoleArn":             schemaAny,
+		"executionRoleArn":        schemaAny,
+		"networkMode":             schemaAny,
+		"containerDefinitions":    schemaArray(containerDefinitionSchema),
+		"volumes":                 schemaArray(volumeSchema),
+		"placementConstraints":    schemaArray(schemaObject(map[string]*schemaNode{"type": schemaAny, "expression": schemaAny})),
+		"requiresCompatibilities": schemaAny,
+		"cpu":                     schemaAny,
+		"memory":                  schemaAny,
+		"tags":                    schemaArray(schemaObject(map[string]*schemaNode{"key": schemaAny, "value": schemaAny})),
+		"pidMode":                 schemaAny,
+		"ipcMode":                 schemaAny,
+		"proxyConfiguration":      schemaObject(map[string]*schemaNode{"type": schemaAny, "containerName": schemaAny, "properties": schemaArray(keyValuePair)}),
+		"inferenceAccelerators":   schemaArray(schemaObject(map[string]*schemaNode{"deviceName": schemaAny, "deviceType": schemaAny})),
+		"ephemeralStorage":        schemaObject(map[string]*schemaNode{"sizeInGiB": schemaAny}),
+		"runtimePlatform":         schemaObject(map[string]*schemaNode{"cpuArchitecture": schemaAny, "operatingSystemFamily": schemaAny}),
+		"enableFaultInjection":    schemaAny,
+		// Read-only fields returned by DescribeTaskDefinition
+		"taskDefinitionArn":  schemaAny,
+		"revision":           schemaAny,
+		"status":             schemaAny,
+		"requiresAttributes": schemaAny,
+		"compatibilities":    schemaAny,
+		"registeredAt":       schemaAny,
+		"registeredBy":       schemaAny,
+		"deregisteredAt":     schemaAny,
+	})
+
+	containerDefinitionsSchema = schemaArray(containerDefinitionSchema)
+
+	// describeOutputSchema is the schema of DescribeTaskDefinition output
+	describeOutputSchema = schemaObject(map[string]*schemaNode{
+		"taskDefinition": taskDefinitionSchema,
+		"tags":           taskDefinitionSchema.fields["tags"],
+	})
+)
+
+// schemaFor returns the schema of the input of a mode, or nil if it has none
+func schemaFor(mode LoadMode) *schemaNode {
+	switch mode {
+	case ModeTask:
+		return taskDefinitionSchema
+	case ModeContainer:
+		return containerDefinitionsSchema
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,382 @@
+package taskdef
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// terraformTaskDefinitionType is the Terraform resource type of ECS task definitions
+const terraformTaskDefinitionType = "aws_ecs_task_definition"
+
+// LoadTerraform loads Terraform configuration (.tf or .tf.json) from a reader
+func LoadTerraform(r io.Reader) (*EmbeddedDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return newEmbeddedDocument(ModeTerraform, data, parseTerraform)
+}
+
+// parseTerraform finds the container images of every aws_ecs_task_definition resource with
+// inline container_definitions. JSON syntax is detected by a leading '{'.
+func parseTerraform(src []byte) ([]imageSite, error) {
+	var sites []imageSite
+	var err error
+	if trimmed := bytes.TrimSpace(src); len(trimmed) > 0 && trimmed[0] == '{' {
+		sites, err = parseTerraformJSON(src)
+	} else {
+		sites, err = parseTerraformHCL(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sites) == 0 {
+		return nil, fmt.Errorf("no %s resources with inline container_definitions found", terraformTaskDefinitionType)
+	}
+	return sites, nil
+}
+
+// parseTerraformHCL finds task definition resources in HCL native syntax. container_definitions
+// may be a jsonencode() call with a literal list or a heredoc holding JSON.
+func parseTerraformHCL(src []byte) ([]imageSite, error) {
+	tokens, err := lexHCL(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HCL: %w", err)
+	}
+	p := &hclParser{src: src, tokens: tokens}
+
+	var sites []imageSite
+	depth := 0
+	for p.peek().kind != hclEOF {
+		tok := p.next()
+		switch {
+		case tok.isPunct("{"):
+			depth++
+		case tok.isPunct("}"):
+			depth--
+		case depth == 0 && tok.kind == hclIdent && tok.text == "resource":
+			typ, name := p.next(), p.next()
+			if typ.kind != hclString || name.kind != hclString || !p.peek().isPunct("{") {
+				continue
+			}
+			if string(src[typ.content.start:typ.content.end]) != terraformTaskDefinitionType {
+				continue
+			}
+			address := terraformTaskDefinitionType + "." + string(src[name.content.start:name.content.end])
+			resourceSites, err := parseTerraformHCLResource(p, address)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", address, err)
+			}
+			sites = append(sites, resourceSites...)
+		}
+	}
+
+	return sites, nil
+}
+
+// parseTerraformHCLResource parses the body of a resource block, leaving the parser after its closing brace
+func parseTerraformHCLResource(p *hclParser, address string) ([]imageSite, error) {
+	p.next()
+	var sites []imageSite
+	depth := 1
+	lineStart := true
+	for depth > 0 {
+		tok := p.next()
+		switch {
+		case tok.kind == hclEOF:
+			return nil, fmt.Errorf("unterminated resource block")
+		case tok.isPunct("{"):
+			depth++
+		case tok.isPunct("}"):
+			depth--
+		case depth == 1 && lineStart && tok.kind == hclIdent && tok.text == "container_definitions" && p.peek().isPunct("="):
+			p.next()
+			value, err := parseTerraformContainerDefinitions(p)
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				continue
+			}
+			valueSites, err := containerSitesFromHCL(p.src, value, address, identitySpan)
+			if err != nil {
+				return nil, err
+			}
+			sites = append(sites, valueSites...)
+		}
+		lineStart = tok.kind == hclNewline || tok.isPunct("{")
+	}
+	return sites, nil
+}
+
+// parseTerraformContainerDefinitions parses the value of container_definitions. It returns the
+// literal list passed to jsonencode() or a heredoc, and nil for anything else (e.g. file()).
+func parseTerraformContainerDefinitions(p *hclParser) (*hclValue, error) {
+	if tok := p.peek(); tok.kind == hclIdent && tok.text == "jsonencode" && p.tokens[p.pos+1].isPunct("(") {
+		p.next()
+		p.next()
+		p.skipNewlines()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		p.skipNewlines()
+		if !p.peek().isPunct(")") {
+			return nil, nil
+		}
+		p.next()
+		return value, nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != hclValueHeredoc {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// containerSitesFromHCL returns the image sites of a container definitions value, which is
+// either an HCL tuple of objects or a heredoc holding JSON. mapSpan maps spans in src to the document.
+func containerSitesFromHCL(src []byte, value *hclValue, resource string, mapSpan func(span) *span) ([]imageSite, error) {
+	if value.kind == hclValueHeredoc {
+		base := value.content.start
+		return containerSitesFromJSON(src[value.content.start:value.content.end], resource, func(sp span) *span {
+			return mapSpan(span{sp.start + base, sp.end + base})
+		})
+	}
+	if value.kind != hclValueList {
+		return nil, nil
+	}
+
+	var sites []imageSite
+	for _, item := range value.items {
+		if item.kind != hclValueObject {
+			continue
+		}
+		site := imageSite{resource: resource}
+		if name := item.field("name"); name != nil {
+			site.container, _ = renderHCLString(src, name)
+		}
+		if image := item.field("image"); image != nil {
+			var literal *span
+			site.image, literal = renderHCLString(src, image)
+			if literal != nil {
+				site.literal = mapSpan(*literal)
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// renderHCLString renders an HCL expression as a string. Quoted templates are returned as written,
+// keeping interpolations such as ${var.tag}; other expressions are rendered as ${...}.
+func renderHCLString(src []byte, v *hclValue) (string, *span) {
+	if v.kind == hclValueString {
+		literal := v.content
+		return string(src[literal.start:literal.end]), &literal
+	}
+	return "${" + string(src[v.pos.start:v.pos.end]) + "}", nil
+}
+
+// containerSitesFromJSON returns the image sites of a JSON array of container definitions
+func containerSitesFromJSON(src []byte, resource string, mapSpan func(span) *span) ([]imageSite, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 || docs[0].Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("container_definitions must be an array")
+	}
+
+	var sites []imageSite
+	for _, c := range docs[0].Content {
+		site := imageSite{resource: resource}
+		if name := mappingValue(c, "name"); name != nil {
+			site.container = name.Value
+		}
+		if image := mappingValue(c, "image"); image != nil {
+			site.image = image.Value
+			if isStringScalar(image) {
+				if literal, err := locateScalar(src, image); err == nil {
+					site.literal = mapSpan(*literal)
+				}
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// identitySpan maps a span to itself
+func identitySpan(sp span) *span {
+	return &sp
+}
+
+// parseTerraformJSON finds task definition resources in Terraform JSON syntax. container_definitions
+// is a string holding either JSON or a "${jsonencode(...)}" template.
+func parseTerraformJSON(src []byte) ([]imageSite, error) {
+	docs, err := parseYAMLDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("input must be a single JSON object")
+	}
+
+	var sites []imageSite
+	for _, resources := range objectList(mappingValue(docs[0], "resource")) {
+		for _, byName := range objectList(mappingValue(resources, terraformTaskDefinitionType)) {
+			for i := 0; i+1 < len(byName.Content); i += 2 {
+				address := terraformTaskDefinitionType + "." + byName.Content[i].Value
+				for _, body := range objectList(resolveAlias(byName.Content[i+1])) {
+					definitions := mappingValue(body, "container_definitions")
+					if !isStringScalar(definitions) {
+						continue
+					}
+					resourceSites, err := terraformJSONContainerSites(src, definitions, address)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", address, err)
+					}
+					sites = append(sites, resourceSites...)
+				}
+			}
+		}
+	}
+	return sites, nil
+}
+
+// objectList returns n itself if it is a mapping, or its mapping elements if it is a sequence
+func objectList(n *yaml.Node) []*yaml.Node {
+	n = resolveAlias(n)
+	switch {
+	case n == nil:
+		return nil
+	case n.Kind == yaml.MappingNode:
+		return []*yaml.Node{n}
+	case n.Kind == yaml.SequenceNode:
+		var list []*yaml.Node
+		for _, item := range n.Content {
+			if item = resolveAlias(item); item.Kind == yaml.MappingNode {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// terraformJSONContainerSites returns the image sites of a container_definitions JSON string
+func terraformJSONContainerSites(src []byte, n *yaml.Node, address string) ([]imageSite, error) {
+	start, err := nodeOffset(src, n)
+	if err != nil {
+		return nil, err
+	}
+	decoded, offsets, err := decodeJSONString(src, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Spans in the decoded string are mapped back to the escaped source when they are written verbatim
+	mapSpan := func(sp span) *span {
+		raw := span{offsets[sp.start], offsets[sp.end]}
+		if !bytes.Equal(src[raw.start:raw.end], decoded[sp.start:sp.end]) {
+			return nil
+		}
+		return &raw
+	}
+
+	trimmed := bytes.TrimSpace(decoded)
+	if !bytes.HasPrefix(trimmed, []byte("${")) || !bytes.HasSuffix(trimmed, []byte("}")) {
+		return containerSitesFromJSON(decoded, address, mapSpan)
+	}
+
+	// "${jsonencode([...])}" template
+	exprStart := bytes.Index(decoded, []byte("${")) + 2
+	exprEnd := bytes.LastIndexByte(decoded, '}')
+	tokens, err := lexHCL(decoded[:exprEnd])
+	if err != nil {
+		return nil, err
+	}
+	p := &hclParser{src: decoded, tokens: tokens}
+	for p.peek().pos.start < exprStart {
+		p.next()
+	}
+	value, err := parseTerraformContainerDefinitions(p)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return containerSitesFromHCL(decoded, value, address, mapSpan)
+}
+
+// decodeJSONString decodes the JSON string whose opening quote is at src[start]. offsets maps each
+// decoded byte to its offset in src; the extra final entry is the offset of the closing quote.
+func decodeJSONString(src []byte, start int) (decoded []byte, offsets []int, err error) {
+	if start >= len(src) || src[start] != '"' {
+		return nil, nil, fmt.Errorf("expected string at offset %d", start)
+	}
+	for i := start + 1; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"':
+			return decoded, append(offsets, i), nil
+		case c == '\\' && i+1 < len(src):
+			r, size := rune(src[i+1]), 2
+			switch src[i+1] {
+			case 'b':
+				r = '\b'
+			case 'f':
+				r = '\f'
+			case 'n':
+				r = '\n'
+			case 'r':
+				r = '\r'
+			case 't':
+				r = '\t'
+			case 'u':
+				r, size = decodeJSONUnicodeEscape(src[i:])
+			}
+			for range utf8.RuneLen(r) {
+				offsets = append(offsets, i)
+			}
+			decoded = utf8.AppendRune(decoded, r)
+			i += size
+		default:
+			decoded = append(decoded, c)
+			offsets = append(offsets, i)
+			i++
+		}
+	}
+	return nil, nil, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// decodeJSONUnicodeEscape decodes a \uXXXX escape (or a surrogate pair) at the start of b
+func decodeJSONUnicodeEscape(b []byte) (rune, int) {
+	if len(b) < 6 {
+		return utf8.RuneError, len(b)
+	}
+	n, err := strconv.ParseUint(string(b[2:6]), 16, 16)
+	if err != nil {
+		return utf8.RuneError, 6
+	}
+	r := rune(n)
+	if utf16.IsSurrogate(r) && len(b) >= 12 && b[6] == '\\' && b[7] == 'u' {
+		if n2, err := strconv.ParseUint(string(b[8:12]), 16, 16); err == nil {
+			if pair := utf16.DecodeRune(r, rune(n2)); pair != utf8.RuneError {
+				return pair, 12
+			}
+		}
+	}
+	return r, 6
+}
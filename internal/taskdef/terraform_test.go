@@ -0,0 +1,185 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+const testTerraformHCL = `resource "aws_ecs_cluster" "main" {
+  name = "main"
+}
+
+# Application task definition
+resource "aws_ecs_task_definition" "app" {
+  family = "my-app"
+  container_definitions = jsonencode([
+    {
+      name      = "web"
+      image     = "${var.registry}/my-app:v1.0" # app image
+      essential = true
+      portMappings = [{ containerPort = 80 }]
+    },
+    {
+      "name" : "nginx",
+      "image" : "nginx:latest",
+    },
+    {
+      name  = "sidecar"
+      image = var.sidecar_image
+    }
+  ])
+}
+
+resource "aws_ecs_task_definition" "worker" {
+  family                = "my-worker"
+  container_definitions = <<EOF
+[
+  {"name": "worker", "image": "my-worker:v1.0"}
+]
+EOF
+}
+
+resource "aws_ecs_task_definition" "external" {
+  family                = "external"
+  container_definitions = file("containers.json")
+}
+`
+
+const testTerraformJSON = `{
+  "resource": {
+    "aws_ecs_task_definition": {
+      "app": {
+        "family": "my-app",
+        "container_definitions": "[{\"name\":\"web\",\"image\":\"my-app:v1.0\"},{\"name\":\"nginx\",\"image\":\"nginx:latest\"}]"
+      },
+      "worker": {
+        "family": "my-worker",
+        "container_definitions": "${jsonencode([{name = \"worker\", image = \"my-worker:v1.0\"}])}"
+      }
+    }
+  }
+}
+`
+
+func TestLoadTerraform(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+		check   func([]Resource) bool
+	}{
+		{
+			name:  "HCL with jsonencode and heredoc",
+			input: testTerraformHCL,
+			check: func(r []Resource) bool {
+				return len(r) == 2 &&
+					r[0].Name == "aws_ecs_task_definition.app" && len(r[0].Containers) == 3 &&
+					r[0].Containers[0].Image == "${var.registry}/my-app:v1.0" &&
+					r[0].Containers[1].Name == "nginx" &&
+					r[0].Containers[2].Image == "${var.sidecar_image}" &&
+					r[1].Name == "aws_ecs_task_definition.worker" && r[1].Containers[0].Image == "my-worker:v1.0"
+			},
+		},
+		{
+			name:  "JSON syntax",
+			input: testTerraformJSON,
+			check: func(r []Resource) bool {
+				return len(r) == 2 && len(r[0].Containers) == 2 &&
+					r[0].Containers[1].Image == "nginx:latest" &&
+					r[1].Containers[0].Name == "worker"
+			},
+		},
+		{
+			name:    "No task definitions",
+			input:   `resource "aws_ecs_cluster" "main" {}`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid HCL",
+			input:   `resource "aws_ecs_task_definition" "app" { container_definitions = "unterminated`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadTerraform(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadTerraform() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && tt.check != nil {
+				if !tt.check(doc.Resources()) {
+					t.Errorf("LoadTerraform() result does not match expected: %+v", doc.Resources())
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateTerraform(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		opts    UpdateOptions
+		wantErr bool
+		want    []string
+	}{
+		{
+			name:  "Update HCL jsonencode",
+			input: testTerraformHCL,
+			opts:  UpdateOptions{Tag: "v2.0", ImageName: "my-app"},
+			want:  []string{`image     = "${var.registry}/my-app:v2.0" # app image`, `"image" : "nginx:latest"`},
+		},
+		{
+			name:  "Update HCL heredoc by resource",
+			input: testTerraformHCL,
+			opts:  UpdateOptions{Tag: "v2.0", Resource: "aws_ecs_task_definition.worker"},
+			want:  []string{`{"name": "worker", "image": "my-worker:v2.0"}`, `"image" : "nginx:latest"`},
+		},
+		{
+			name:  "Update JSON string",
+			input: testTerraformJSON,
+			opts:  UpdateOptions{Tag: "v2.0"},
+			want: []string{
+				`"[{\"name\":\"web\",\"image\":\"my-app:v2.0\"},{\"name\":\"nginx\",\"image\":\"nginx:v2.0\"}]"`,
+				`"${jsonencode([{name = \"worker\", image = \"my-worker:v2.0\"}])}"`,
+			},
+		},
+		{
+			name:    "Error: image is a variable",
+			input:   testTerraformHCL,
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "sidecar"},
+			wantErr: true,
+		},
+		{
+			name:    "Error: container not found",
+			input:   testTerraformJSON,
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "notfound"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := LoadTerraform(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("LoadTerraform() error = %v", err)
+			}
+			_, err = doc.Update(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			result := string(doc.Bytes())
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("Update() result should contain %q, got:\n%s", w, result)
+				}
+			}
+			if !tt.wantErr && strings.Count(result, "\n") != strings.Count(tt.input, "\n") {
+				t.Errorf("Update() changed more than the image tags:\n%s", result)
+			}
+		})
+	}
+}
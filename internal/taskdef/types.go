@@ -2,28 +2,230 @@ package taskdef
 
 // ContainerDefinition represents an ECS container definition
 type ContainerDefinition struct {
-	Name         string                 `json:"name" yaml:"name"`
-	Image        string                 `json:"image" yaml:"image"`
-	CPU          int                    `json:"cpu,omitempty" yaml:"cpu,omitempty"`
-	Memory       int                    `json:"memory,omitempty" yaml:"memory,omitempty"`
-	Essential    *bool                  `json:"essential,omitempty" yaml:"essential,omitempty"`
-	PortMappings []PortMapping          `json:"portMappings,omitempty" yaml:"portMappings,omitempty"`
-	Environment  []EnvironmentVariable  `json:"environment,omitempty" yaml:"environment,omitempty"`
-	// Store all other fields as-is
+	Name                   string                 `json:"name" yaml:"name"`
+	Image                  string                 `json:"image" yaml:"image"`
+	CPU                    int                    `json:"cpu,omitempty" yaml:"cpu,omitempty"`
+	Memory                 int                    `json:"memory,omitempty" yaml:"memory,omitempty"`
+	Essential              *bool                  `json:"essential,omitempty" yaml:"essential,omitempty"`
+	PortMappings           []PortMapping          `json:"portMappings,omitempty" yaml:"portMappings,omitempty"`
+	Environment            []EnvironmentVariable  `json:"environment,omitempty" yaml:"environment,omitempty"`
+	LogConfiguration       *LogConfiguration      `json:"logConfiguration,omitempty" yaml:"logConfiguration,omitempty"`
+	HealthCheck            *HealthCheck           `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	ReadonlyRootFilesystem *bool                  `json:"readonlyRootFilesystem,omitempty" yaml:"readonlyRootFilesystem,omitempty"`
+	Privileged             *bool                  `json:"privileged,omitempty" yaml:"privileged,omitempty"`
+	RepositoryCredentials  *RepositoryCredentials `json:"repositoryCredentials,omitempty" yaml:"repositoryCredentials,omitempty"`
+	MemoryReservation      int                    `json:"memoryReservation,omitempty" yaml:"memoryReservation,omitempty"`
+	Links                  []string               `json:"links,omitempty" yaml:"links,omitempty"`
+	RestartPolicy          *RestartPolicy         `json:"restartPolicy,omitempty" yaml:"restartPolicy,omitempty"`
+	EntryPoint             []string               `json:"entryPoint,omitempty" yaml:"entryPoint,omitempty"`
+	Command                []string               `json:"command,omitempty" yaml:"command,omitempty"`
+	EnvironmentFiles       []EnvironmentFile      `json:"environmentFiles,omitempty" yaml:"environmentFiles,omitempty"`
+	MountPoints            []MountPoint           `json:"mountPoints,omitempty" yaml:"mountPoints,omitempty"`
+	VolumesFrom            []VolumeFrom           `json:"volumesFrom,omitempty" yaml:"volumesFrom,omitempty"`
+	LinuxParameters        *LinuxParameters       `json:"linuxParameters,omitempty" yaml:"linuxParameters,omitempty"`
+	Secrets                []Secret               `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+	DependsOn              []ContainerDependency  `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	StartTimeout           int                    `json:"startTimeout,omitempty" yaml:"startTimeout,omitempty"`
+	StopTimeout            int                    `json:"stopTimeout,omitempty" yaml:"stopTimeout,omitempty"`
+	VersionConsistency     string                 `json:"versionConsistency,omitempty" yaml:"versionConsistency,omitempty"`
+	Hostname               string                 `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	User                   string                 `json:"user,omitempty" yaml:"user,omitempty"`
+	WorkingDirectory       string                 `json:"workingDirectory,omitempty" yaml:"workingDirectory,omitempty"`
+	DisableNetworking      *bool                  `json:"disableNetworking,omitempty" yaml:"disableNetworking,omitempty"`
+	DNSServers             []string               `json:"dnsServers,omitempty" yaml:"dnsServers,omitempty"`
+	DNSSearchDomains       []string               `json:"dnsSearchDomains,omitempty" yaml:"dnsSearchDomains,omitempty"`
+	ExtraHosts             []HostEntry            `json:"extraHosts,omitempty" yaml:"extraHosts,omitempty"`
+	DockerSecurityOptions  []string               `json:"dockerSecurityOptions,omitempty" yaml:"dockerSecurityOptions,omitempty"`
+	Interactive            *bool                  `json:"interactive,omitempty" yaml:"interactive,omitempty"`
+	PseudoTerminal         *bool                  `json:"pseudoTerminal,omitempty" yaml:"pseudoTerminal,omitempty"`
+	DockerLabels           map[string]string      `json:"dockerLabels,omitempty" yaml:"dockerLabels,omitempty"`
+	Ulimits                []Ulimit               `json:"ulimits,omitempty" yaml:"ulimits,omitempty"`
+	SystemControls         []SystemControl        `json:"systemControls,omitempty" yaml:"systemControls,omitempty"`
+	ResourceRequirements   []ResourceRequirement  `json:"resourceRequirements,omitempty" yaml:"resourceRequirements,omitempty"`
+	FirelensConfiguration  *FirelensConfiguration `json:"firelensConfiguration,omitempty" yaml:"firelensConfiguration,omitempty"`
+	CredentialSpecs        []string               `json:"credentialSpecs,omitempty" yaml:"credentialSpecs,omitempty"`
+	// Store all other fields as-is; they are written back after the known fields
 	Extra map[string]interface{} `json:"-" yaml:"-"`
 }
 
+// The types below keep their unknown fields in Extra, like ContainerDefinition and TaskDefinition,
+// so that fields added to the ECS API survive a round trip
+
 // PortMapping represents a port mapping configuration
 type PortMapping struct {
-	ContainerPort int    `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
-	HostPort      int    `json:"hostPort,omitempty" yaml:"hostPort,omitempty"`
-	Protocol      string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	ContainerPort      int    `json:"containerPort,omitempty" yaml:"containerPort,omitempty"`
+	HostPort           int    `json:"hostPort,omitempty" yaml:"hostPort,omitempty"`
+	Protocol           string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Name               string `json:"name,omitempty" yaml:"name,omitempty"`
+	AppProtocol        string `json:"appProtocol,omitempty" yaml:"appProtocol,omitempty"`
+	ContainerPortRange string `json:"containerPortRange,omitempty" yaml:"containerPortRange,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
 }
 
 // EnvironmentVariable represents an environment variable
 type EnvironmentVariable struct {
 	Name  string `json:"name" yaml:"name"`
 	Value string `json:"value" yaml:"value"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// EnvironmentFile represents a file containing environment variables
+type EnvironmentFile struct {
+	Value string `json:"value" yaml:"value"`
+	Type  string `json:"type" yaml:"type"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Secret represents a secret exposed to a container from Secrets Manager or Parameter Store
+type Secret struct {
+	Name      string `json:"name" yaml:"name"`
+	ValueFrom string `json:"valueFrom" yaml:"valueFrom"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// LogConfiguration represents the log configuration of a container
+type LogConfiguration struct {
+	LogDriver     string            `json:"logDriver" yaml:"logDriver"`
+	Options       map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	SecretOptions []Secret          `json:"secretOptions,omitempty" yaml:"secretOptions,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// HealthCheck represents the health check of a container
+type HealthCheck struct {
+	Command     []string `json:"command" yaml:"command"`
+	Interval    int      `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout     int      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Retries     int      `json:"retries,omitempty" yaml:"retries,omitempty"`
+	StartPeriod int      `json:"startPeriod,omitempty" yaml:"startPeriod,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// RepositoryCredentials represents the private registry credentials of a container
+type RepositoryCredentials struct {
+	CredentialsParameter string `json:"credentialsParameter" yaml:"credentialsParameter"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// RestartPolicy represents the restart policy of a container
+type RestartPolicy struct {
+	Enabled              *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	IgnoredExitCodes     []int `json:"ignoredExitCodes,omitempty" yaml:"ignoredExitCodes,omitempty"`
+	RestartAttemptPeriod int   `json:"restartAttemptPeriod,omitempty" yaml:"restartAttemptPeriod,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// MountPoint represents a volume mounted into a container
+type MountPoint struct {
+	SourceVolume  string `json:"sourceVolume,omitempty" yaml:"sourceVolume,omitempty"`
+	ContainerPath string `json:"containerPath,omitempty" yaml:"containerPath,omitempty"`
+	ReadOnly      *bool  `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// VolumeFrom represents volumes mounted from another container
+type VolumeFrom struct {
+	SourceContainer string `json:"sourceContainer,omitempty" yaml:"sourceContainer,omitempty"`
+	ReadOnly        *bool  `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// LinuxParameters represents Linux-specific options of a container
+type LinuxParameters struct {
+	Capabilities       *KernelCapabilities `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	Devices            []Device            `json:"devices,omitempty" yaml:"devices,omitempty"`
+	InitProcessEnabled *bool               `json:"initProcessEnabled,omitempty" yaml:"initProcessEnabled,omitempty"`
+	SharedMemorySize   int                 `json:"sharedMemorySize,omitempty" yaml:"sharedMemorySize,omitempty"`
+	Tmpfs              []Tmpfs             `json:"tmpfs,omitempty" yaml:"tmpfs,omitempty"`
+	MaxSwap            *int                `json:"maxSwap,omitempty" yaml:"maxSwap,omitempty"`
+	Swappiness         *int                `json:"swappiness,omitempty" yaml:"swappiness,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// KernelCapabilities represents Linux capabilities added to or dropped from a container
+type KernelCapabilities struct {
+	Add  []string `json:"add,omitempty" yaml:"add,omitempty"`
+	Drop []string `json:"drop,omitempty" yaml:"drop,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Device represents a host device exposed to a container
+type Device struct {
+	HostPath      string   `json:"hostPath" yaml:"hostPath"`
+	ContainerPath string   `json:"containerPath,omitempty" yaml:"containerPath,omitempty"`
+	Permissions   []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Tmpfs represents a tmpfs mount of a container
+type Tmpfs struct {
+	ContainerPath string   `json:"containerPath" yaml:"containerPath"`
+	Size          int      `json:"size" yaml:"size"`
+	MountOptions  []string `json:"mountOptions,omitempty" yaml:"mountOptions,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// ContainerDependency represents a dependency on another container of the task
+type ContainerDependency struct {
+	ContainerName string `json:"containerName" yaml:"containerName"`
+	Condition     string `json:"condition" yaml:"condition"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// HostEntry represents an entry added to /etc/hosts of a container
+type HostEntry struct {
+	Hostname  string `json:"hostname" yaml:"hostname"`
+	IPAddress string `json:"ipAddress" yaml:"ipAddress"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Ulimit represents a resource limit of a container
+type Ulimit struct {
+	Name      string `json:"name" yaml:"name"`
+	SoftLimit int    `json:"softLimit" yaml:"softLimit"`
+	HardLimit int    `json:"hardLimit" yaml:"hardLimit"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// SystemControl represents a namespaced kernel parameter of a container
+type SystemControl struct {
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Value     string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// ResourceRequirement represents a GPU or Elastic Inference accelerator assigned to a container
+type ResourceRequirement struct {
+	Value string `json:"value" yaml:"value"`
+	Type  string `json:"type" yaml:"type"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// FirelensConfiguration represents the FireLens log router configuration of a container
+type FirelensConfiguration struct {
+	Type    string            `json:"type" yaml:"type"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
 }
 
 // TaskDefinition represents an ECS task definition
@@ -37,6 +239,138 @@ type TaskDefinition struct {
 	CPU                     string                 `json:"cpu,omitempty" yaml:"cpu,omitempty"`
 	Memory                  string                 `json:"memory,omitempty" yaml:"memory,omitempty"`
 	Revision                int                    `json:"revision,omitempty" yaml:"revision,omitempty"`
-	// Store all other fields as-is
+	Volumes                 []Volume               `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	PlacementConstraints    []PlacementConstraint  `json:"placementConstraints,omitempty" yaml:"placementConstraints,omitempty"`
+	RuntimePlatform         *RuntimePlatform       `json:"runtimePlatform,omitempty" yaml:"runtimePlatform,omitempty"`
+	EphemeralStorage        *EphemeralStorage      `json:"ephemeralStorage,omitempty" yaml:"ephemeralStorage,omitempty"`
+	ProxyConfiguration      *ProxyConfiguration    `json:"proxyConfiguration,omitempty" yaml:"proxyConfiguration,omitempty"`
+	PidMode                 string                 `json:"pidMode,omitempty" yaml:"pidMode,omitempty"`
+	IpcMode                 string                 `json:"ipcMode,omitempty" yaml:"ipcMode,omitempty"`
+	InferenceAccelerators   []InferenceAccelerator `json:"inferenceAccelerators,omitempty" yaml:"inferenceAccelerators,omitempty"`
+	Tags                    []Tag                  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Store all other fields as-is; they are written back after the known fields
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Volume represents a data volume of a task. At most one of Host, DockerVolumeConfiguration,
+// EFSVolumeConfiguration and FSxWindowsFileServerVolumeConfiguration is set.
+type Volume struct {
+	Name                                    string                                   `json:"name" yaml:"name"`
+	Host                                    *HostVolumeProperties                    `json:"host,omitempty" yaml:"host,omitempty"`
+	ConfiguredAtLaunch                      *bool                                    `json:"configuredAtLaunch,omitempty" yaml:"configuredAtLaunch,omitempty"`
+	DockerVolumeConfiguration               *DockerVolumeConfiguration               `json:"dockerVolumeConfiguration,omitempty" yaml:"dockerVolumeConfiguration,omitempty"`
+	EFSVolumeConfiguration                  *EFSVolumeConfiguration                  `json:"efsVolumeConfiguration,omitempty" yaml:"efsVolumeConfiguration,omitempty"`
+	FSxWindowsFileServerVolumeConfiguration *FSxWindowsFileServerVolumeConfiguration `json:"fsxWindowsFileServerVolumeConfiguration,omitempty" yaml:"fsxWindowsFileServerVolumeConfiguration,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// HostVolumeProperties represents a bind mount host volume
+type HostVolumeProperties struct {
+	SourcePath string `json:"sourcePath,omitempty" yaml:"sourcePath,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// DockerVolumeConfiguration represents a Docker volume
+type DockerVolumeConfiguration struct {
+	Scope         string            `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Autoprovision *bool             `json:"autoprovision,omitempty" yaml:"autoprovision,omitempty"`
+	Driver        string            `json:"driver,omitempty" yaml:"driver,omitempty"`
+	DriverOpts    map[string]string `json:"driverOpts,omitempty" yaml:"driverOpts,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// EFSVolumeConfiguration represents an Amazon EFS volume
+type EFSVolumeConfiguration struct {
+	FileSystemID          string                  `json:"fileSystemId" yaml:"fileSystemId"`
+	RootDirectory         string                  `json:"rootDirectory,omitempty" yaml:"rootDirectory,omitempty"`
+	TransitEncryption     string                  `json:"transitEncryption,omitempty" yaml:"transitEncryption,omitempty"`
+	TransitEncryptionPort int                     `json:"transitEncryptionPort,omitempty" yaml:"transitEncryptionPort,omitempty"`
+	AuthorizationConfig   *EFSAuthorizationConfig `json:"authorizationConfig,omitempty" yaml:"authorizationConfig,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// EFSAuthorizationConfig represents the authorization configuration of an EFS volume
+type EFSAuthorizationConfig struct {
+	AccessPointID string `json:"accessPointId,omitempty" yaml:"accessPointId,omitempty"`
+	IAM           string `json:"iam,omitempty" yaml:"iam,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// FSxWindowsFileServerVolumeConfiguration represents an Amazon FSx for Windows File Server volume
+type FSxWindowsFileServerVolumeConfiguration struct {
+	FileSystemID        string                                   `json:"fileSystemId" yaml:"fileSystemId"`
+	RootDirectory       string                                   `json:"rootDirectory" yaml:"rootDirectory"`
+	AuthorizationConfig *FSxWindowsFileServerAuthorizationConfig `json:"authorizationConfig,omitempty" yaml:"authorizationConfig,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// FSxWindowsFileServerAuthorizationConfig represents the authorization configuration of an FSx volume
+type FSxWindowsFileServerAuthorizationConfig struct {
+	CredentialsParameter string `json:"credentialsParameter" yaml:"credentialsParameter"`
+	Domain               string `json:"domain" yaml:"domain"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// PlacementConstraint represents a task placement constraint
+type PlacementConstraint struct {
+	Type       string `json:"type" yaml:"type"`
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// RuntimePlatform represents the operating system and CPU architecture a task runs on
+type RuntimePlatform struct {
+	CPUArchitecture       string `json:"cpuArchitecture,omitempty" yaml:"cpuArchitecture,omitempty"`
+	OperatingSystemFamily string `json:"operatingSystemFamily,omitempty" yaml:"operatingSystemFamily,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// EphemeralStorage represents the ephemeral storage of a Fargate task
+type EphemeralStorage struct {
+	SizeInGiB int `json:"sizeInGiB" yaml:"sizeInGiB"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// ProxyConfiguration represents the App Mesh proxy configuration of a task
+type ProxyConfiguration struct {
+	Type          string         `json:"type,omitempty" yaml:"type,omitempty"`
+	ContainerName string         `json:"containerName" yaml:"containerName"`
+	Properties    []KeyValuePair `json:"properties,omitempty" yaml:"properties,omitempty"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// KeyValuePair represents a name and value pair
+type KeyValuePair struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// InferenceAccelerator represents an Elastic Inference accelerator of a task
+type InferenceAccelerator struct {
+	DeviceName string `json:"deviceName" yaml:"deviceName"`
+	DeviceType string `json:"deviceType" yaml:"deviceType"`
+
+	Extra map[string]interface{} `json:"-" yaml:"-"`
+}
+
+// Tag represents a task definition tag
+type Tag struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+
 	Extra map[string]interface{} `json:"-" yaml:"-"`
 }
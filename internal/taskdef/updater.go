@@ -10,27 +10,43 @@ type UpdateOptions struct {
 	Tag           string
 	ContainerName string
 	ImageName     string
+	// Resource filters embedded documents by resource (e.g. CloudFormation logical ID, Terraform address or Kubernetes [namespace/]kind/name)
+	Resource string
+	// TagFunc, if set, returns the new tag for each matching container instead of Tag
+	TagFunc func(container ContainerDefinition) (string, error)
+	// DigestFunc, if set, returns the digest each updated image is pinned to
+	DigestFunc func(image string) (string, error)
 }
 
 // parseImage splits an image string into repository and tag
 // e.g., "nginx:latest" -> ("nginx", "latest")
 // e.g., "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.3" -> ("123456789.dkr.ecr.us-east-1.amazonaws.com/my-app", "v1.2.3")
+// e.g., "localhost:5000/my-app" -> ("localhost:5000/my-app", "")
 func parseImage(image string) (repository string, tag string) {
-	parts := strings.Split(image, ":")
-	if len(parts) == 1 {
+	// The last colon separates the tag unless it belongs to the registry host (e.g., URL with port).
+	// Colons inside ${...} substitutions of templated images (CloudFormation, Terraform, compose) are skipped.
+	idx := -1
+	depth := 0
+	for i := 0; i < len(image); i++ {
+		switch {
+		case strings.HasPrefix(image[i:], "${"):
+			depth++
+			i++
+		case image[i] == '}' && depth > 0:
+			depth--
+		case image[i] == ':' && depth == 0:
+			idx = i
+		}
+	}
+	if idx == -1 || strings.Contains(image[idx+1:], "/") {
 		return image, ""
 	}
 
-	// Handle case where there are multiple colons (e.g., URL with port)
-	// We assume the last colon separates the tag
-	repository = strings.Join(parts[:len(parts)-1], ":")
-	tag = parts[len(parts)-1]
-
-	return repository, tag
+	return image[:idx], image[idx+1:]
 }
 
-// matchesFilter checks if a container matches the filter criteria
-func matchesFilter(container *ContainerDefinition, opts UpdateOptions) bool {
+// MatchesFilter checks if a container matches the filter criteria
+func MatchesFilter(container *ContainerDefinition, opts UpdateOptions) bool {
 	// Filter by container name
 	if opts.ContainerName != "" && container.Name != opts.ContainerName {
 		return false
@@ -38,15 +54,9 @@ func matchesFilter(container *ContainerDefinition, opts UpdateOptions) bool {
 
 	// Filter by image repository name
 	if opts.ImageName != "" {
-		repository, _ := parseImage(container.Image)
-		// Extract just the repository name (without registry URL)
-		repoName := repository
-		if strings.Contains(repository, "/") {
-			parts := strings.Split(repository, "/")
-			repoName = parts[len(parts)-1]
-		}
-
-		if repoName != opts.ImageName && repository != opts.ImageName {
+		ref := ParseImageReference(container.Image)
+		// Match just the repository name (without registry URL) or the full repository
+		if ref.Name() != opts.ImageName && ref.Repository != opts.ImageName {
 			return false
 		}
 	}
@@ -54,58 +64,162 @@ func matchesFilter(container *ContainerDefinition, opts UpdateOptions) bool {
 	return true
 }
 
-// updateContainerImage updates the image tag for a single container
+// updateContainerImage updates the image tag for a single container. A pinned digest is dropped
+// because it belongs to the previous tag.
 func updateContainerImage(container *ContainerDefinition, newTag string) {
-	repository, _ := parseImage(container.Image)
-	container.Image = fmt.Sprintf("%s:%s", repository, newTag)
+	ref := ParseImageReference(container.Image)
+	container.Image = fmt.Sprintf("%s:%s", ref.Repository, newTag)
 }
 
-// UpdateTaskDefinition updates the container image tags in a task definition
-func UpdateTaskDefinition(taskDef *TaskDefinition, opts UpdateOptions) error {
-	updated := false
+// placeholderError reports a filter that only matched containers using image placeholders
+func placeholderError(container *ContainerDefinition) error {
+	return fmt.Errorf("container '%s' uses image placeholder %s; use the render command to substitute it", container.Name, container.Image)
+}
 
-	for i := range taskDef.ContainerDefinitions {
-		container := &taskDef.ContainerDefinitions[i]
-		if matchesFilter(container, opts) {
-			updateContainerImage(container, opts.Tag)
-			updated = true
-		}
-	}
+// ContainerMatch describes a container that matched the filters of an update
+type ContainerMatch struct {
+	// Resource is the resource of an embedded document the container belongs to (empty otherwise)
+	Resource string
+	Index    int
+	Name     string
+	OldImage string
+	NewImage string
+	// Placeholder is set for containers using image placeholders, which are left as-is
+	Placeholder bool
+}
 
-	if !updated && (opts.ContainerName != "" || opts.ImageName != "") {
-		// User specified a filter but no containers matched
-		if opts.ContainerName != "" {
-			return fmt.Errorf("container '%s' not found in definitions", opts.ContainerName)
-		}
-		if opts.ImageName != "" {
-			return fmt.Errorf("image '%s' not found in definitions", opts.ImageName)
+// FilterDiagnostic reports how many containers a single filter matched on its own
+type FilterDiagnostic struct {
+	// Filter is "container" or "image"
+	Filter  string
+	Value   string
+	Matched int
+}
+
+// UpdateResult describes the outcome of an update
+type UpdateResult struct {
+	// Matches lists the containers matching all filters, in order
+	Matches []ContainerMatch
+	// Misses lists the names of the containers that did not match the filters
+	Misses []string
+	// Filters has a diagnostic for each filter that was set
+	Filters []FilterDiagnostic
+}
+
+// Updated returns the matches whose image was changed
+func (r *UpdateResult) Updated() []ContainerMatch {
+	var updated []ContainerMatch
+	for _, m := range r.Matches {
+		if m.OldImage != m.NewImage {
+			updated = append(updated, m)
 		}
 	}
+	return updated
+}
 
-	return nil
+// FilterError reports every filter that could not be met by an update
+type FilterError struct {
+	Problems []string
 }
 
-// UpdateContainerDefinitions updates the container image tags in a list of container definitions
-func UpdateContainerDefinitions(containers []ContainerDefinition, opts UpdateOptions) ([]ContainerDefinition, error) {
-	updated := false
+func (e *FilterError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0]
+	}
+	return "no container matched the filters:\n  " + strings.Join(e.Problems, "\n  ")
+}
 
+// Update updates the image tags of the containers matching the filters in place.
+// Containers using image placeholders (e.g. <IMAGE1_NAME>) are left as-is. If a filter is set and
+// no container could be updated, a *FilterError listing every unmet filter is returned along
+// with the result.
+func Update(containers []ContainerDefinition, opts UpdateOptions) (*UpdateResult, error) {
+	result := &UpdateResult{}
+	filters := []FilterDiagnostic{
+		{Filter: "container", Value: opts.ContainerName},
+		{Filter: "image", Value: opts.ImageName},
+	}
+
+	updated := false
+	var placeholders []*ContainerDefinition
 	for i := range containers {
 		container := &containers[i]
-		if matchesFilter(container, opts) {
-			updateContainerImage(container, opts.Tag)
+		if opts.ContainerName != "" && MatchesFilter(container, UpdateOptions{ContainerName: opts.ContainerName}) {
+			filters[0].Matched++
+		}
+		if opts.ImageName != "" && MatchesFilter(container, UpdateOptions{ImageName: opts.ImageName}) {
+			filters[1].Matched++
+		}
+		if !MatchesFilter(container, opts) {
+			result.Misses = append(result.Misses, container.Name)
+			continue
+		}
+
+		match := ContainerMatch{Index: i, Name: container.Name, OldImage: container.Image}
+		if _, ok := ImagePlaceholder(container.Image); ok {
+			match.Placeholder = true
+			placeholders = append(placeholders, container)
+		} else {
+			tag := opts.Tag
+			if opts.TagFunc != nil {
+				var err error
+				if tag, err = opts.TagFunc(*container); err != nil {
+					return nil, err
+				}
+			}
+			updateContainerImage(container, tag)
+			if opts.DigestFunc != nil {
+				digest, err := opts.DigestFunc(container.Image)
+				if err != nil {
+					return nil, err
+				}
+				ref := ParseImageReference(container.Image)
+				ref.Digest = digest
+				container.Image = ref.String()
+			}
 			updated = true
 		}
+		match.NewImage = container.Image
+		result.Matches = append(result.Matches, match)
 	}
 
-	if !updated && (opts.ContainerName != "" || opts.ImageName != "") {
-		// User specified a filter but no containers matched
-		if opts.ContainerName != "" {
-			return nil, fmt.Errorf("container '%s' not found in definitions", opts.ContainerName)
+	for _, f := range filters {
+		if f.Value != "" {
+			result.Filters = append(result.Filters, f)
 		}
-		if opts.ImageName != "" {
-			return nil, fmt.Errorf("image '%s' not found in definitions", opts.ImageName)
+	}
+	if updated || len(result.Filters) == 0 {
+		return result, nil
+	}
+
+	// A filter was set but no container could be updated
+	var problems []string
+	for _, f := range result.Filters {
+		if f.Matched == 0 {
+			problems = append(problems, fmt.Sprintf("%s '%s' not found in definitions", f.Filter, f.Value))
 		}
 	}
+	if len(problems) == 0 && len(placeholders) == 0 {
+		problems = append(problems, fmt.Sprintf("no container has both name '%s' and image '%s'", opts.ContainerName, opts.ImageName))
+	}
+	for _, p := range placeholders {
+		problems = append(problems, placeholderError(p).Error())
+	}
+	return result, &FilterError{Problems: problems}
+}
 
+// UpdateTaskDefinition updates the container image tags in a task definition.
+// Containers using image placeholders (e.g. <IMAGE1_NAME>) are left as-is.
+func UpdateTaskDefinition(taskDef *TaskDefinition, opts UpdateOptions) error {
+	_, err := Update(taskDef.ContainerDefinitions, opts)
+	return err
+}
+
+// UpdateContainerDefinitions updates the container image tags in a list of container definitions.
+// Containers using image placeholders (e.g. <IMAGE1_NAME>) are left as-is.
+func UpdateContainerDefinitions(containers []ContainerDefinition, opts UpdateOptions) ([]ContainerDefinition, error) {
+	if _, err := Update(containers, opts); err != nil {
+		return nil, err
+	}
 	return containers, nil
 }
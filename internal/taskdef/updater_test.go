@@ -1,6 +1,8 @@
 package taskdef
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -35,6 +37,24 @@ func TestParseImage(t *testing.T) {
 			expectedRepo:   "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app",
 			expectedTag:    "",
 		},
+		{
+			name:           "Registry with port and no tag",
+			image:          "localhost:5000/my-app",
+			expectedRepo:   "localhost:5000/my-app",
+			expectedTag:    "",
+		},
+		{
+			name:           "Templated image with substitution tag",
+			image:          "${REGISTRY}/my-app:${TAG:-latest}",
+			expectedRepo:   "${REGISTRY}/my-app",
+			expectedTag:    "${TAG:-latest}",
+		},
+		{
+			name:           "Registry with port and tag",
+			image:          "localhost:5000/my-app:v1",
+			expectedRepo:   "localhost:5000/my-app",
+			expectedTag:    "v1",
+		},
 	}
 
 	for _, tt := range tests {
@@ -103,6 +123,45 @@ func TestUpdateTaskDefinition(t *testing.T) {
 					td.ContainerDefinitions[1].Image == "my-app:stable"
 			},
 		},
+		{
+			name: "Pinned digest is dropped",
+			taskDef: &TaskDefinition{
+				Family: "app",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "app", Image: "localhost:5000/my-app:v1.0@sha256:abcdef"},
+				},
+			},
+			opts: UpdateOptions{Tag: "v2.0", ImageName: "my-app"},
+			check: func(td *TaskDefinition) bool {
+				return td.ContainerDefinitions[0].Image == "localhost:5000/my-app:v2.0"
+			},
+		},
+		{
+			name: "Image placeholders are left as-is",
+			taskDef: &TaskDefinition{
+				Family: "app",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "<IMAGE1_NAME>"},
+					{Name: "api", Image: "api:v1.0"},
+				},
+			},
+			opts: UpdateOptions{Tag: "v2.0"},
+			check: func(td *TaskDefinition) bool {
+				return td.ContainerDefinitions[0].Image == "<IMAGE1_NAME>" &&
+					td.ContainerDefinitions[1].Image == "api:v2.0"
+			},
+		},
+		{
+			name: "Error: filter matches only a placeholder",
+			taskDef: &TaskDefinition{
+				Family: "app",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "<IMAGE1_NAME>"},
+				},
+			},
+			opts:    UpdateOptions{Tag: "v2.0", ContainerName: "web"},
+			wantErr: true,
+		},
 		{
 			name: "Error: container not found",
 			taskDef: &TaskDefinition{
@@ -200,3 +259,130 @@ func TestUpdateContainerDefinitions(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         UpdateOptions
+		wantUpdated  []string
+		wantMisses   []string
+		wantProblems []string
+	}{
+		{
+			name:        "No filters",
+			opts:        UpdateOptions{Tag: "v2"},
+			wantUpdated: []string{"web", "worker", "nginx"},
+		},
+		{
+			name:        "Container filter",
+			opts:        UpdateOptions{Tag: "v2", ContainerName: "worker"},
+			wantUpdated: []string{"worker"},
+			wantMisses:  []string{"web", "nginx", "app"},
+		},
+		{
+			name: "All unmet filters are listed",
+			opts: UpdateOptions{Tag: "v2", ContainerName: "api", ImageName: "redis"},
+			wantProblems: []string{
+				"container 'api' not found in definitions",
+				"image 'redis' not found in definitions",
+			},
+		},
+		{
+			name:         "Filters that match different containers",
+			opts:         UpdateOptions{Tag: "v2", ContainerName: "web", ImageName: "nginx"},
+			wantProblems: []string{"no container has both name 'web' and image 'nginx'"},
+		},
+		{
+			name:         "Filter that matches only a placeholder",
+			opts:         UpdateOptions{Tag: "v2", ContainerName: "app"},
+			wantProblems: []string{"container 'app' uses image placeholder <IMAGE1_NAME>; use the render command to substitute it"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := []ContainerDefinition{
+				{Name: "web", Image: "my-app:v1"},
+				{Name: "worker", Image: "my-app:v1"},
+				{Name: "nginx", Image: "nginx:1.25"},
+				{Name: "app", Image: "<IMAGE1_NAME>"},
+			}
+			result, err := Update(containers, tt.opts)
+
+			if tt.wantProblems != nil {
+				var filterErr *FilterError
+				if !errors.As(err, &filterErr) {
+					t.Fatalf("Update() error = %v, expected a FilterError", err)
+				}
+				if !reflect.DeepEqual(filterErr.Problems, tt.wantProblems) {
+					t.Errorf("Update() problems = %q, expected %q", filterErr.Problems, tt.wantProblems)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			var updated []string
+			for _, m := range result.Updated() {
+				updated = append(updated, m.Name)
+				if containers[m.Index].Image != m.NewImage || m.NewImage == m.OldImage {
+					t.Errorf("Update() match %+v does not reflect container %+v", m, containers[m.Index])
+				}
+			}
+			if !reflect.DeepEqual(updated, tt.wantUpdated) {
+				t.Errorf("Update() updated = %v, expected %v", updated, tt.wantUpdated)
+			}
+			if !reflect.DeepEqual(result.Misses, tt.wantMisses) {
+				t.Errorf("Update() misses = %v, expected %v", result.Misses, tt.wantMisses)
+			}
+		})
+	}
+}
+
+func TestUpdateFilterDiagnostics(t *testing.T) {
+	containers := []ContainerDefinition{
+		{Name: "web", Image: "my-app:v1"},
+		{Name: "worker", Image: "my-app:v1"},
+	}
+	result, err := Update(containers, UpdateOptions{Tag: "v2", ContainerName: "web", ImageName: "my-app"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	expected := []FilterDiagnostic{
+		{Filter: "container", Value: "web", Matched: 1},
+		{Filter: "image", Value: "my-app", Matched: 2},
+	}
+	if !reflect.DeepEqual(result.Filters, expected) {
+		t.Errorf("Update() filters = %+v, expected %+v", result.Filters, expected)
+	}
+}
+
+func TestUpdateTagFunc(t *testing.T) {
+	containers := []ContainerDefinition{
+		{Name: "web", Image: "my-app:v1"},
+		{Name: "nginx", Image: "nginx:1.25"},
+		{Name: "app", Image: "<IMAGE1_NAME>"},
+	}
+	tags := map[string]string{"my-app": "v2", "nginx": "1.27"}
+	var called []string
+	opts := UpdateOptions{TagFunc: func(c ContainerDefinition) (string, error) {
+		called = append(called, c.Name)
+		return tags[ParseImageReference(c.Image).Name()], nil
+	}}
+	if _, err := Update(containers, opts); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if containers[0].Image != "my-app:v2" || containers[1].Image != "nginx:1.27" {
+		t.Errorf("Update() images = %q, %q", containers[0].Image, containers[1].Image)
+	}
+	if !reflect.DeepEqual(called, []string{"web", "nginx"}) {
+		t.Errorf("TagFunc called for %v, expected placeholders to be skipped", called)
+	}
+
+	want := errors.New("lookup failed")
+	opts.TagFunc = func(ContainerDefinition) (string, error) { return "", want }
+	if _, err := Update(containers, opts); !errors.Is(err, want) {
+		t.Errorf("Update() error = %v, expected %v", err, want)
+	}
+}
@@ -0,0 +1,315 @@
+package taskdef
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError represents a violation of the ECS RegisterTaskDefinition constraints
+type ValidationError struct {
+	// Path is the JSON path of the offending field, e.g. $.containerDefinitions[0].image
+	Path    string `json:"path" yaml:"path"`
+	Message string `json:"message" yaml:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+var (
+	// namePattern matches valid family and container names
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// imagePattern matches the characters allowed in an image reference
+	imagePattern = regexp.MustCompile(`^[A-Za-z0-9_\-:./#@]+$`)
+)
+
+const maxNameLength = 255
+
+// fargateMemoryRange is the memory (MiB) supported with a Fargate task CPU value: either an
+// explicit list of values, or a range from min to max in steps
+type fargateMemoryRange struct {
+	values         []int
+	min, max, step int
+}
+
+// supports reports whether memory is supported
+func (r fargateMemoryRange) supports(memory int) bool {
+	if r.values != nil {
+		for _, v := range r.values {
+			if memory == v {
+				return true
+			}
+		}
+		return false
+	}
+	return memory >= r.min && memory <= r.max && (memory-r.min)%r.step == 0
+}
+
+// String describes the supported memory, e.g. "1024-4096 MiB in 1024 MiB increments"
+func (r fargateMemoryRange) String() string {
+	if r.values != nil {
+		s := make([]string, len(r.values))
+		for i, v := range r.values {
+			s[i] = strconv.Itoa(v)
+		}
+		return strings.Join(s[:len(s)-1], ", ") + " or " + s[len(s)-1] + " MiB"
+	}
+	return fmt.Sprintf("%d-%d MiB in %d MiB increments", r.min, r.max, r.step)
+}
+
+// fargateMemory maps Fargate task CPU units to the supported memory
+var fargateMemory = map[int]fargateMemoryRange{
+	256:   {values: []int{512, 1024, 2048}},
+	512:   {min: 1024, max: 4096, step: 1024},
+	1024:  {min: 2048, max: 8192, step: 1024},
+	2048:  {min: 4096, max: 16384, step: 1024},
+	4096:  {min: 8192, max: 30720, step: 1024},
+	8192:  {min: 16384, max: 61440, step: 4096},
+	16384: {min: 32768, max: 122880, step: 8192},
+}
+
+// validator collects validation errors
+type validator struct {
+	errs []ValidationError
+}
+
+func (v *validator) add(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkName checks the charset and length of a family or container name
+func (v *validator) checkName(path, name string) {
+	switch {
+	case name == "":
+		v.add(path, "is required")
+	case len(name) > maxNameLength:
+		v.add(path, "must be at most %d characters", maxNameLength)
+	case !namePattern.MatchString(name):
+		v.add(path, "must contain only letters, numbers, hyphens and underscores")
+	}
+}
+
+// ValidateTaskDefinition checks a task definition against the ECS RegisterTaskDefinition constraints
+func ValidateTaskDefinition(taskDef *TaskDefinition) []ValidationError {
+	v := &validator{}
+	v.checkName("$.family", taskDef.Family)
+
+	fargate := false
+	for _, c := range taskDef.RequiresCompatibilities {
+		if c == "FARGATE" {
+			fargate = true
+		}
+	}
+
+	cpu, cpuOK := 0, taskDef.CPU == ""
+	if taskDef.CPU != "" {
+		var err error
+		if cpu, err = parseTaskCPU(taskDef.CPU); err != nil {
+			v.add("$.cpu", "%v", err)
+		} else {
+			cpuOK = true
+		}
+	}
+	memory, memoryOK := 0, taskDef.Memory == ""
+	if taskDef.Memory != "" {
+		var err error
+		if memory, err = parseTaskMemory(taskDef.Memory); err != nil {
+			v.add("$.memory", "%v", err)
+		} else {
+			memoryOK = true
+		}
+	}
+
+	if fargate {
+		if taskDef.NetworkMode != "awsvpc" {
+			v.add("$.networkMode", "must be awsvpc for FARGATE (got %q)", taskDef.NetworkMode)
+		}
+		switch {
+		case taskDef.CPU == "":
+			v.add("$.cpu", "is required for FARGATE")
+		case taskDef.Memory == "":
+			v.add("$.memory", "is required for FARGATE")
+		case cpuOK && memoryOK:
+			if r, ok := fargateMemory[cpu]; !ok {
+				v.add("$.cpu", "%d is not a supported FARGATE CPU value (256, 512, 1024, 2048, 4096, 8192 or 16384)", cpu)
+			} else if !r.supports(memory) {
+				v.add("$.memory", "%d MiB is not supported with %d CPU units on FARGATE (%s)", memory, cpu, r)
+			}
+		}
+	}
+
+	if len(taskDef.ContainerDefinitions) == 0 {
+		v.add("$.containerDefinitions", "at least one container definition is required")
+	}
+	v.checkContainers("$.containerDefinitions", taskDef.ContainerDefinitions, taskDef.NetworkMode)
+
+	for i, c := range taskDef.ContainerDefinitions {
+		path := fmt.Sprintf("$.containerDefinitions[%d]", i)
+		if cpuOK && cpu > 0 && c.CPU > cpu {
+			v.add(path+".cpu", "%d exceeds the task CPU (%d)", c.CPU, cpu)
+		}
+		if memoryOK && memory > 0 && c.Memory > memory {
+			v.add(path+".memory", "%d MiB exceeds the task memory (%d MiB)", c.Memory, memory)
+		}
+	}
+
+	v.checkTaskLevel(taskDef)
+
+	return v.errs
+}
+
+// checkTaskLevel checks volumes, runtime platform, ephemeral storage and proxy configuration
+func (v *validator) checkTaskLevel(taskDef *TaskDefinition) {
+	volumes := make(map[string]int)
+	for i, vol := range taskDef.Volumes {
+		vpath := fmt.Sprintf("$.volumes[%d]", i)
+		if vol.Name == "" {
+			v.add(vpath+".name", "is required")
+			continue
+		}
+		if first, ok := volumes[vol.Name]; ok {
+			v.add(vpath+".name", "duplicate volume name %q (also used by $.volumes[%d])", vol.Name, first)
+			continue
+		}
+		volumes[vol.Name] = i
+		if vol.EFSVolumeConfiguration != nil && vol.EFSVolumeConfiguration.FileSystemID == "" {
+			v.add(vpath+".efsVolumeConfiguration.fileSystemId", "is required")
+		}
+	}
+	for i, c := range taskDef.ContainerDefinitions {
+		for j, mp := range c.MountPoints {
+			if _, ok := volumes[mp.SourceVolume]; !ok {
+				v.add(fmt.Sprintf("$.containerDefinitions[%d].mountPoints[%d].sourceVolume", i, j), "references undeclared volume %q", mp.SourceVolume)
+			}
+		}
+	}
+
+	if p := taskDef.RuntimePlatform; p != nil && p.CPUArchitecture != "" && p.CPUArchitecture != "X86_64" && p.CPUArchitecture != "ARM64" {
+		v.add("$.runtimePlatform.cpuArchitecture", "must be X86_64 or ARM64 (got %q)", p.CPUArchitecture)
+	}
+	if e := taskDef.EphemeralStorage; e != nil && (e.SizeInGiB < 21 || e.SizeInGiB > 200) {
+		v.add("$.ephemeralStorage.sizeInGiB", "must be between 21 and 200")
+	}
+	if taskDef.PidMode != "" && taskDef.PidMode != "host" && taskDef.PidMode != "task" {
+		v.add("$.pidMode", "must be host or task")
+	}
+	if taskDef.IpcMode != "" && taskDef.IpcMode != "host" && taskDef.IpcMode != "task" && taskDef.IpcMode != "none" {
+		v.add("$.ipcMode", "must be host, task or none")
+	}
+
+	if p := taskDef.ProxyConfiguration; p != nil {
+		if p.ContainerName == "" {
+			v.add("$.proxyConfiguration.containerName", "is required")
+		} else {
+			found := false
+			for _, c := range taskDef.ContainerDefinitions {
+				if c.Name == p.ContainerName {
+					found = true
+				}
+			}
+			if !found {
+				v.add("$.proxyConfiguration.containerName", "references unknown container %q", p.ContainerName)
+			}
+		}
+	}
+}
+
+// ValidateContainerDefinitions checks container definitions against the ECS RegisterTaskDefinition constraints
+func ValidateContainerDefinitions(containers []ContainerDefinition) []ValidationError {
+	v := &validator{}
+	v.checkContainers("$", containers, "")
+	return v.errs
+}
+
+// checkContainers checks the container-level constraints
+func (v *validator) checkContainers(path string, containers []ContainerDefinition, networkMode string) {
+	names := make(map[string]int)
+	essential := false
+	for i, c := range containers {
+		cpath := fmt.Sprintf("%s[%d]", path, i)
+
+		v.checkName(cpath+".name", c.Name)
+		if first, ok := names[c.Name]; ok && c.Name != "" {
+			v.add(cpath+".name", "duplicate container name %q (also used by %s[%d])", c.Name, path, first)
+		} else {
+			names[c.Name] = i
+		}
+
+		if _, ok := ImagePlaceholder(c.Image); !ok {
+			switch {
+			case c.Image == "":
+				v.add(cpath+".image", "is required")
+			case len(c.Image) > maxNameLength:
+				v.add(cpath+".image", "must be at most %d characters", maxNameLength)
+			case !imagePattern.MatchString(c.Image):
+				v.add(cpath+".image", "contains invalid characters")
+			}
+		}
+
+		if c.CPU < 0 {
+			v.add(cpath+".cpu", "must not be negative")
+		}
+		if c.Memory != 0 && c.Memory < 6 {
+			v.add(cpath+".memory", "must be at least 6 MiB")
+		}
+		if c.Essential == nil || *c.Essential {
+			essential = true
+		}
+
+		for j, pm := range c.PortMappings {
+			ppath := fmt.Sprintf("%s.portMappings[%d]", cpath, j)
+			if pm.ContainerPort < 1 || pm.ContainerPort > 65535 {
+				v.add(ppath+".containerPort", "must be between 1 and 65535")
+			}
+			if pm.HostPort < 0 || pm.HostPort > 65535 {
+				v.add(ppath+".hostPort", "must be between 0 and 65535")
+			}
+			if (networkMode == "awsvpc" || networkMode == "host") && pm.HostPort != 0 && pm.HostPort != pm.ContainerPort {
+				v.add(ppath+".hostPort", "must equal containerPort (%d) in %s network mode", pm.ContainerPort, networkMode)
+			}
+			if pm.Protocol != "" && pm.Protocol != "tcp" && pm.Protocol != "udp" {
+				v.add(ppath+".protocol", "must be tcp or udp")
+			}
+		}
+	}
+
+	if len(containers) > 0 && !essential {
+		v.add(path, "at least one container must be essential")
+	}
+}
+
+// parseTaskCPU parses task-level CPU given in units ("1024") or vCPUs ("1 vCPU")
+func parseTaskCPU(s string) (int, error) {
+	value := strings.TrimSpace(strings.ToLower(s))
+	if strings.HasSuffix(value, "vcpu") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "vcpu")), 64)
+		if err != nil || f <= 0 {
+			return 0, fmt.Errorf("invalid CPU value %q", s)
+		}
+		return int(f * 1024), nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid CPU value %q", s)
+	}
+	return n, nil
+}
+
+// parseTaskMemory parses task-level memory given in MiB ("512") or GB ("2 GB")
+func parseTaskMemory(s string) (int, error) {
+	value := strings.TrimSpace(strings.ToLower(s))
+	if strings.HasSuffix(value, "gb") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(value, "gb")), 64)
+		if err != nil || f <= 0 {
+			return 0, fmt.Errorf("invalid memory value %q", s)
+		}
+		return int(f * 1024), nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(value, "mib")))
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid memory value %q", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,202 @@
+package taskdef
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTaskDefinition(t *testing.T) {
+	essentialFalse := false
+
+	tests := []struct {
+		name      string
+		taskDef   *TaskDefinition
+		wantPaths []string
+	}{
+		{
+			name: "Valid FARGATE task definition",
+			taskDef: &TaskDefinition{
+				Family:                  "my-app",
+				NetworkMode:             "awsvpc",
+				RequiresCompatibilities: []string{"FARGATE"},
+				CPU:                     "1 vCPU",
+				Memory:                  "2 GB",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "nginx:latest", PortMappings: []PortMapping{{ContainerPort: 80, HostPort: 80, Protocol: "tcp"}}},
+					{Name: "app", Image: "<IMAGE1_NAME>"},
+				},
+			},
+		},
+		{
+			name: "Missing required fields",
+			taskDef: &TaskDefinition{
+				ContainerDefinitions: []ContainerDefinition{{}},
+			},
+			wantPaths: []string{"$.family", "$.containerDefinitions[0].name", "$.containerDefinitions[0].image"},
+		},
+		{
+			name: "Invalid names and duplicates",
+			taskDef: &TaskDefinition{
+				Family: "my app",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "nginx"},
+					{Name: "web", Image: "nginx latest"},
+					{Name: strings.Repeat("a", 256), Image: "nginx"},
+				},
+			},
+			wantPaths: []string{"$.family", "$.containerDefinitions[1].name", "$.containerDefinitions[1].image", "$.containerDefinitions[2].name"},
+		},
+		{
+			name: "FARGATE constraints",
+			taskDef: &TaskDefinition{
+				Family:                  "my-app",
+				NetworkMode:             "bridge",
+				RequiresCompatibilities: []string{"FARGATE"},
+				CPU:                     "256",
+				Memory:                  "4096",
+				ContainerDefinitions:    []ContainerDefinition{{Name: "web", Image: "nginx", Memory: 8192}},
+			},
+			wantPaths: []string{"$.networkMode", "$.memory", "$.containerDefinitions[0].memory"},
+		},
+		{
+			name: "FARGATE 256 CPU units with 1536 MiB",
+			taskDef: &TaskDefinition{
+				Family:                  "my-app",
+				NetworkMode:             "awsvpc",
+				RequiresCompatibilities: []string{"FARGATE"},
+				CPU:                     "256",
+				Memory:                  "1536",
+				ContainerDefinitions:    []ContainerDefinition{{Name: "web", Image: "nginx"}},
+			},
+			wantPaths: []string{"$.memory"},
+		},
+		{
+			name: "FARGATE 512 CPU units with 3072 MiB",
+			taskDef: &TaskDefinition{
+				Family:                  "my-app",
+				NetworkMode:             "awsvpc",
+				RequiresCompatibilities: []string{"FARGATE"},
+				CPU:                     "512",
+				Memory:                  "3072",
+				ContainerDefinitions:    []ContainerDefinition{{Name: "web", Image: "nginx"}},
+			},
+		},
+		{
+			name: "FARGATE without CPU",
+			taskDef: &TaskDefinition{
+				Family:                  "my-app",
+				NetworkMode:             "awsvpc",
+				RequiresCompatibilities: []string{"FARGATE"},
+				ContainerDefinitions:    []ContainerDefinition{{Name: "web", Image: "nginx"}},
+			},
+			wantPaths: []string{"$.cpu"},
+		},
+		{
+			name: "Port mapping rules",
+			taskDef: &TaskDefinition{
+				Family:      "my-app",
+				NetworkMode: "awsvpc",
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "nginx", Essential: &essentialFalse, PortMappings: []PortMapping{
+						{ContainerPort: 80, HostPort: 8080},
+						{ContainerPort: 0, Protocol: "http"},
+					}},
+				},
+			},
+			wantPaths: []string{
+				"$.containerDefinitions",
+				"$.containerDefinitions[0].portMappings[0].hostPort",
+				"$.containerDefinitions[0].portMappings[1].containerPort",
+				"$.containerDefinitions[0].portMappings[1].protocol",
+			},
+		},
+		{
+			name: "Task-level fields",
+			taskDef: &TaskDefinition{
+				Family: "my-app",
+				Volumes: []Volume{
+					{Name: "data", EFSVolumeConfiguration: &EFSVolumeConfiguration{}},
+					{Name: "data"},
+				},
+				RuntimePlatform:    &RuntimePlatform{CPUArchitecture: "AMD64"},
+				EphemeralStorage:   &EphemeralStorage{SizeInGiB: 10},
+				PidMode:            "container",
+				ProxyConfiguration: &ProxyConfiguration{Type: "APPMESH", ContainerName: "envoy"},
+				ContainerDefinitions: []ContainerDefinition{
+					{Name: "web", Image: "nginx", MountPoints: []MountPoint{
+						{SourceVolume: "data", ContainerPath: "/data"},
+						{SourceVolume: "cache", ContainerPath: "/cache"},
+					}},
+				},
+			},
+			wantPaths: []string{
+				"$.volumes[0].efsVolumeConfiguration.fileSystemId",
+				"$.volumes[1].name",
+				"$.containerDefinitions[0].mountPoints[1].sourceVolume",
+				"$.runtimePlatform.cpuArchitecture",
+				"$.ephemeralStorage.sizeInGiB",
+				"$.pidMode",
+				"$.proxyConfiguration.containerName",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateTaskDefinition(tt.taskDef)
+			paths := make(map[string]bool)
+			for _, e := range errs {
+				paths[e.Path] = true
+			}
+			for _, p := range tt.wantPaths {
+				if !paths[p] {
+					t.Errorf("ValidateTaskDefinition() missing error for %s, got %v", p, errs)
+				}
+			}
+			if len(tt.wantPaths) == 0 && len(errs) > 0 {
+				t.Errorf("ValidateTaskDefinition() unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestValidateContainerDefinitions(t *testing.T) {
+	errs := ValidateContainerDefinitions([]ContainerDefinition{
+		{Name: "web", Image: "nginx:latest"},
+		{Name: "api"},
+	})
+	if len(errs) != 1 || errs[0].Path != "$[1].image" {
+		t.Errorf("ValidateContainerDefinitions() = %v, expected a single error for $[1].image", errs)
+	}
+}
+
+func TestParseTaskCPUAndMemory(t *testing.T) {
+	tests := []struct {
+		input   string
+		parse   func(string) (int, error)
+		want    int
+		wantErr bool
+	}{
+		{input: "256", parse: parseTaskCPU, want: 256},
+		{input: "0.25 vCPU", parse: parseTaskCPU, want: 256},
+		{input: "2 vcpu", parse: parseTaskCPU, want: 2048},
+		{input: "abc", parse: parseTaskCPU, wantErr: true},
+		{input: "512", parse: parseTaskMemory, want: 512},
+		{input: "2 GB", parse: parseTaskMemory, want: 2048},
+		{input: "0.5GB", parse: parseTaskMemory, want: 512},
+		{input: "-1", parse: parseTaskMemory, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := tt.parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parse(%q) = %d, expected %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
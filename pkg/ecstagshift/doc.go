@@ -0,0 +1,22 @@
+// Package ecstagshift is the public Go API of ecs-tag-shift.
+//
+// It loads ECS task definitions and container definitions (JSON or JSONC),
+// parses image references, selects containers by name or image, shifts image
+// tags and writes the result in the same formats as the CLI.
+//
+// The package follows semantic versioning: exported identifiers are not
+// removed or changed incompatibly within a major version. Packages under
+// internal/ are implementation details and carry no such guarantee.
+//
+// Most types of this package, such as TaskDefinition and LogConfiguration,
+// are aliases of types defined under internal/. The guarantee covers them as
+// they are seen through this package: their exported fields and methods are
+// kept compatible, even though the types they alias are not themselves part
+// of the public API. The fields of the aliased types are recorded in
+// testdata/api.txt, and a test fails if an internal change removes or changes
+// one of them.
+//
+// Errors can be inspected with errors.As: the loaders return *ParseError and
+// *StrictError, and the update functions return *FilterError when no
+// container matches the filter.
+package ecstagshift
@@ -0,0 +1,234 @@
+package ecstagshift
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dev-shimada/ecs-tag-shift/internal/output"
+	"github.com/dev-shimada/ecs-tag-shift/internal/taskdef"
+)
+
+// Task definition types and the types of their fields. Unknown fields are kept in Extra and
+// written back as-is when the task or container definition they belong to is encoded.
+type (
+	TaskDefinition                          = taskdef.TaskDefinition
+	ContainerDefinition                     = taskdef.ContainerDefinition
+	PortMapping                             = taskdef.PortMapping
+	EnvironmentVariable                     = taskdef.EnvironmentVariable
+	EnvironmentFile                         = taskdef.EnvironmentFile
+	Secret                                  = taskdef.Secret
+	LogConfiguration                        = taskdef.LogConfiguration
+	HealthCheck                             = taskdef.HealthCheck
+	RepositoryCredentials                   = taskdef.RepositoryCredentials
+	RestartPolicy                           = taskdef.RestartPolicy
+	MountPoint                              = taskdef.MountPoint
+	VolumeFrom                              = taskdef.VolumeFrom
+	LinuxParameters                         = taskdef.LinuxParameters
+	KernelCapabilities                      = taskdef.KernelCapabilities
+	Device                                  = taskdef.Device
+	Tmpfs                                   = taskdef.Tmpfs
+	ContainerDependency                     = taskdef.ContainerDependency
+	HostEntry                               = taskdef.HostEntry
+	Ulimit                                  = taskdef.Ulimit
+	SystemControl                           = taskdef.SystemControl
+	ResourceRequirement                     = taskdef.ResourceRequirement
+	FirelensConfiguration                   = taskdef.FirelensConfiguration
+	Volume                                  = taskdef.Volume
+	HostVolumeProperties                    = taskdef.HostVolumeProperties
+	DockerVolumeConfiguration               = taskdef.DockerVolumeConfiguration
+	EFSVolumeConfiguration                  = taskdef.EFSVolumeConfiguration
+	EFSAuthorizationConfig                  = taskdef.EFSAuthorizationConfig
+	FSxWindowsFileServerVolumeConfiguration = taskdef.FSxWindowsFileServerVolumeConfiguration
+	FSxWindowsFileServerAuthorizationConfig = taskdef.FSxWindowsFileServerAuthorizationConfig
+	PlacementConstraint                     = taskdef.PlacementConstraint
+	RuntimePlatform                         = taskdef.RuntimePlatform
+	EphemeralStorage                        = taskdef.EphemeralStorage
+	ProxyConfiguration                      = taskdef.ProxyConfiguration
+	KeyValuePair                            = taskdef.KeyValuePair
+	InferenceAccelerator                    = taskdef.InferenceAccelerator
+	Tag                                     = taskdef.Tag
+)
+
+// ImageReference is a parsed image reference of the form repository[:tag][@digest]
+type ImageReference = taskdef.ImageReference
+
+// Error types returned by the loaders and Validate.
+type (
+	// ParseError reports malformed JSON or a type mismatch with its line and column
+	ParseError = taskdef.ParseError
+	// StrictError lists the duplicate and unknown keys found with LoadOptions.Strict
+	StrictError = taskdef.StrictError
+	// ValidationError reports a violated ECS constraint with the JSON path of the field
+	ValidationError = taskdef.ValidationError
+)
+
+// LoadOptions represents options for loading task and container definitions
+type LoadOptions = taskdef.LoadOptions
+
+// Format represents an output format
+type Format = output.OutputFormat
+
+const (
+	FormatJSON Format = output.FormatJSON
+	FormatYAML Format = output.FormatYAML
+	// FormatText is only supported by the summary writers
+	FormatText Format = output.FormatText
+)
+
+// ParseImageReference parses an image reference such as "nginx:1.25" or
+// "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.3@sha256:..."
+func ParseImageReference(image string) ImageReference {
+	return taskdef.ParseImageReference(image)
+}
+
+// LoadTaskDefinition loads a task definition from JSON or JSONC
+func LoadTaskDefinition(r io.Reader, opts LoadOptions) (*TaskDefinition, error) {
+	data, err := taskdef.LoadWithOptions(r, taskdef.ModeTask, opts)
+	if err != nil {
+		return nil, err
+	}
+	return data.(*TaskDefinition), nil
+}
+
+// LoadTaskDefinitionFile loads a task definition from a JSON or JSONC file
+func LoadTaskDefinitionFile(filename string, opts LoadOptions) (*TaskDefinition, error) {
+	data, err := taskdef.LoadFromFileWithOptions(filename, taskdef.ModeTask, opts)
+	if err != nil {
+		return nil, err
+	}
+	return data.(*TaskDefinition), nil
+}
+
+// LoadContainerDefinitions loads a container definitions array from JSON or JSONC
+func LoadContainerDefinitions(r io.Reader, opts LoadOptions) ([]ContainerDefinition, error) {
+	data, err := taskdef.LoadWithOptions(r, taskdef.ModeContainer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]ContainerDefinition), nil
+}
+
+// LoadContainerDefinitionsFile loads a container definitions array from a JSON or JSONC file
+func LoadContainerDefinitionsFile(filename string, opts LoadOptions) ([]ContainerDefinition, error) {
+	data, err := taskdef.LoadFromFileWithOptions(filename, taskdef.ModeContainer, opts)
+	if err != nil {
+		return nil, err
+	}
+	return data.([]ContainerDefinition), nil
+}
+
+// Filter selects containers by name and/or image. Empty fields match any container.
+type Filter struct {
+	ContainerName string
+	// ImageName matches the repository name (e.g. "my-app") or the full repository including the registry
+	ImageName string
+}
+
+// Match reports whether a container matches the filter
+func (f Filter) Match(container ContainerDefinition) bool {
+	return taskdef.MatchesFilter(&container, f.updateOptions(""))
+}
+
+// Select returns the indexes of the containers matching the filter
+func (f Filter) Select(containers []ContainerDefinition) []int {
+	var indexes []int
+	for i, c := range containers {
+		if f.Match(c) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (f Filter) updateOptions(tag string) taskdef.UpdateOptions {
+	return taskdef.UpdateOptions{Tag: tag, ContainerName: f.ContainerName, ImageName: f.ImageName}
+}
+
+// UpdateOptions represents options for shifting image tags
+type UpdateOptions struct {
+	// Tag is the new image tag
+	Tag    string
+	Filter Filter
+}
+
+// ImageChange describes the image of a single container before and after an update
+type ImageChange struct {
+	Container string
+	OldImage  string
+	NewImage  string
+}
+
+// UpdateResult describes the changes made by an update
+type UpdateResult struct {
+	Changes []ImageChange
+}
+
+// FilterError is returned by UpdateTaskDefinition and UpdateContainerDefinitions when a filter is
+// set and no container could be updated. Problems describes every unmet filter.
+type FilterError struct {
+	Problems []string
+}
+
+func (e *FilterError) Error() string {
+	return (&taskdef.FilterError{Problems: e.Problems}).Error()
+}
+
+// UpdateTaskDefinition shifts the image tags of the matching containers in place.
+// Containers using image placeholders (e.g. <IMAGE1_NAME>) are left as-is. If a filter is set
+// and no container could be updated, a *FilterError lists every unmet filter.
+func UpdateTaskDefinition(taskDef *TaskDefinition, opts UpdateOptions) (*UpdateResult, error) {
+	return UpdateContainerDefinitions(taskDef.ContainerDefinitions, opts)
+}
+
+// UpdateContainerDefinitions shifts the image tags of the matching containers in place.
+// It behaves like UpdateTaskDefinition.
+func UpdateContainerDefinitions(containers []ContainerDefinition, opts UpdateOptions) (*UpdateResult, error) {
+	if opts.Tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+	updated, err := taskdef.Update(containers, opts.Filter.updateOptions(opts.Tag))
+	var filterErr *taskdef.FilterError
+	if errors.As(err, &filterErr) {
+		return nil, &FilterError{Problems: filterErr.Problems}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpdateResult{}
+	for _, m := range updated.Updated() {
+		result.Changes = append(result.Changes, ImageChange{Container: m.Name, OldImage: m.OldImage, NewImage: m.NewImage})
+	}
+	return result, nil
+}
+
+// ValidateTaskDefinition checks a task definition against the ECS RegisterTaskDefinition constraints
+func ValidateTaskDefinition(taskDef *TaskDefinition) []ValidationError {
+	return taskdef.ValidateTaskDefinition(taskDef)
+}
+
+// ValidateContainerDefinitions checks container definitions against the ECS RegisterTaskDefinition constraints
+func ValidateContainerDefinitions(containers []ContainerDefinition) []ValidationError {
+	return taskdef.ValidateContainerDefinitions(containers)
+}
+
+// WriteTaskDefinition writes the full task definition as JSON or YAML, as the shift command does
+func WriteTaskDefinition(w io.Writer, taskDef *TaskDefinition, format Format) error {
+	return output.FormatTaskDefinitionFull(w, taskDef, format)
+}
+
+// WriteContainerDefinitions writes the full container definitions as JSON or YAML, as the shift command does
+func WriteContainerDefinitions(w io.Writer, containers []ContainerDefinition, format Format) error {
+	return output.FormatContainerDefinitionsFull(w, containers, format)
+}
+
+// WriteTaskDefinitionSummary writes the family and container images, as the show command does
+func WriteTaskDefinitionSummary(w io.Writer, taskDef *TaskDefinition, format Format) error {
+	return output.FormatTaskDefinition(w, taskDef, format, false)
+}
+
+// WriteContainerDefinitionsSummary writes the container images, as the show command does
+func WriteContainerDefinitionsSummary(w io.Writer, containers []ContainerDefinition, format Format) error {
+	return output.FormatContainerDefinitions(w, containers, format, false)
+}
@@ -0,0 +1,205 @@
+package ecstagshift
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTaskDefinitionFile(t *testing.T) {
+	taskDef, err := LoadTaskDefinitionFile(filepath.Join("..", "..", "examples", "task-definition.jsonc"), LoadOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("LoadTaskDefinitionFile() error = %v", err)
+	}
+	if taskDef.Family == "" || len(taskDef.ContainerDefinitions) == 0 {
+		t.Errorf("LoadTaskDefinitionFile() = %+v", taskDef)
+	}
+
+	containers, err := LoadContainerDefinitionsFile(filepath.Join("..", "..", "examples", "container-definitions.json"), LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadContainerDefinitionsFile() error = %v", err)
+	}
+	if len(containers) == 0 {
+		t.Errorf("LoadContainerDefinitionsFile() returned no containers")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := LoadTaskDefinition(strings.NewReader(`{"family": 1}`), LoadOptions{})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 1 {
+		t.Errorf("LoadTaskDefinition() error = %v, expected a ParseError", err)
+	}
+
+	_, err = LoadContainerDefinitions(strings.NewReader(`[{"name": "web", "imgae": "nginx"}]`), LoadOptions{Strict: true})
+	var strictErr *StrictError
+	if !errors.As(err, &strictErr) || len(strictErr.Problems) != 1 {
+		t.Errorf("LoadContainerDefinitions() error = %v, expected a StrictError", err)
+	}
+}
+
+func TestUpdateContainerDefinitions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        UpdateOptions
+		wantChanges []ImageChange
+		wantErr     bool
+	}{
+		{
+			name: "All containers",
+			opts: UpdateOptions{Tag: "v2"},
+			wantChanges: []ImageChange{
+				{Container: "web", OldImage: "my-app:v1", NewImage: "my-app:v2"},
+				{Container: "nginx", OldImage: "nginx:1.25", NewImage: "nginx:v2"},
+			},
+		},
+		{
+			name:        "Filter by image",
+			opts:        UpdateOptions{Tag: "v2", Filter: Filter{ImageName: "my-app"}},
+			wantChanges: []ImageChange{{Container: "web", OldImage: "my-app:v1", NewImage: "my-app:v2"}},
+		},
+		{
+			name:        "Unchanged tag",
+			opts:        UpdateOptions{Tag: "v1", Filter: Filter{ContainerName: "web"}},
+			wantChanges: nil,
+		},
+		{
+			name:    "No matching container",
+			opts:    UpdateOptions{Tag: "v2", Filter: Filter{ContainerName: "api"}},
+			wantErr: true,
+		},
+		{
+			name:    "Missing tag",
+			opts:    UpdateOptions{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := []ContainerDefinition{
+				{Name: "web", Image: "my-app:v1"},
+				{Name: "nginx", Image: "nginx:1.25"},
+				{Name: "app", Image: "<IMAGE1_NAME>"},
+			}
+			result, err := UpdateContainerDefinitions(containers, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateContainerDefinitions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(result.Changes) != len(tt.wantChanges) {
+				t.Fatalf("UpdateContainerDefinitions() changes = %+v, expected %+v", result.Changes, tt.wantChanges)
+			}
+			for i, c := range result.Changes {
+				if c != tt.wantChanges[i] {
+					t.Errorf("change[%d] = %+v, expected %+v", i, c, tt.wantChanges[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTaskDefinition(t *testing.T) {
+	taskDef, err := LoadTaskDefinition(strings.NewReader(`{"family": "my-app", "containerDefinitions": [{"name": "web", "image": "<IMAGE1_NAME>"}], "futureField": 1}`), LoadOptions{})
+	if err != nil {
+		t.Fatalf("LoadTaskDefinition() error = %v", err)
+	}
+	if errs := ValidateTaskDefinition(taskDef); len(errs) != 0 {
+		t.Errorf("ValidateTaskDefinition() = %v", errs)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteTaskDefinition(buf, taskDef, FormatJSON); err != nil {
+		t.Fatalf("WriteTaskDefinition() error = %v", err)
+	}
+	for _, want := range []string{`"image": "<IMAGE1_NAME>"`, `"futureField": 1`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteTaskDefinition() output does not contain %s:\n%s", want, buf.String())
+		}
+	}
+
+	if err := WriteTaskDefinition(buf, taskDef, FormatText); err == nil {
+		t.Errorf("WriteTaskDefinition() expected an error for text format")
+	}
+}
+
+func TestNestedTypes(t *testing.T) {
+	// Nested fields can be built with the types of this package
+	taskDef := &TaskDefinition{
+		Family: "my-app",
+		ContainerDefinitions: []ContainerDefinition{{
+			Name:             "web",
+			Image:            "my-app:v1",
+			LogConfiguration: &LogConfiguration{LogDriver: "awslogs", SecretOptions: []Secret{{Name: "key", ValueFrom: "arn"}}},
+			PortMappings:     []PortMapping{{ContainerPort: 80}},
+			HealthCheck:      &HealthCheck{Command: []string{"CMD", "true"}},
+			Environment:      []EnvironmentVariable{{Name: "ENV", Value: "prod"}},
+		}},
+		Volumes:         []Volume{{Name: "data", EFSVolumeConfiguration: &EFSVolumeConfiguration{FileSystemID: "fs-1"}}},
+		RuntimePlatform: &RuntimePlatform{CPUArchitecture: "ARM64"},
+		Tags:            []Tag{{Key: "team", Value: "web"}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteTaskDefinition(buf, taskDef, FormatJSON); err != nil {
+		t.Fatalf("WriteTaskDefinition() error = %v", err)
+	}
+	for _, want := range []string{`"logDriver": "awslogs"`, `"containerPort": 80`, `"fileSystemId": "fs-1"`, `"cpuArchitecture": "ARM64"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteTaskDefinition() output does not contain %s:\n%s", want, buf.String())
+		}
+	}
+}
+
+// aliasedTypes are the types of this package that alias internal types
+var aliasedTypes = []interface{}{
+	TaskDefinition{}, ContainerDefinition{}, PortMapping{}, EnvironmentVariable{}, EnvironmentFile{},
+	Secret{}, LogConfiguration{}, HealthCheck{}, RepositoryCredentials{}, RestartPolicy{}, MountPoint{},
+	VolumeFrom{}, LinuxParameters{}, KernelCapabilities{}, Device{}, Tmpfs{}, ContainerDependency{},
+	HostEntry{}, Ulimit{}, SystemControl{}, ResourceRequirement{}, FirelensConfiguration{}, Volume{},
+	HostVolumeProperties{}, DockerVolumeConfiguration{}, EFSVolumeConfiguration{}, EFSAuthorizationConfig{},
+	FSxWindowsFileServerVolumeConfiguration{}, FSxWindowsFileServerAuthorizationConfig{},
+	PlacementConstraint{}, RuntimePlatform{}, EphemeralStorage{}, ProxyConfiguration{}, KeyValuePair{},
+	InferenceAccelerator{}, Tag{}, ImageReference{}, ParseError{}, StrictError{}, ValidationError{},
+	LoadOptions{},
+}
+
+// TestAliasedTypesCompatibility fails when a field of an aliased type listed in testdata/api.txt
+// is removed or changed, which would break the compatibility guarantee of the package through an
+// internal change. New fields are compatible; add them to testdata/api.txt.
+func TestAliasedTypesCompatibility(t *testing.T) {
+	current := make(map[string]bool)
+	for _, v := range aliasedTypes {
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.IsExported() {
+				current[strings.TrimSpace(fmt.Sprintf("%s.%s %s %s", typ.Name(), f.Name, f.Type, f.Tag))] = true
+			}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "api.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		listed[line] = true
+		if !current[line] {
+			t.Errorf("field removed or changed: %s", line)
+		}
+	}
+	for line := range current {
+		if !listed[line] {
+			t.Errorf("field not listed in testdata/api.txt: %s", line)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package ecstagshift_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dev-shimada/ecs-tag-shift/pkg/ecstagshift"
+)
+
+func ExampleParseImageReference() {
+	ref := ecstagshift.ParseImageReference("123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.3")
+	fmt.Println(ref.Repository)
+	fmt.Println(ref.Name())
+	fmt.Println(ref.Tag)
+	// Output:
+	// 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app
+	// my-app
+	// v1.2.3
+}
+
+func ExampleUpdateTaskDefinition() {
+	taskDef, err := ecstagshift.LoadTaskDefinition(strings.NewReader(`{
+  // JSONC comments are allowed
+  "family": "my-app",
+  "containerDefinitions": [
+    {"name": "web", "image": "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.2"},
+    {"name": "nginx", "image": "nginx:1.25"}
+  ]
+}`), ecstagshift.LoadOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	result, err := ecstagshift.UpdateTaskDefinition(taskDef, ecstagshift.UpdateOptions{
+		Tag:    "v1.2.3",
+		Filter: ecstagshift.Filter{ContainerName: "web"},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, c := range result.Changes {
+		fmt.Printf("%s: %s -> %s\n", c.Container, c.OldImage, c.NewImage)
+	}
+
+	if err := ecstagshift.WriteTaskDefinitionSummary(os.Stdout, taskDef, ecstagshift.FormatText); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// web: 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.2 -> 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.3
+	// Family: my-app
+	//
+	// Containers:
+	//   - web: 123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1.2.3
+	//   - nginx: nginx:1.25
+}
+
+func ExampleFilter_Select() {
+	containers := []ecstagshift.ContainerDefinition{
+		{Name: "web", Image: "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1"},
+		{Name: "worker", Image: "123456789.dkr.ecr.us-east-1.amazonaws.com/my-app:v1"},
+		{Name: "nginx", Image: "nginx:1.25"},
+	}
+	for _, i := range (ecstagshift.Filter{ImageName: "my-app"}).Select(containers) {
+		fmt.Println(containers[i].Name)
+	}
+	// Output:
+	// web
+	// worker
+}
+
+func ExampleFilterError() {
+	containers := []ecstagshift.ContainerDefinition{{Name: "web", Image: "my-app:v1"}}
+	_, err := ecstagshift.UpdateContainerDefinitions(containers, ecstagshift.UpdateOptions{
+		Tag:    "v2",
+		Filter: ecstagshift.Filter{ContainerName: "api", ImageName: "other"},
+	})
+
+	var filterErr *ecstagshift.FilterError
+	if errors.As(err, &filterErr) {
+		for _, problem := range filterErr.Problems {
+			fmt.Println(problem)
+		}
+	}
+	// Output:
+	// container 'api' not found in definitions
+	// image 'other' not found in definitions
+}